@@ -1,11 +1,17 @@
 package main
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
+	"strconv"
 	"strings"
 
+	"github.com/templecloud/glu/pkg/lexer"
+	"github.com/templecloud/glu/pkg/parser"
 	"github.com/templecloud/glu/pkg/repl"
+	"github.com/templecloud/glu/pkg/resolver"
+	"github.com/templecloud/glu/pkg/transpiler"
 )
 
 const (
@@ -13,19 +19,111 @@ const (
 	Repl = "repl"
 	// File switch
 	File = "-f"
+	// Build identifier.
+	Build = "build"
+	// Target switch
+	Target = "--target"
+	// VM switch
+	VM = "--vm"
+	// MaxDepth switch
+	MaxDepth = "--max-depth"
 )
 
+// options are the switches that precede the arguments.
+type options struct {
+	useVM    bool
+	maxDepth int
+}
+
 func main() {
-	if len(os.Args) == 2 && os.Args[1] == Repl {
-		repl.New().Start(os.Stdin, os.Stdout)
-	} else if len(os.Args) == 3 && os.Args[1] == File {
-		data, err := ioutil.ReadFile(os.Args[2])
-		if err != nil {
+	opts, args := parseOptions(os.Args[1:])
+	if len(args) == 1 && args[0] == Repl {
+		configure(repl.New(), opts).Start(os.Stdin, os.Stdout)
+	} else if len(args) == 2 && args[0] == File {
+		if err := configure(repl.NewCmd(), opts).ExecFile(args[1]); err != nil {
 			panic(err)
 		}
-		repl.NewCmd().Exec(string(data))
+	} else if len(args) == 4 && args[0] == Build && args[1] == Target {
+		os.Exit(build(args[2], args[3]))
 	} else {
-		input := strings.Join(os.Args[1:], " ")
-		repl.NewCmd().Exec(input)
+		input := strings.Join(args, " ")
+		configure(repl.NewCmd(), opts).Exec(input)
+	}
+}
+
+// parseOptions strips the leading switches from the arguments.
+func parseOptions(args []string) (options, []string) {
+	var opts options
+	for len(args) > 0 {
+		if args[0] == VM {
+			opts.useVM = true
+			args = args[1:]
+		} else if args[0] == MaxDepth && len(args) > 1 {
+			depth, err := strconv.Atoi(args[1])
+			if err != nil || depth < 1 {
+				fmt.Fprintf(os.Stderr, "Invalid %s '%s'.\n", MaxDepth, args[1])
+				os.Exit(1)
+			}
+			opts.maxDepth = depth
+			args = args[2:]
+		} else {
+			break
+		}
+	}
+	return opts, args
+}
+
+// configure applies the options to the Repl.
+func configure(r *repl.Repl, opts options) *repl.Repl {
+	if opts.maxDepth > 0 {
+		r.WithMaxDepth(opts.maxDepth)
+	}
+	if opts.useVM {
+		r.WithVM()
+	}
+	return r
+}
+
+// build transpiles the Glu file to the target language and writes the result
+// to stdout. Errors are written to stderr. It returns the exit status.
+func build(target string, path string) int {
+	if target != "bash" {
+		fmt.Fprintf(os.Stderr, "Unknown build target '%s'.\n", target)
+		return 1
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open file: %v\n", err)
+		return 1
+	}
+	tokens, tokenErrs := lexer.New(string(data)).ScanTokens()
+	for _, tokenErr := range tokenErrs {
+		fmt.Fprintf(os.Stderr, "Token Error: %+v\n", tokenErr)
+	}
+	p := parser.New(tokens)
+	stmts := p.Parse()
+	for _, parseErr := range p.Errors {
+		fmt.Fprintf(os.Stderr, "Parse Error: %s\n", parseErr.Error())
+	}
+	if len(tokenErrs) > 0 || len(p.Errors) > 0 {
+		return 1
+	}
+	r := resolver.New()
+	r.Resolve(stmts)
+	for _, resolveErr := range r.Errors {
+		fmt.Fprintf(os.Stderr, "Resolve Error: %s\n", resolveErr.Error())
+	}
+	if len(r.Errors) > 0 {
+		return 1
+	}
+	bash := transpiler.NewBash()
+	script := bash.Transpile(stmts)
+	for _, compileErr := range bash.Errors {
+		fmt.Fprintf(os.Stderr, "Compile Error: %s\n", compileErr.Error())
+	}
+	if len(bash.Errors) > 0 {
+		return 1
 	}
+	fmt.Print(script)
+	return 0
 }
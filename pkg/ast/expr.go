@@ -71,6 +71,83 @@ func (c *Call) Accept(visitor Visitor) interface{} {
 	return visitor.VisitCallExpr(c)
 }
 
+// Command ====================================================================
+//
+
+// Command expression node. Represents a shell command, e.g. '`ls -l`' or
+// '$(ls -l)', whose Token lexeme is the command text.
+type Command struct {
+	Token *token.Token
+}
+
+// NewCommand constructor.
+func NewCommand(token *token.Token) *Command {
+	return &Command{Token: token}
+}
+
+// Accept a Vistor that can perform an operation on the node to return a result.
+func (c *Command) Accept(visitor Visitor) interface{} {
+	return visitor.VisitCommandExpr(c)
+}
+
+// Concat =====================================================================
+//
+
+// Concat expression node. Represents an interpolated string, e.g.
+// '"Hello ${name}!"', as the concatenation of the string value of its Parts.
+type Concat struct {
+	Token *token.Token
+	Parts []Expr
+}
+
+// NewConcat constructor.
+func NewConcat(token *token.Token, parts []Expr) *Concat {
+	return &Concat{Token: token, Parts: parts}
+}
+
+// Accept a Vistor that can perform an operation on the node to return a result.
+func (c *Concat) Accept(visitor Visitor) interface{} {
+	return visitor.VisitConcatExpr(c)
+}
+
+// EnvVar =====================================================================
+//
+
+// EnvVar expression node. Represents a process environment variable, e.g.
+// '$HOME'. The Name lexeme does not include the '$'.
+type EnvVar struct {
+	Name *token.Token
+}
+
+// NewEnvVar constructor.
+func NewEnvVar(name *token.Token) *EnvVar {
+	return &EnvVar{Name: name}
+}
+
+// Accept a Vistor that can perform an operation on the node to return a result.
+func (ev *EnvVar) Accept(visitor Visitor) interface{} {
+	return visitor.VisitEnvVarExpr(ev)
+}
+
+// Get ========================================================================
+//
+
+// Get expression node. Represents a property access on an instance.
+type Get struct {
+	Object Expr
+	Name   *token.Token
+}
+
+// NewGet constructor.
+func NewGet(object Expr, name *token.Token) *Get {
+	return &Get{Object: object, Name: name}
+}
+
+// Accept a Vistor that can perform an operation on the node to return a result.
+func (g *Get) Accept(visitor Visitor) interface{} {
+	return visitor.VisitGetExpr(g)
+}
+
 // Grouping ===================================================================
 //
 
@@ -89,6 +166,64 @@ func (g *Grouping) Accept(visitor Visitor) interface{} {
 	return visitor.VisitGroupingExpr(g)
 }
 
+// Index ======================================================================
+//
+
+// Index expression node. Represents an element access, e.g. 'xs[i]'.
+type Index struct {
+	Object  Expr
+	Bracket *token.Token
+	Index   Expr
+}
+
+// NewIndex constructor.
+func NewIndex(object Expr, bracket *token.Token, index Expr) *Index {
+	return &Index{Object: object, Bracket: bracket, Index: index}
+}
+
+// Accept a Vistor that can perform an operation on the node to return a result.
+func (i *Index) Accept(visitor Visitor) interface{} {
+	return visitor.VisitIndexExpr(i)
+}
+
+// Lambda =====================================================================
+//
+
+// Lambda expression node. Represents an anonymous function, e.g.
+// 'func (a) { ... }' or '(a) => a + 1'.
+type Lambda struct {
+	Fn *FnStmt
+}
+
+// NewLambda constructor.
+func NewLambda(fn *FnStmt) *Lambda {
+	return &Lambda{Fn: fn}
+}
+
+// Accept a Vistor that can perform an operation on the node to return a result.
+func (l *Lambda) Accept(visitor Visitor) interface{} {
+	return visitor.VisitLambdaExpr(l)
+}
+
+// List =======================================================================
+//
+
+// List expression node. Represents a list literal, e.g. '[a, b, c]'.
+type List struct {
+	Bracket  *token.Token
+	Elements []Expr
+}
+
+// NewList constructor.
+func NewList(bracket *token.Token, elements []Expr) *List {
+	return &List{Bracket: bracket, Elements: elements}
+}
+
+// Accept a Vistor that can perform an operation on the node to return a result.
+func (l *List) Accept(visitor Visitor) interface{} {
+	return visitor.VisitListExpr(l)
+}
+
 // Literal ====================================================================
 //
 
@@ -128,6 +263,47 @@ func (l *Logical) Accept(visitor Visitor) interface{} {
 	return visitor.VisitLogicalExpr(l)
 }
 
+// Map ========================================================================
+//
+
+// Map expression node. Represents a map literal, e.g. '{"k": v}'. Keys and
+// Values are parallel lists in source order.
+type Map struct {
+	Brace  *token.Token
+	Keys   []Expr
+	Values []Expr
+}
+
+// NewMap constructor.
+func NewMap(brace *token.Token, keys []Expr, values []Expr) *Map {
+	return &Map{Brace: brace, Keys: keys, Values: values}
+}
+
+// Accept a Vistor that can perform an operation on the node to return a result.
+func (m *Map) Accept(visitor Visitor) interface{} {
+	return visitor.VisitMapExpr(m)
+}
+
+// Numeral ====================================================================
+//
+
+// Numeral expression node. Represents a number literal whose Value is either
+// an int64 or a float64.
+type Numeral struct {
+	Token *token.Token
+	Value interface{}
+}
+
+// NewNumeral constructor.
+func NewNumeral(token *token.Token, value interface{}) *Numeral {
+	return &Numeral{Token: token, Value: value}
+}
+
+// Accept a Vistor that can perform an operation on the node to return a result.
+func (n *Numeral) Accept(visitor Visitor) interface{} {
+	return visitor.VisitNumeralExpr(n)
+}
+
 // Return =====================================================================
 //
 
@@ -147,6 +323,108 @@ func (r *Return) Accept(visitor Visitor) interface{} {
 	return visitor.VisitReturnExpr(r)
 }
 
+// Set ========================================================================
+//
+
+// Set expression node. Represents a property assignment on an instance.
+type Set struct {
+	Object Expr
+	Name   *token.Token
+	Value  Expr
+}
+
+// NewSet constructor.
+func NewSet(object Expr, name *token.Token, value Expr) *Set {
+	return &Set{Object: object, Name: name, Value: value}
+}
+
+// Accept a Vistor that can perform an operation on the node to return a result.
+func (s *Set) Accept(visitor Visitor) interface{} {
+	return visitor.VisitSetExpr(s)
+}
+
+// SetEnv =====================================================================
+//
+
+// SetEnv expression node. Represents assignment to a process environment
+// variable, e.g. '$PATH = "/bin"'.
+type SetEnv struct {
+	Name  *token.Token
+	Value Expr
+}
+
+// NewSetEnv constructor.
+func NewSetEnv(name *token.Token, value Expr) *SetEnv {
+	return &SetEnv{Name: name, Value: value}
+}
+
+// Accept a Vistor that can perform an operation on the node to return a result.
+func (se *SetEnv) Accept(visitor Visitor) interface{} {
+	return visitor.VisitSetEnvExpr(se)
+}
+
+// SetIndex ===================================================================
+//
+
+// SetIndex expression node. Represents an element assignment, e.g.
+// 'xs[i] = v'.
+type SetIndex struct {
+	Object  Expr
+	Bracket *token.Token
+	Index   Expr
+	Value   Expr
+}
+
+// NewSetIndex constructor.
+func NewSetIndex(object Expr, bracket *token.Token, index Expr, value Expr) *SetIndex {
+	return &SetIndex{Object: object, Bracket: bracket, Index: index, Value: value}
+}
+
+// Accept a Vistor that can perform an operation on the node to return a result.
+func (si *SetIndex) Accept(visitor Visitor) interface{} {
+	return visitor.VisitSetIndexExpr(si)
+}
+
+// Slice ======================================================================
+//
+
+// Slice expression node. Represents a sub-list access, e.g. 'xs[a:b]'. Either
+// bound may be nil.
+type Slice struct {
+	Object  Expr
+	Bracket *token.Token
+	Start   Expr
+	End     Expr
+}
+
+// NewSlice constructor.
+func NewSlice(object Expr, bracket *token.Token, start Expr, end Expr) *Slice {
+	return &Slice{Object: object, Bracket: bracket, Start: start, End: end}
+}
+
+// Accept a Vistor that can perform an operation on the node to return a result.
+func (s *Slice) Accept(visitor Visitor) interface{} {
+	return visitor.VisitSliceExpr(s)
+}
+
+// This =======================================================================
+//
+
+// This expression node.
+type This struct {
+	Keyword *token.Token
+}
+
+// NewThis constructor.
+func NewThis(keyword *token.Token) *This {
+	return &This{Keyword: keyword}
+}
+
+// Accept a Vistor that can perform an operation on the node to return a result.
+func (t *This) Accept(visitor Visitor) interface{} {
+	return visitor.VisitThisExpr(t)
+}
+
 // Unary ======================================================================
 //
 
@@ -54,11 +54,63 @@ func (p *Printer) VisitCallExpr(expr *Call) interface{} {
 	return builder.String()
 }
 
+// VisitCommandExpr returns a string representation of the node.
+// Terminates recursion.
+func (p *Printer) VisitCommandExpr(expr *Command) interface{} {
+	return fmt.Sprintf("(#cmd `%s`)", expr.Token.Lexeme)
+}
+
+// VisitConcatExpr returns a string representation of the node.
+func (p *Printer) VisitConcatExpr(expr *Concat) interface{} {
+	return p.parenthesize("#concat", expr.Parts...)
+}
+
+// VisitEnvVarExpr returns a string representation of the node.
+// Terminates recursion.
+func (p *Printer) VisitEnvVarExpr(expr *EnvVar) interface{} {
+	return "$" + expr.Name.Lexeme
+}
+
+// VisitGetExpr returns a string representation of the node.
+func (p *Printer) VisitGetExpr(expr *Get) interface{} {
+	object := expr.Object.Accept(p).(string)
+	return fmt.Sprintf("(#get %s.%s)", object, expr.Name.Lexeme)
+}
+
 // VisitGroupingExpr returns a string representation of the node.
 func (p *Printer) VisitGroupingExpr(expr *Grouping) interface{} {
 	return p.parenthesize("#g", expr.Expr)
 }
 
+// VisitIndexExpr returns a string representation of the node.
+func (p *Printer) VisitIndexExpr(expr *Index) interface{} {
+	object := expr.Object.Accept(p).(string)
+	index := expr.Index.Accept(p).(string)
+	return fmt.Sprintf("(#index %s[%s])", object, index)
+}
+
+// VisitLambdaExpr returns a string representation of the node.
+func (p *Printer) VisitLambdaExpr(expr *Lambda) interface{} {
+	return fmt.Sprintf("(#lambda %s)", p.function(expr.Fn))
+}
+
+// VisitListExpr returns a string representation of the node.
+func (p *Printer) VisitListExpr(expr *List) interface{} {
+	var builder strings.Builder
+	builder.WriteString("(")
+	builder.WriteString("#list")
+	builder.WriteString(" [")
+	for idx, e := range expr.Elements {
+		builder.WriteString(e.Accept(p).(string))
+		if idx < len(expr.Elements)-1 {
+			builder.WriteString(", ")
+		}
+	}
+	builder.WriteString("]")
+	builder.WriteString(")")
+	return builder.String()
+}
+
 // VisitLiteralExpr returns a string representation of the node.
 // Terminates recursion.
 func (p *Printer) VisitLiteralExpr(expr *Literal) interface{} {
@@ -76,11 +128,75 @@ func (p *Printer) VisitLogicalExpr(expr *Logical) interface{} {
 	return p.parenthesize(expr.Operator.Lexeme, expr.Left, expr.Right)
 }
 
+// VisitMapExpr returns a string representation of the node.
+func (p *Printer) VisitMapExpr(expr *Map) interface{} {
+	var builder strings.Builder
+	builder.WriteString("(")
+	builder.WriteString("#map")
+	builder.WriteString(" {")
+	for idx, key := range expr.Keys {
+		builder.WriteString(key.Accept(p).(string))
+		builder.WriteString(": ")
+		builder.WriteString(expr.Values[idx].Accept(p).(string))
+		if idx < len(expr.Keys)-1 {
+			builder.WriteString(", ")
+		}
+	}
+	builder.WriteString("}")
+	builder.WriteString(")")
+	return builder.String()
+}
+
+// VisitNumeralExpr returns a string representation of the node.
+// Terminates recursion.
+func (p *Printer) VisitNumeralExpr(expr *Numeral) interface{} {
+	return expr.Token.Lexeme
+}
+
 // VisitReturnExpr returns a string representation of the node.
 func (p *Printer) VisitReturnExpr(expr *Return) interface{} {
 	return p.parenthesize(expr.Keyword.Lexeme, expr.Value)
 }
 
+// VisitSetExpr returns a string representation of the node.
+func (p *Printer) VisitSetExpr(expr *Set) interface{} {
+	object := expr.Object.Accept(p).(string)
+	nfo := fmt.Sprintf("#set %s.%s =", object, expr.Name.Lexeme)
+	return p.parenthesize(nfo, expr.Value)
+}
+
+// VisitSetEnvExpr returns a string representation of the node.
+func (p *Printer) VisitSetEnvExpr(expr *SetEnv) interface{} {
+	nfo := fmt.Sprintf("#set-env $%s =", expr.Name.Lexeme)
+	return p.parenthesize(nfo, expr.Value)
+}
+
+// VisitSetIndexExpr returns a string representation of the node.
+func (p *Printer) VisitSetIndexExpr(expr *SetIndex) interface{} {
+	object := expr.Object.Accept(p).(string)
+	index := expr.Index.Accept(p).(string)
+	nfo := fmt.Sprintf("#set-index %s[%s] =", object, index)
+	return p.parenthesize(nfo, expr.Value)
+}
+
+// VisitSliceExpr returns a string representation of the node.
+func (p *Printer) VisitSliceExpr(expr *Slice) interface{} {
+	object := expr.Object.Accept(p).(string)
+	var start, end string
+	if expr.Start != nil {
+		start = expr.Start.Accept(p).(string)
+	}
+	if expr.End != nil {
+		end = expr.End.Accept(p).(string)
+	}
+	return fmt.Sprintf("(#slice %s[%s:%s])", object, start, end)
+}
+
+// VisitThisExpr returns a string representation of the node.
+func (p *Printer) VisitThisExpr(expr *This) interface{} {
+	return expr.Keyword.Lexeme
+}
+
 // VisitUnaryExpr returns a string representation of the node.
 func (p *Printer) VisitUnaryExpr(expr *Unary) interface{} {
 	return p.parenthesize(expr.Operator.Lexeme, expr.Right)
@@ -107,6 +223,68 @@ func (p *Printer) VisitBlockStmt(stmt *BlockStmt) interface{} {
 	return builder.String()
 }
 
+// VisitBreakStmt returns a string representation of the node.
+func (p *Printer) VisitBreakStmt(stmt *BreakStmt) interface{} {
+	return "(#break)"
+}
+
+// VisitClassStmt returns a string representation of the node.
+func (p *Printer) VisitClassStmt(stmt *ClassStmt) interface{} {
+	var builder strings.Builder
+	builder.WriteString("(")
+	builder.WriteString("#class-stmt")
+	builder.WriteString(" ")
+	builder.WriteString(stmt.Name.Lexeme)
+	builder.WriteString(" {")
+	for _, field := range stmt.Fields {
+		builder.WriteString(" ")
+		builder.WriteString(field.Accept(p).(string))
+	}
+	for _, method := range stmt.Methods {
+		builder.WriteString(" ")
+		builder.WriteString(method.Accept(p).(string))
+	}
+	builder.WriteString(" }")
+	builder.WriteString(")")
+	return builder.String()
+}
+
+// VisitContinueStmt returns a string representation of the node.
+func (p *Printer) VisitContinueStmt(stmt *ContinueStmt) interface{} {
+	return "(#continue)"
+}
+
+// VisitExecStmt returns a string representation of the node.
+func (p *Printer) VisitExecStmt(stmt *ExecStmt) interface{} {
+	var builder strings.Builder
+	builder.WriteString("(")
+	builder.WriteString("#exec")
+	for _, argument := range stmt.Arguments {
+		builder.WriteString(" ")
+		builder.WriteString(argument.Accept(p).(string))
+	}
+	for _, redirect := range stmt.Redirects {
+		builder.WriteString(" ")
+		if redirect.FD == 2 || (redirect.FD == 1 && redirect.Target == nil) {
+			builder.WriteString(fmt.Sprintf("%d", redirect.FD))
+		}
+		builder.WriteString(redirect.Operator.Lexeme)
+		if redirect.Target == nil {
+			builder.WriteString(fmt.Sprintf("&%d", redirect.TargetFD))
+		} else {
+			builder.WriteString(" ")
+			builder.WriteString(redirect.Target.Accept(p).(string))
+		}
+	}
+	builder.WriteString(")")
+	return builder.String()
+}
+
+// VisitExportStmt returns a string representation of the node.
+func (p *Printer) VisitExportStmt(stmt *ExportStmt) interface{} {
+	return fmt.Sprintf("(#export %s)", stmt.Declaration.Accept(p).(string))
+}
+
 // VisitExprStmt returns a string representation of the node.
 func (p *Printer) VisitExprStmt(stmt *ExprStmt) interface{} {
 	return p.parenthesize("#es", stmt.Expr)
@@ -118,27 +296,25 @@ func (p *Printer) VisitFnStmt(fn *FnStmt) interface{} {
 	builder.WriteString("(")
 	builder.WriteString("#fn-stmt")
 	builder.WriteString(" ")
-	builder.WriteString(fn.Name.Lexeme)
-	builder.WriteString("(")
-	for idx, param := range fn.Params {
-		builder.WriteString(param.Lexeme)
-		if idx < len(fn.Params)-1 {
-			builder.WriteString(", ")
-		}
-	}
-	builder.WriteString(")")
-	builder.WriteString(" { ")
-	for idx, stmt := range fn.Body {
-		builder.WriteString(stmt.Accept(p).(string))
-		if idx < len(fn.Body)-1 {
-			builder.WriteString("; ")
-		}
+	if fn.Attribute != nil {
+		builder.WriteString("@")
+		builder.WriteString(fn.Attribute.Lexeme)
+		builder.WriteString(" ")
 	}
-	builder.WriteString(" }")
+	builder.WriteString(fn.Name.Lexeme)
+	builder.WriteString(p.function(fn))
 	builder.WriteString(")")
 	return builder.String()
 }
 
+// VisitImportStmt returns a string representation of the node.
+func (p *Printer) VisitImportStmt(stmt *ImportStmt) interface{} {
+	if stmt.Kind != nil {
+		return fmt.Sprintf("(#import %s \"%s\")", stmt.Kind.Lexeme, stmt.Path.Lexeme)
+	}
+	return fmt.Sprintf("(#import \"%s\" as %s)", stmt.Path.Lexeme, stmt.Alias.Lexeme)
+}
+
 // VisitIfStmt returns a string representation of the node.
 func (p *Printer) VisitIfStmt(stmt *IfStmt) interface{} {
 	var builder strings.Builder
@@ -161,6 +337,34 @@ func (p *Printer) VisitLogStmt(stmt *LogStmt) interface{} {
 	return p.parenthesize("#ls", stmt.Expr)
 }
 
+// VisitThrowStmt returns a string representation of the node.
+func (p *Printer) VisitThrowStmt(stmt *ThrowStmt) interface{} {
+	return p.parenthesize("#throw", stmt.Value)
+}
+
+// VisitTryStmt returns a string representation of the node.
+func (p *Printer) VisitTryStmt(stmt *TryStmt) interface{} {
+	var builder strings.Builder
+	builder.WriteString("(")
+	builder.WriteString("#try")
+	builder.WriteString(" ")
+	builder.WriteString(p.block(stmt.Body))
+	if stmt.CatchName != nil {
+		builder.WriteString(" (#catch ")
+		builder.WriteString(stmt.CatchName.Lexeme)
+		builder.WriteString(" ")
+		builder.WriteString(p.block(stmt.Catch))
+		builder.WriteString(")")
+	}
+	if stmt.Finally != nil {
+		builder.WriteString(" (#finally ")
+		builder.WriteString(p.block(stmt.Finally))
+		builder.WriteString(")")
+	}
+	builder.WriteString(")")
+	return builder.String()
+}
+
 // VisitVariableStmt returns a string representation of the node.
 func (p *Printer) VisitVariableStmt(stmt *VariableStmt) interface{} {
 	if stmt.Initialiser != nil {
@@ -178,7 +382,25 @@ func (p *Printer) VisitWhileStmt(stmt *WhileStmt) interface{} {
 	builder.WriteString(" ")
 	builder.WriteString(stmt.Condition.Accept(p).(string))
 	builder.WriteString(" ")
-	builder.WriteString(stmt.Body.Accept(p).(string))
+	if stmt.Increment != nil {
+		// Print as the block the 'for' loop originally de-sugared to.
+		builder.WriteString(p.block([]Stmt{stmt.Body, NewExprStmt(stmt.Increment)}))
+	} else {
+		builder.WriteString(stmt.Body.Accept(p).(string))
+	}
+	builder.WriteString(")")
+	return builder.String()
+}
+
+// VisitWithStmt returns a string representation of the node.
+func (p *Printer) VisitWithStmt(stmt *WithStmt) interface{} {
+	var builder strings.Builder
+	builder.WriteString("(")
+	builder.WriteString("#with-env")
+	builder.WriteString(" ")
+	builder.WriteString(stmt.Env.Accept(p).(string))
+	builder.WriteString(" ")
+	builder.WriteString(p.block(stmt.Body))
 	builder.WriteString(")")
 	return builder.String()
 }
@@ -186,6 +408,37 @@ func (p *Printer) VisitWhileStmt(stmt *WhileStmt) interface{} {
 // Support Functions ==========================================================
 //
 
+// function returns a string representation of the parameters and body of a
+// function.
+func (p *Printer) function(fn *FnStmt) string {
+	var builder strings.Builder
+	builder.WriteString("(")
+	for idx, param := range fn.Params {
+		builder.WriteString(param.Lexeme)
+		if idx < len(fn.Params)-1 {
+			builder.WriteString(", ")
+		}
+	}
+	builder.WriteString(")")
+	builder.WriteString(" { ")
+	if fn.Script != nil {
+		builder.WriteString(strings.TrimSpace(fn.Script.Lexeme))
+	}
+	for idx, stmt := range fn.Body {
+		builder.WriteString(stmt.Accept(p).(string))
+		if idx < len(fn.Body)-1 {
+			builder.WriteString("; ")
+		}
+	}
+	builder.WriteString(" }")
+	return builder.String()
+}
+
+// block returns a string representation of a list of statements.
+func (p *Printer) block(stmts []Stmt) string {
+	return NewBlockStmt(stmts).Accept(p).(string)
+}
+
 // parenthesize adds grouping parenthese and recursively calls 'accept'.
 func (p *Printer) parenthesize(name string, exprs ...Expr) string {
 	var builder strings.Builder
@@ -28,6 +28,123 @@ func (bs *BlockStmt) Accept(visitor Visitor) interface{} {
 	return visitor.VisitBlockStmt(bs)
 }
 
+// BreakStmt ==================================================================
+//
+
+// BreakStmt statement node.
+type BreakStmt struct {
+	Keyword *token.Token
+}
+
+// NewBreakStmt constructor.
+func NewBreakStmt(keyword *token.Token) *BreakStmt {
+	return &BreakStmt{Keyword: keyword}
+}
+
+// Accept a Vistor that can perform an operation on the node to return a result.
+func (bs *BreakStmt) Accept(visitor Visitor) interface{} {
+	return visitor.VisitBreakStmt(bs)
+}
+
+// ClassStmt ==================================================================
+//
+
+// ClassStmt statement node.
+type ClassStmt struct {
+	Name    *token.Token
+	Fields  []*VariableStmt
+	Methods []*FnStmt
+}
+
+// NewClassStmt constructor.
+func NewClassStmt(
+	name *token.Token,
+	fields []*VariableStmt,
+	methods []*FnStmt,
+) *ClassStmt {
+	return &ClassStmt{Name: name, Fields: fields, Methods: methods}
+}
+
+// Accept a Vistor that can perform an operation on the node to return a result.
+func (cs *ClassStmt) Accept(visitor Visitor) interface{} {
+	return visitor.VisitClassStmt(cs)
+}
+
+// ContinueStmt ===============================================================
+//
+
+// ContinueStmt statement node.
+type ContinueStmt struct {
+	Keyword *token.Token
+}
+
+// NewContinueStmt constructor.
+func NewContinueStmt(keyword *token.Token) *ContinueStmt {
+	return &ContinueStmt{Keyword: keyword}
+}
+
+// Accept a Vistor that can perform an operation on the node to return a result.
+func (cs *ContinueStmt) Accept(visitor Visitor) interface{} {
+	return visitor.VisitContinueStmt(cs)
+}
+
+// ExecStmt ===================================================================
+//
+
+// ExecStmt statement node. Represents running an external program with
+// shell-style redirections, e.g. 'exec "ls", "-l" > "out.txt" 2>&1;'.
+type ExecStmt struct {
+	Keyword   *token.Token
+	Arguments []Expr
+	Redirects []*Redirect
+}
+
+// NewExecStmt constructor.
+func NewExecStmt(keyword *token.Token, arguments []Expr, redirects []*Redirect) *ExecStmt {
+	return &ExecStmt{Keyword: keyword, Arguments: arguments, Redirects: redirects}
+}
+
+// Accept a Vistor that can perform an operation on the node to return a result.
+func (es *ExecStmt) Accept(visitor Visitor) interface{} {
+	return visitor.VisitExecStmt(es)
+}
+
+// Redirect represents a redirection of the file descriptor FD of an
+// ExecStmt. The Operator is one of '<', '>' or '>>'. The descriptor is
+// redirected to the file named by Target or, if Target is nil, duplicated
+// from the descriptor TargetFD, e.g. '2>&1'.
+type Redirect struct {
+	Operator *token.Token
+	FD       int
+	Target   Expr
+	TargetFD int
+}
+
+// NewRedirect constructor.
+func NewRedirect(operator *token.Token, fd int, target Expr, targetFD int) *Redirect {
+	return &Redirect{Operator: operator, FD: fd, Target: target, TargetFD: targetFD}
+}
+
+// ExportStmt =================================================================
+//
+
+// ExportStmt statement node. Represents a top-level declaration that is
+// visible to the importers of a module, e.g. 'export func f() {}'.
+type ExportStmt struct {
+	Keyword     *token.Token
+	Declaration Stmt
+}
+
+// NewExportStmt constructor.
+func NewExportStmt(keyword *token.Token, declaration Stmt) *ExportStmt {
+	return &ExportStmt{Keyword: keyword, Declaration: declaration}
+}
+
+// Accept a Vistor that can perform an operation on the node to return a result.
+func (es *ExportStmt) Accept(visitor Visitor) interface{} {
+	return visitor.VisitExportStmt(es)
+}
+
 // ExprStmt ===================================================================
 //
 
@@ -49,11 +166,14 @@ func (es *ExprStmt) Accept(visitor Visitor) interface{} {
 // FnStmt =====================================================================
 //
 
-// FnStmt statement node.
+// FnStmt statement node. An attributed function, e.g. 'func @bash f() {}',
+// has a raw Script body in place of Glu statements.
 type FnStmt struct {
-	Name   *token.Token
-	Params []*token.Token
-	Body   []Stmt
+	Name      *token.Token
+	Params    []*token.Token
+	Body      []Stmt
+	Attribute *token.Token
+	Script    *token.Token
 }
 
 // NewFnStmt constructor.
@@ -65,6 +185,16 @@ func NewFnStmt(
 	return &FnStmt{Name: name, Params: params, Body: body}
 }
 
+// NewScriptFnStmt constructor.
+func NewScriptFnStmt(
+	attribute *token.Token,
+	name *token.Token,
+	params []*token.Token,
+	script *token.Token,
+) *FnStmt {
+	return &FnStmt{Name: name, Params: params, Attribute: attribute, Script: script}
+}
+
 // Accept a Vistor that can perform an operation on the node to return a result.
 func (fs *FnStmt) Accept(visitor Visitor) interface{} {
 	return visitor.VisitFnStmt(fs)
@@ -90,6 +220,34 @@ func (is *IfStmt) Accept(visitor Visitor) interface{} {
 	return visitor.VisitIfStmt(is)
 }
 
+// ImportStmt =================================================================
+//
+
+// ImportStmt statement node. Represents the import of a Glu module bound to
+// an alias, e.g. 'import "lib/util.glu" as util;', or of a library of
+// functions of the specified kind, e.g. 'import bash "lib/helpers.sh";'.
+type ImportStmt struct {
+	Keyword *token.Token
+	Kind    *token.Token
+	Path    *token.Token
+	Alias   *token.Token
+}
+
+// NewImportStmt constructor.
+func NewImportStmt(
+	keyword *token.Token,
+	kind *token.Token,
+	path *token.Token,
+	alias *token.Token,
+) *ImportStmt {
+	return &ImportStmt{Keyword: keyword, Kind: kind, Path: path, Alias: alias}
+}
+
+// Accept a Vistor that can perform an operation on the node to return a result.
+func (is *ImportStmt) Accept(visitor Visitor) interface{} {
+	return visitor.VisitImportStmt(is)
+}
+
 // LogStmt ====================================================================
 //
 
@@ -108,6 +266,54 @@ func (ps *LogStmt) Accept(visitor Visitor) interface{} {
 	return visitor.VisitLogStmt(ps)
 }
 
+// ThrowStmt ==================================================================
+//
+
+// ThrowStmt statement node.
+type ThrowStmt struct {
+	Keyword *token.Token
+	Value   Expr
+}
+
+// NewThrowStmt constructor.
+func NewThrowStmt(keyword *token.Token, value Expr) *ThrowStmt {
+	return &ThrowStmt{Keyword: keyword, Value: value}
+}
+
+// Accept a Vistor that can perform an operation on the node to return a result.
+func (ts *ThrowStmt) Accept(visitor Visitor) interface{} {
+	return visitor.VisitThrowStmt(ts)
+}
+
+// TryStmt ====================================================================
+//
+
+// TryStmt statement node. The catch clause is present if CatchName is not
+// nil, and the finally clause is present if Finally is not nil.
+type TryStmt struct {
+	Keyword   *token.Token
+	Body      []Stmt
+	CatchName *token.Token
+	Catch     []Stmt
+	Finally   []Stmt
+}
+
+// NewTryStmt constructor.
+func NewTryStmt(
+	keyword *token.Token,
+	body []Stmt,
+	catchName *token.Token,
+	catch []Stmt,
+	finally []Stmt,
+) *TryStmt {
+	return &TryStmt{Keyword: keyword, Body: body, CatchName: catchName, Catch: catch, Finally: finally}
+}
+
+// Accept a Vistor that can perform an operation on the node to return a result.
+func (ts *TryStmt) Accept(visitor Visitor) interface{} {
+	return visitor.VisitTryStmt(ts)
+}
+
 // VariableStmt ====================================================================
 //
 
@@ -130,10 +336,13 @@ func (vs *VariableStmt) Accept(visitor Visitor) interface{} {
 // WhileStmt ====================================================================
 //
 
-// WhileStmt statement node.
+// WhileStmt statement node. Increment is the optional increment clause of a
+// de-sugared 'for' loop, evaluated after the body on every iteration, even if
+// the body was exited by a 'continue'.
 type WhileStmt struct {
 	Condition Expr
 	Body      Stmt
+	Increment Expr
 }
 
 // NewWhileStmt constructor.
@@ -141,7 +350,33 @@ func NewWhileStmt(condition Expr, body Stmt) *WhileStmt {
 	return &WhileStmt{Condition: condition, Body: body}
 }
 
+// NewForWhileStmt constructor. Creates the WhileStmt of a de-sugared 'for' loop.
+func NewForWhileStmt(condition Expr, body Stmt, increment Expr) *WhileStmt {
+	return &WhileStmt{Condition: condition, Body: body, Increment: increment}
+}
+
 // Accept a Vistor that can perform an operation on the node to return a result.
 func (vs *WhileStmt) Accept(visitor Visitor) interface{} {
 	return visitor.VisitWhileStmt(vs)
 }
+
+// WithStmt ===================================================================
+//
+
+// WithStmt statement node. Represents a block run with a map of overrides
+// applied to the process environment, e.g. 'with env {"K": "v"} { ... }'.
+type WithStmt struct {
+	Keyword *token.Token
+	Env     Expr
+	Body    []Stmt
+}
+
+// NewWithStmt constructor.
+func NewWithStmt(keyword *token.Token, env Expr, body []Stmt) *WithStmt {
+	return &WithStmt{Keyword: keyword, Env: env, Body: body}
+}
+
+// Accept a Vistor that can perform an operation on the node to return a result.
+func (ws *WithStmt) Accept(visitor Visitor) interface{} {
+	return visitor.VisitWithStmt(ws)
+}
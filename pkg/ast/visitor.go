@@ -10,18 +10,41 @@ type Visitor interface {
 	VisitAssignExpr(a *Assign) interface{}
 	VisitBinaryExpr(b *Binary) interface{}
 	VisitCallExpr(*Call) interface{}
+	VisitCommandExpr(c *Command) interface{}
+	VisitConcatExpr(c *Concat) interface{}
+	VisitEnvVarExpr(ev *EnvVar) interface{}
+	VisitGetExpr(g *Get) interface{}
 	VisitGroupingExpr(g *Grouping) interface{}
+	VisitIndexExpr(i *Index) interface{}
+	VisitLambdaExpr(l *Lambda) interface{}
+	VisitListExpr(l *List) interface{}
 	VisitLiteralExpr(l *Literal) interface{}
 	VisitLogicalExpr(l *Logical) interface{}
+	VisitMapExpr(m *Map) interface{}
+	VisitNumeralExpr(n *Numeral) interface{}
 	VisitReturnExpr(r *Return) interface{}
+	VisitSetExpr(s *Set) interface{}
+	VisitSetEnvExpr(se *SetEnv) interface{}
+	VisitSetIndexExpr(si *SetIndex) interface{}
+	VisitSliceExpr(s *Slice) interface{}
+	VisitThisExpr(t *This) interface{}
 	VisitUnaryExpr(u *Unary) interface{}
 	VisitVarExpr(ve *VarExpr) interface{}
 	// statements
 	VisitBlockStmt(bs *BlockStmt) interface{}
+	VisitBreakStmt(bs *BreakStmt) interface{}
+	VisitClassStmt(cs *ClassStmt) interface{}
+	VisitContinueStmt(cs *ContinueStmt) interface{}
+	VisitExecStmt(es *ExecStmt) interface{}
+	VisitExportStmt(es *ExportStmt) interface{}
 	VisitExprStmt(es *ExprStmt) interface{}
 	VisitIfStmt(stmt *IfStmt) interface{}
 	VisitFnStmt(fs *FnStmt) interface{}
+	VisitImportStmt(is *ImportStmt) interface{}
 	VisitLogStmt(ps *LogStmt) interface{}
+	VisitThrowStmt(ts *ThrowStmt) interface{}
+	VisitTryStmt(ts *TryStmt) interface{}
 	VisitVariableStmt(vs *VariableStmt) interface{}
 	VisitWhileStmt(ws *WhileStmt) interface{}
+	VisitWithStmt(ws *WithStmt) interface{}
 }
@@ -0,0 +1,388 @@
+package compiler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/templecloud/glu/pkg/ast"
+	"github.com/templecloud/glu/pkg/token"
+)
+
+// OpCode =====================================================================
+//
+
+// OpCode is a bytecode instruction. Operands follow the opcode in the code of
+// a Chunk; one byte operands are written u8 and two byte operands u16 below.
+type OpCode byte
+
+const (
+	// OpConstant pushes the constant u16.
+	OpConstant OpCode = iota
+	// OpNil pushes nil.
+	OpNil
+	// OpTrue pushes true.
+	OpTrue
+	// OpFalse pushes false.
+	OpFalse
+	// OpPop discards the top of the stack.
+	OpPop
+	// OpPopLocals discards u8 locals, closing any upvalues that capture them.
+	OpPopLocals
+	// OpGetLocal pushes the local in slot u8 of the frame.
+	OpGetLocal
+	// OpSetLocal assigns the top of the stack to the local in slot u8.
+	OpSetLocal
+	// OpGetUpvalue pushes the upvalue u8 of the closure.
+	OpGetUpvalue
+	// OpSetUpvalue assigns the top of the stack to the upvalue u8.
+	OpSetUpvalue
+	// OpGetGlobal pushes the global named by the constant u16.
+	OpGetGlobal
+	// OpSetGlobal assigns the top of the stack to the global named by the
+	// constant u16.
+	OpSetGlobal
+	// OpDefineGlobal pops a value and defines the global named by the constant
+	// u16.
+	OpDefineGlobal
+	// OpGetProperty replaces an object with its property named by the
+	// constant u16.
+	OpGetProperty
+	// OpSetProperty pops a value and an instance, assigns the field named by
+	// the constant u16, and pushes the value.
+	OpSetProperty
+	// OpCheckInstance raises an error if the top of the stack is not an
+	// instance, before the value of a field assignment is evaluated.
+	OpCheckInstance
+	// OpGetIndex replaces a collection and an index with the element.
+	OpGetIndex
+	// OpSetIndex replaces a collection, an index and a value with the value
+	// after assigning the element.
+	OpSetIndex
+	// OpSlice replaces a list and its bounds with a slice. The u8 flags record
+	// which of the start (1) and end (2) bounds are present.
+	OpSlice
+	// OpEqual and the binary operators below replace two operands with the
+	// result of the operator.
+	OpEqual
+	OpNotEqual
+	OpGreater
+	OpGreaterEqual
+	OpLess
+	OpLessEqual
+	OpAdd
+	OpSubtract
+	OpMultiply
+	OpDivide
+	OpFloorDivide
+	OpModulo
+	OpPower
+	OpBitAnd
+	OpBitXor
+	OpShiftLeft
+	OpShiftRight
+	// OpPipe replaces u8 operands with the result of the chain of '|'
+	// operators held in the constant u16.
+	OpPipe
+	// OpNot replaces a value with its logical negation.
+	OpNot
+	// OpNegate replaces a number with its negation.
+	OpNegate
+	// OpBitNot replaces an integer with its bitwise complement.
+	OpBitNot
+	// OpJump jumps forward by u16.
+	OpJump
+	// OpJumpIfFalse jumps forward by u16 if the top of the stack is falsy. The
+	// condition is not popped.
+	OpJumpIfFalse
+	// OpJumpIfTrue jumps forward by u16 if the top of the stack is truthy. The
+	// condition is not popped.
+	OpJumpIfTrue
+	// OpLoop jumps backward by u16.
+	OpLoop
+	// OpCall calls the callee below u8 arguments.
+	OpCall
+	// OpClosure pushes a closure of the function constant u16. It is followed
+	// by an isLocal and index byte pair for each upvalue.
+	OpClosure
+	// OpScript pushes an attributed function for the declaration constant u16.
+	OpScript
+	// OpReturn returns the top of the stack from the frame.
+	OpReturn
+	// OpClass pushes a class named by the constant u16.
+	OpClass
+	// OpFields pops the field initialiser closure of the class below it.
+	OpFields
+	// OpSetField pops a value and assigns the field named by the constant u16
+	// of the instance in slot 0 of the frame.
+	OpSetField
+	// OpMethod pops a method and adds it to the class below it as the name
+	// constant u16.
+	OpMethod
+	// OpList replaces u16 elements with a list.
+	OpList
+	// OpMap pushes an empty map.
+	OpMap
+	// OpMapEntry pops a key and value and sets the entry of the map below.
+	OpMapEntry
+	// OpConcat replaces u16 parts with the concatenation of their strings.
+	OpConcat
+	// OpCommand runs the command constant u16 and pushes its output.
+	OpCommand
+	// OpGetEnv pushes the process environment variable named by the constant
+	// u16.
+	OpGetEnv
+	// OpSetEnv sets the process environment variable named by the constant
+	// u16 to the top of the stack.
+	OpSetEnv
+	// OpExec pops u8 arguments and the redirect targets, and runs the exec
+	// statement constant u16.
+	OpExec
+	// OpLog pops and logs a value.
+	OpLog
+	// OpThrow pops and throws a value.
+	OpThrow
+	// OpRethrow pops and throws a caught error unchanged.
+	OpRethrow
+	// OpTry registers an exception handler at the forward offset u16.
+	OpTry
+	// OpEndTry removes the innermost exception handler.
+	OpEndTry
+	// OpWith pops a map of environment variables and applies them to the
+	// process environment.
+	OpWith
+	// OpEndWith restores the process environment of the innermost 'with'.
+	OpEndWith
+	// OpPushNames begins a scope for functions imported by name.
+	OpPushNames
+	// OpPopNames ends the innermost scope for functions imported by name.
+	OpPopNames
+	// OpImportBash imports the bash library at the path constant u16. The u8
+	// flag is 1 if the functions are defined in the innermost names scope.
+	OpImportBash
+	// OpImportModule pushes the Glu module at the path constant u16.
+	OpImportModule
+	// OpExport exports the global named by the constant u16.
+	OpExport
+)
+
+var opNames = [...]string{
+	OpConstant:      "CONSTANT",
+	OpNil:           "NIL",
+	OpTrue:          "TRUE",
+	OpFalse:         "FALSE",
+	OpPop:           "POP",
+	OpPopLocals:     "POP_LOCALS",
+	OpGetLocal:      "GET_LOCAL",
+	OpSetLocal:      "SET_LOCAL",
+	OpGetUpvalue:    "GET_UPVALUE",
+	OpSetUpvalue:    "SET_UPVALUE",
+	OpGetGlobal:     "GET_GLOBAL",
+	OpSetGlobal:     "SET_GLOBAL",
+	OpDefineGlobal:  "DEFINE_GLOBAL",
+	OpGetProperty:   "GET_PROPERTY",
+	OpSetProperty:   "SET_PROPERTY",
+	OpCheckInstance: "CHECK_INSTANCE",
+	OpGetIndex:      "GET_INDEX",
+	OpSetIndex:      "SET_INDEX",
+	OpSlice:         "SLICE",
+	OpEqual:         "EQUAL",
+	OpNotEqual:      "NOT_EQUAL",
+	OpGreater:       "GREATER",
+	OpGreaterEqual:  "GREATER_EQUAL",
+	OpLess:          "LESS",
+	OpLessEqual:     "LESS_EQUAL",
+	OpAdd:           "ADD",
+	OpSubtract:      "SUBTRACT",
+	OpMultiply:      "MULTIPLY",
+	OpDivide:        "DIVIDE",
+	OpFloorDivide:   "FLOOR_DIVIDE",
+	OpModulo:        "MODULO",
+	OpPower:         "POWER",
+	OpBitAnd:        "BIT_AND",
+	OpBitXor:        "BIT_XOR",
+	OpShiftLeft:     "SHIFT_LEFT",
+	OpShiftRight:    "SHIFT_RIGHT",
+	OpPipe:          "PIPE",
+	OpNot:           "NOT",
+	OpNegate:        "NEGATE",
+	OpBitNot:        "BIT_NOT",
+	OpJump:          "JUMP",
+	OpJumpIfFalse:   "JUMP_IF_FALSE",
+	OpJumpIfTrue:    "JUMP_IF_TRUE",
+	OpLoop:          "LOOP",
+	OpCall:          "CALL",
+	OpClosure:       "CLOSURE",
+	OpScript:        "SCRIPT",
+	OpReturn:        "RETURN",
+	OpClass:         "CLASS",
+	OpFields:        "FIELDS",
+	OpSetField:      "SET_FIELD",
+	OpMethod:        "METHOD",
+	OpList:          "LIST",
+	OpMap:           "MAP",
+	OpMapEntry:      "MAP_ENTRY",
+	OpConcat:        "CONCAT",
+	OpCommand:       "COMMAND",
+	OpGetEnv:        "GET_ENV",
+	OpSetEnv:        "SET_ENV",
+	OpExec:          "EXEC",
+	OpLog:           "LOG",
+	OpThrow:         "THROW",
+	OpRethrow:       "RETHROW",
+	OpTry:           "TRY",
+	OpEndTry:        "END_TRY",
+	OpWith:          "WITH",
+	OpEndWith:       "END_WITH",
+	OpPushNames:     "PUSH_NAMES",
+	OpPopNames:      "POP_NAMES",
+	OpImportBash:    "IMPORT_BASH",
+	OpImportModule:  "IMPORT_MODULE",
+	OpExport:        "EXPORT",
+}
+
+func (op OpCode) String() string {
+	if int(op) < len(opNames) {
+		return opNames[op]
+	}
+	return fmt.Sprintf("OP_%d", byte(op))
+}
+
+// Chunk ======================================================================
+//
+
+// Chunk is the bytecode of a function. Tokens holds, for each byte of code,
+// the token of the source the instruction was compiled from, so that runtime
+// errors are reported at the same position as the tree-walking interpreter.
+type Chunk struct {
+	Code      []byte
+	Tokens    []*token.Token
+	Constants []interface{}
+}
+
+// Write appends a byte compiled from the token.
+func (c *Chunk) Write(b byte, at *token.Token) {
+	c.Code = append(c.Code, b)
+	c.Tokens = append(c.Tokens, at)
+}
+
+// AddConstant appends a value to the constant pool and returns its index.
+// Equal strings and numbers share an entry.
+func (c *Chunk) AddConstant(value interface{}) int {
+	switch value.(type) {
+	case string, int64, float64:
+		for idx, constant := range c.Constants {
+			if constant == value {
+				return idx
+			}
+		}
+	}
+	c.Constants = append(c.Constants, value)
+	return len(c.Constants) - 1
+}
+
+// Function ===================================================================
+//
+
+// Function is a compiled Glu function. Scripts, the top-level code of a
+// program or module, are functions with no parameters.
+type Function struct {
+	Name          string
+	Arity         int
+	Chunk         *Chunk
+	UpvalueCount  int
+	IsInitializer bool
+}
+
+// NewFunction constructor.
+func NewFunction(name string, arity int) *Function {
+	return &Function{Name: name, Arity: arity, Chunk: &Chunk{}}
+}
+
+func (f *Function) String() string {
+	return fmt.Sprintf("<fn %s>", f.Name)
+}
+
+// Disassemble ================================================================
+//
+
+// Disassemble returns a listing of the instructions of the function, followed
+// by those of the functions in its constant pool.
+func Disassemble(fn *Function) string {
+	var builder strings.Builder
+	disassemble(&builder, fn)
+	return builder.String()
+}
+
+func disassemble(builder *strings.Builder, fn *Function) {
+	fmt.Fprintf(builder, "== %s ==\n", fn.Name)
+	chunk := fn.Chunk
+	var nested []*Function
+	for offset := 0; offset < len(chunk.Code); {
+		op := OpCode(chunk.Code[offset])
+		fmt.Fprintf(builder, "%04d %s", offset, op)
+		offset++
+		switch op {
+		case OpConstant, OpGetGlobal, OpSetGlobal, OpDefineGlobal,
+			OpGetProperty, OpSetProperty, OpClass, OpSetField,
+			OpMethod, OpCommand, OpGetEnv, OpSetEnv, OpImportModule, OpExport,
+			OpScript:
+			idx := chunk.read16(offset)
+			fmt.Fprintf(builder, " %d '%s'", idx, describe(chunk.Constants[idx]))
+			offset += 2
+		case OpPopLocals, OpGetLocal, OpSetLocal, OpGetUpvalue, OpSetUpvalue,
+			OpSlice, OpCall:
+			fmt.Fprintf(builder, " %d", chunk.Code[offset])
+			offset++
+		case OpList, OpConcat:
+			fmt.Fprintf(builder, " %d", chunk.read16(offset))
+			offset += 2
+		case OpJump, OpJumpIfFalse, OpJumpIfTrue, OpTry:
+			jump := chunk.read16(offset)
+			fmt.Fprintf(builder, " -> %04d", offset+2+jump)
+			offset += 2
+		case OpLoop:
+			jump := chunk.read16(offset)
+			fmt.Fprintf(builder, " -> %04d", offset+2-jump)
+			offset += 2
+		case OpPipe, OpExec, OpImportBash:
+			fmt.Fprintf(builder, " %d %d", chunk.Code[offset], chunk.read16(offset+1))
+			offset += 3
+		case OpClosure:
+			idx := chunk.read16(offset)
+			offset += 2
+			proto := chunk.Constants[idx].(*Function)
+			nested = append(nested, proto)
+			fmt.Fprintf(builder, " %d '%s'", idx, proto)
+			for upvalue := 0; upvalue < proto.UpvalueCount; upvalue++ {
+				kind := "upvalue"
+				if chunk.Code[offset] == 1 {
+					kind = "local"
+				}
+				fmt.Fprintf(builder, " %s:%d", kind, chunk.Code[offset+1])
+				offset += 2
+			}
+		}
+		builder.WriteString("\n")
+	}
+	for _, proto := range nested {
+		disassemble(builder, proto)
+	}
+}
+
+// describe returns a short description of a constant.
+func describe(constant interface{}) string {
+	switch value := constant.(type) {
+	case *token.Token:
+		return value.Lexeme
+	case *ast.FnStmt:
+		return value.Name.Lexeme
+	case fmt.Stringer:
+		return value.String()
+	}
+	return fmt.Sprintf("%v", constant)
+}
+
+// read16 returns the big-endian two byte operand at the offset.
+func (c *Chunk) read16(offset int) int {
+	return int(c.Code[offset])<<8 | int(c.Code[offset+1])
+}
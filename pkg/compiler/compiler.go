@@ -0,0 +1,1080 @@
+package compiler
+
+import (
+	"github.com/templecloud/glu/pkg/ast"
+	"github.com/templecloud/glu/pkg/token"
+)
+
+// Compiler ===================================================================
+//
+
+// Compiler is a Visitor that lowers Glu statements into bytecode Functions
+// for the VM. Variables declared in local scopes live in numbered stack slots
+// of the enclosing function, and variables of enclosing functions are
+// captured as upvalues. Variables that are not local are globals, accessed by
+// name. Constructs that cannot be compiled are reported as Errors.
+type Compiler struct {
+	Errors []*Error
+	// current is the function being compiled.
+	current *state
+}
+
+// New constructor.
+func New() *Compiler {
+	return &Compiler{}
+}
+
+// Compile returns a script that executes the statements, e.g. the body of a
+// module. Any errors are recorded in Errors.
+func (c *Compiler) Compile(stmts []ast.Stmt) *Function {
+	c.begin(NewFunction("script", 0), scriptKind, "")
+	for _, stmt := range stmts {
+		c.statement(stmt)
+	}
+	c.emit(nil, byte(OpNil), byte(OpReturn))
+	fn, _ := c.end()
+	return fn
+}
+
+// CompileStmt returns a script that executes the statement. The script of an
+// expression statement returns the value of the expression, and otherwise
+// nil. Any errors are recorded in Errors.
+func (c *Compiler) CompileStmt(stmt ast.Stmt) *Function {
+	c.begin(NewFunction("script", 0), scriptKind, "")
+	if es, ok := stmt.(*ast.ExprStmt); ok {
+		c.expression(es.Expr)
+	} else {
+		c.statement(stmt)
+		c.emit(nil, byte(OpNil))
+	}
+	c.emit(nil, byte(OpReturn))
+	fn, _ := c.end()
+	return fn
+}
+
+// Limits =====================================================================
+//
+
+const (
+	// maxLocals is the number of local variables a function may declare.
+	maxLocals = 256
+	// maxUpvalues is the number of variables a function may capture.
+	maxUpvalues = 256
+	// maxArguments is the number of arguments a call may pass.
+	maxArguments = 255
+	// maxOperand is the largest two byte operand.
+	maxOperand = 1<<16 - 1
+)
+
+// State ======================================================================
+//
+
+// kind is the kind of function being compiled.
+type kind int
+
+const (
+	scriptKind kind = iota
+	functionKind
+	methodKind
+	initializerKind
+)
+
+// state is the compile state of a function.
+type state struct {
+	enclosing  *state
+	function   *Function
+	kind       kind
+	locals     []*local
+	upvalues   []upvalue
+	scopeDepth int
+	// blocks is the stack of enclosing statements that must be exited by
+	// 'break', 'continue' and 'return', innermost last.
+	blocks []*block
+}
+
+// local is a local variable. Its slot is its index in the locals. A hoisted
+// local is a function whose declaration has not been reached; it is only
+// visible to the functions declared before it.
+type local struct {
+	name     string
+	depth    int
+	captured bool
+	hoisted  bool
+}
+
+// upvalue is a variable captured from the enclosing function; either a local
+// of the enclosing function or one of its upvalues.
+type upvalue struct {
+	index   int
+	isLocal bool
+}
+
+// blockKind is the kind of statement a block is compiled from.
+type blockKind int
+
+const (
+	loopBlock blockKind = iota
+	handlerBlock
+	withBlock
+	namesBlock
+)
+
+// block is a statement that must be exited explicitly.
+type block struct {
+	kind blockKind
+	// locals is the number of locals declared when the block was entered.
+	locals int
+	// breaks and continues are the jumps to patch at the end of a loop.
+	breaks    []int
+	continues []int
+	// finally is the block that runs when a handler is exited, and result
+	// the slot that holds the value returned through it.
+	finally []ast.Stmt
+	result  int
+}
+
+// begin starts compiling a function. Slot 0 holds the function itself, or
+// 'this' for a method.
+func (c *Compiler) begin(fn *Function, k kind, slot0 string) {
+	c.current = &state{
+		enclosing: c.current,
+		function:  fn,
+		kind:      k,
+		locals:    []*local{{name: slot0}},
+	}
+	fn.IsInitializer = k == initializerKind
+}
+
+// end finishes compiling a function and returns it with the variables it
+// captures.
+func (c *Compiler) end() (*Function, []upvalue) {
+	fs := c.current
+	fs.function.UpvalueCount = len(fs.upvalues)
+	c.current = fs.enclosing
+	return fs.function, fs.upvalues
+}
+
+// Expression Functions =======================================================
+//
+
+// VisitAssignExpr compiles the node.
+func (c *Compiler) VisitAssignExpr(expr *ast.Assign) interface{} {
+	c.expression(expr.Value)
+	c.variable(expr.Name, true)
+	return nil
+}
+
+// VisitBinaryExpr compiles the node. A chain of '|' operators is compiled
+// into a single instruction so that commands run as one pipeline.
+func (c *Compiler) VisitBinaryExpr(expr *ast.Binary) interface{} {
+	if expr.Operator.Type == token.Pipe {
+		operands, operators := flattenPipe(expr)
+		for _, operand := range operands {
+			c.expression(operand)
+		}
+		constant := c.constant(expr.Operator, operators)
+		c.emit(expr.Operator, byte(OpPipe), byte(len(operands)))
+		c.emit16(expr.Operator, constant)
+		return nil
+	}
+	c.expression(expr.Left)
+	c.expression(expr.Right)
+	c.emit(expr.Operator, byte(binaryOps[expr.Operator.Type]))
+	return nil
+}
+
+// binaryOps maps the binary operators to their instructions.
+var binaryOps = map[token.Type]OpCode{
+	token.EqualEqual:         OpEqual,
+	token.NotEqual:           OpNotEqual,
+	token.GreaterThan:        OpGreater,
+	token.GreaterThanOrEqual: OpGreaterEqual,
+	token.LessThan:           OpLess,
+	token.LessThanOrEqual:    OpLessEqual,
+	token.Plus:               OpAdd,
+	token.Minus:              OpSubtract,
+	token.Star:               OpMultiply,
+	token.ForwardSlash:       OpDivide,
+	token.ForwardSlashDual:   OpFloorDivide,
+	token.Percent:            OpModulo,
+	token.StarDual:           OpPower,
+	token.Ampersand:          OpBitAnd,
+	token.Caret:              OpBitXor,
+	token.LessThanDual:       OpShiftLeft,
+	token.GreaterThanDual:    OpShiftRight,
+}
+
+// VisitCallExpr compiles the node.
+func (c *Compiler) VisitCallExpr(expr *ast.Call) interface{} {
+	c.expression(expr.Callee)
+	if len(expr.Arguments) > maxArguments {
+		c.error(expr.Paren, "Too many arguments.")
+	}
+	for _, argument := range expr.Arguments {
+		c.expression(argument)
+	}
+	c.emit(expr.Paren, byte(OpCall), byte(len(expr.Arguments)))
+	return nil
+}
+
+// VisitCommandExpr compiles the node.
+func (c *Compiler) VisitCommandExpr(expr *ast.Command) interface{} {
+	c.emitConstantOp(expr.Token, OpCommand, expr.Token)
+	return nil
+}
+
+// VisitConcatExpr compiles the node.
+func (c *Compiler) VisitConcatExpr(expr *ast.Concat) interface{} {
+	for _, part := range expr.Parts {
+		c.expression(part)
+	}
+	c.emit(expr.Token, byte(OpConcat))
+	c.emit16(expr.Token, c.count(expr.Token, len(expr.Parts)))
+	return nil
+}
+
+// VisitEnvVarExpr compiles the node.
+func (c *Compiler) VisitEnvVarExpr(expr *ast.EnvVar) interface{} {
+	c.emitConstantOp(expr.Name, OpGetEnv, expr.Name.Lexeme)
+	return nil
+}
+
+// VisitGetExpr compiles the node.
+func (c *Compiler) VisitGetExpr(expr *ast.Get) interface{} {
+	c.expression(expr.Object)
+	c.emitConstantOp(expr.Name, OpGetProperty, expr.Name.Lexeme)
+	return nil
+}
+
+// VisitGroupingExpr compiles the node.
+func (c *Compiler) VisitGroupingExpr(expr *ast.Grouping) interface{} {
+	c.expression(expr.Expr)
+	return nil
+}
+
+// VisitIndexExpr compiles the node.
+func (c *Compiler) VisitIndexExpr(expr *ast.Index) interface{} {
+	c.expression(expr.Object)
+	c.expression(expr.Index)
+	c.emit(expr.Bracket, byte(OpGetIndex))
+	return nil
+}
+
+// VisitLambdaExpr compiles the node.
+func (c *Compiler) VisitLambdaExpr(expr *ast.Lambda) interface{} {
+	c.function(expr.Fn, functionKind)
+	return nil
+}
+
+// VisitListExpr compiles the node.
+func (c *Compiler) VisitListExpr(expr *ast.List) interface{} {
+	for _, element := range expr.Elements {
+		c.expression(element)
+	}
+	c.emit(expr.Bracket, byte(OpList))
+	c.emit16(expr.Bracket, c.count(expr.Bracket, len(expr.Elements)))
+	return nil
+}
+
+// VisitLiteralExpr compiles the node.
+func (c *Compiler) VisitLiteralExpr(expr *ast.Literal) interface{} {
+	switch expr.Value {
+	case nil:
+		c.emit(nil, byte(OpNil))
+	case true:
+		c.emit(nil, byte(OpTrue))
+	case false:
+		c.emit(nil, byte(OpFalse))
+	default:
+		c.emitConstantOp(nil, OpConstant, expr.Value)
+	}
+	return nil
+}
+
+// VisitLogicalExpr compiles the node. The right operand is skipped if the
+// left operand decides the result.
+func (c *Compiler) VisitLogicalExpr(expr *ast.Logical) interface{} {
+	c.expression(expr.Left)
+	op := OpJumpIfFalse
+	if expr.Operator.Type == token.Or {
+		op = OpJumpIfTrue
+	}
+	end := c.emitJump(expr.Operator, op)
+	c.emit(expr.Operator, byte(OpPop))
+	c.expression(expr.Right)
+	c.patchJump(expr.Operator, end)
+	return nil
+}
+
+// VisitMapExpr compiles the node. Each entry is set as it is evaluated.
+func (c *Compiler) VisitMapExpr(expr *ast.Map) interface{} {
+	c.emit(expr.Brace, byte(OpMap))
+	for idx, key := range expr.Keys {
+		c.expression(key)
+		c.expression(expr.Values[idx])
+		c.emit(expr.Brace, byte(OpMapEntry))
+	}
+	return nil
+}
+
+// VisitNumeralExpr compiles the node.
+func (c *Compiler) VisitNumeralExpr(expr *ast.Numeral) interface{} {
+	c.emitConstantOp(expr.Token, OpConstant, expr.Value)
+	return nil
+}
+
+// VisitReturnExpr compiles the node. Any enclosing blocks of the function are
+// exited, running their finally blocks, before the value is returned. An
+// initializer always returns 'this'.
+func (c *Compiler) VisitReturnExpr(expr *ast.Return) interface{} {
+	if c.current.kind == scriptKind {
+		c.error(expr.Keyword, "Cannot return from top-level code.")
+		return nil
+	}
+	if c.current.kind == initializerKind {
+		if expr.Value != nil {
+			c.expression(expr.Value)
+			c.emit(expr.Keyword, byte(OpPop))
+		}
+		c.emit(expr.Keyword, byte(OpGetLocal), 0)
+	} else if expr.Value != nil {
+		c.expression(expr.Value)
+	} else {
+		c.emit(expr.Keyword, byte(OpNil))
+	}
+	c.exit(expr.Keyword, -1, true)
+	c.emit(expr.Keyword, byte(OpReturn))
+	return nil
+}
+
+// VisitSetExpr compiles the node. The object is checked to be an instance
+// before the value is evaluated.
+func (c *Compiler) VisitSetExpr(expr *ast.Set) interface{} {
+	c.expression(expr.Object)
+	if _, ok := expr.Object.(*ast.This); !ok {
+		c.emit(expr.Name, byte(OpCheckInstance))
+	}
+	c.expression(expr.Value)
+	c.emitConstantOp(expr.Name, OpSetProperty, expr.Name.Lexeme)
+	return nil
+}
+
+// VisitSetEnvExpr compiles the node.
+func (c *Compiler) VisitSetEnvExpr(expr *ast.SetEnv) interface{} {
+	c.expression(expr.Value)
+	c.emitConstantOp(expr.Name, OpSetEnv, expr.Name.Lexeme)
+	return nil
+}
+
+// VisitSetIndexExpr compiles the node.
+func (c *Compiler) VisitSetIndexExpr(expr *ast.SetIndex) interface{} {
+	c.expression(expr.Object)
+	c.expression(expr.Index)
+	c.expression(expr.Value)
+	c.emit(expr.Bracket, byte(OpSetIndex))
+	return nil
+}
+
+// VisitSliceExpr compiles the node.
+func (c *Compiler) VisitSliceExpr(expr *ast.Slice) interface{} {
+	c.expression(expr.Object)
+	var flags byte
+	if expr.Start != nil {
+		c.expression(expr.Start)
+		flags |= 1
+	}
+	if expr.End != nil {
+		c.expression(expr.End)
+		flags |= 2
+	}
+	c.emit(expr.Bracket, byte(OpSlice), flags)
+	return nil
+}
+
+// VisitThisExpr compiles the node.
+func (c *Compiler) VisitThisExpr(expr *ast.This) interface{} {
+	c.variable(expr.Keyword, false)
+	return nil
+}
+
+// VisitUnaryExpr compiles the node.
+func (c *Compiler) VisitUnaryExpr(expr *ast.Unary) interface{} {
+	c.expression(expr.Right)
+	switch expr.Operator.Type {
+	case token.Not:
+		c.emit(expr.Operator, byte(OpNot))
+	case token.Minus:
+		c.emit(expr.Operator, byte(OpNegate))
+	case token.Tilde:
+		c.emit(expr.Operator, byte(OpBitNot))
+	}
+	return nil
+}
+
+// VisitVarExpr compiles the node.
+func (c *Compiler) VisitVarExpr(expr *ast.VarExpr) interface{} {
+	c.variable(expr.Name, false)
+	return nil
+}
+
+// Stmt Functions =============================================================
+//
+
+// VisitBlockStmt compiles the node.
+func (c *Compiler) VisitBlockStmt(stmt *ast.BlockStmt) interface{} {
+	c.block(nil, stmt.Stmts)
+	return nil
+}
+
+// VisitBreakStmt compiles the node.
+func (c *Compiler) VisitBreakStmt(stmt *ast.BreakStmt) interface{} {
+	if loop := c.loop(); loop >= 0 {
+		c.exit(stmt.Keyword, loop, false)
+		jump := c.emitJump(stmt.Keyword, OpJump)
+		c.current.blocks[loop].breaks = append(c.current.blocks[loop].breaks, jump)
+	}
+	return nil
+}
+
+// VisitClassStmt compiles the node. The class is created before its methods
+// so that they are added to it in turn. The field initialisers are compiled
+// into a method that is run on each new instance.
+func (c *Compiler) VisitClassStmt(stmt *ast.ClassStmt) interface{} {
+	c.declare(stmt.Name)
+	c.emitConstantOp(stmt.Name, OpClass, stmt.Name.Lexeme)
+	if len(stmt.Fields) > 0 {
+		c.begin(NewFunction(stmt.Name.Lexeme, 0), methodKind, "this")
+		for _, field := range stmt.Fields {
+			if field.Initialiser != nil {
+				c.expression(field.Initialiser)
+			} else {
+				c.emit(field.Name, byte(OpNil))
+			}
+			c.emitConstantOp(field.Name, OpSetField, field.Name.Lexeme)
+		}
+		c.emit(stmt.Name, byte(OpNil), byte(OpReturn))
+		fn, upvalues := c.end()
+		c.closure(stmt.Name, fn, upvalues)
+		c.emit(stmt.Name, byte(OpFields))
+	}
+	for _, method := range stmt.Methods {
+		k := methodKind
+		if method.Name.Lexeme == "init" {
+			k = initializerKind
+		}
+		c.function(method, k)
+		c.emitConstantOp(method.Name, OpMethod, method.Name.Lexeme)
+	}
+	c.define(stmt.Name)
+	return nil
+}
+
+// VisitContinueStmt compiles the node.
+func (c *Compiler) VisitContinueStmt(stmt *ast.ContinueStmt) interface{} {
+	if loop := c.loop(); loop >= 0 {
+		c.exit(stmt.Keyword, loop, false)
+		jump := c.emitJump(stmt.Keyword, OpJump)
+		c.current.blocks[loop].continues = append(c.current.blocks[loop].continues, jump)
+	}
+	return nil
+}
+
+// VisitExecStmt compiles the node. The redirect targets are evaluated after
+// the arguments.
+func (c *Compiler) VisitExecStmt(stmt *ast.ExecStmt) interface{} {
+	if len(stmt.Arguments) > maxArguments {
+		c.error(stmt.Keyword, "Too many arguments.")
+	}
+	for _, argument := range stmt.Arguments {
+		c.expression(argument)
+	}
+	for _, redirect := range stmt.Redirects {
+		if redirect.Target != nil {
+			c.expression(redirect.Target)
+		}
+	}
+	constant := c.constant(stmt.Keyword, stmt)
+	c.emit(stmt.Keyword, byte(OpExec), byte(len(stmt.Arguments)))
+	c.emit16(stmt.Keyword, constant)
+	return nil
+}
+
+// VisitExportStmt compiles the node.
+func (c *Compiler) VisitExportStmt(stmt *ast.ExportStmt) interface{} {
+	c.statement(stmt.Declaration)
+	c.emitConstantOp(stmt.Keyword, OpExport, declarationName(stmt.Declaration))
+	return nil
+}
+
+// VisitExprStmt compiles the node.
+func (c *Compiler) VisitExprStmt(stmt *ast.ExprStmt) interface{} {
+	c.expression(stmt.Expr)
+	c.emit(nil, byte(OpPop))
+	return nil
+}
+
+// VisitFnStmt compiles the node. The function is declared before its body is
+// compiled so that it can refer to itself recursively. A function hoisted by
+// its block is stored in its slot.
+func (c *Compiler) VisitFnStmt(stmt *ast.FnStmt) interface{} {
+	if slot := c.hoisted(stmt.Name.Lexeme); slot >= 0 {
+		c.function(stmt, functionKind)
+		c.emit(stmt.Name, byte(OpSetLocal), byte(slot), byte(OpPop))
+		c.current.locals[slot].hoisted = false
+		return nil
+	}
+	c.declare(stmt.Name)
+	c.function(stmt, functionKind)
+	c.define(stmt.Name)
+	return nil
+}
+
+// VisitIfStmt compiles the node.
+func (c *Compiler) VisitIfStmt(stmt *ast.IfStmt) interface{} {
+	c.expression(stmt.Condition)
+	thenJump := c.emitJump(nil, OpJumpIfFalse)
+	c.emit(nil, byte(OpPop))
+	c.statement(stmt.ThenBranch)
+	elseJump := c.emitJump(nil, OpJump)
+	c.patchJump(nil, thenJump)
+	c.emit(nil, byte(OpPop))
+	if stmt.ElseBranch != nil {
+		c.statement(stmt.ElseBranch)
+	}
+	c.patchJump(nil, elseJump)
+	return nil
+}
+
+// VisitImportStmt compiles the node. The functions of a bash library imported
+// in a local scope are defined in the names scope of the enclosing block.
+func (c *Compiler) VisitImportStmt(stmt *ast.ImportStmt) interface{} {
+	if stmt.Kind == nil {
+		c.declare(stmt.Alias)
+		c.emitConstantOp(stmt.Path, OpImportModule, stmt.Path)
+		c.define(stmt.Alias)
+		return nil
+	}
+	var local byte
+	if c.current.scopeDepth > 0 {
+		local = 1
+	}
+	constant := c.constant(stmt.Path, stmt.Path)
+	c.emit(stmt.Path, byte(OpImportBash), local)
+	c.emit16(stmt.Path, constant)
+	return nil
+}
+
+// VisitLogStmt compiles the node.
+func (c *Compiler) VisitLogStmt(stmt *ast.LogStmt) interface{} {
+	c.expression(stmt.Expr)
+	c.emit(nil, byte(OpLog))
+	return nil
+}
+
+// VisitThrowStmt compiles the node.
+func (c *Compiler) VisitThrowStmt(stmt *ast.ThrowStmt) interface{} {
+	c.expression(stmt.Value)
+	c.emit(stmt.Keyword, byte(OpThrow))
+	return nil
+}
+
+// VisitTryStmt compiles the node. A handler protects the try block, and the
+// catch block if there is a finally block. On an error, the stack is unwound
+// to the height of the handler and the error is pushed.
+//
+// The finally block is compiled on the normal path and on the error path,
+// after which the error is rethrown. A 'break', 'continue' or 'return' out of
+// the protected blocks compiles a copy of it before the jump, and a returned
+// value is held in a hidden local while it runs.
+func (c *Compiler) VisitTryStmt(stmt *ast.TryStmt) interface{} {
+	c.beginScope()
+	var finally, catch int
+	if stmt.Finally != nil {
+		c.emit(nil, byte(OpNil))
+		c.addLocal(nil, "")
+		finally = c.emitJump(nil, OpTry)
+		c.pushBlock(&block{
+			kind:    handlerBlock,
+			finally: stmt.Finally,
+			result:  len(c.current.locals) - 1,
+		})
+	}
+	if stmt.CatchName != nil {
+		catch = c.emitJump(nil, OpTry)
+		c.pushBlock(&block{kind: handlerBlock})
+	}
+	c.block(nil, stmt.Body)
+	if stmt.CatchName != nil {
+		c.popBlock()
+		c.emit(nil, byte(OpEndTry))
+		skip := c.emitJump(nil, OpJump)
+		c.patchJump(nil, catch)
+		c.block(stmt.CatchName, stmt.Catch)
+		c.patchJump(nil, skip)
+	}
+	if stmt.Finally != nil {
+		c.popBlock()
+		c.emit(nil, byte(OpEndTry))
+		c.block(nil, stmt.Finally)
+		skip := c.emitJump(nil, OpJump)
+		c.patchJump(nil, finally)
+		c.beginScope()
+		c.addLocal(nil, "")
+		c.block(nil, stmt.Finally)
+		c.emit(nil, byte(OpRethrow))
+		c.current.locals = c.current.locals[:len(c.current.locals)-1]
+		c.current.scopeDepth--
+		c.patchJump(nil, skip)
+	}
+	c.endScope()
+	return nil
+}
+
+// VisitVariableStmt compiles the node.
+func (c *Compiler) VisitVariableStmt(stmt *ast.VariableStmt) interface{} {
+	if stmt.Initialiser != nil {
+		c.expression(stmt.Initialiser)
+	} else {
+		c.emit(stmt.Name, byte(OpNil))
+	}
+	c.declare(stmt.Name)
+	c.define(stmt.Name)
+	return nil
+}
+
+// VisitWhileStmt compiles the node. A 'continue' jumps forward to the
+// increment, and a 'break' past the end of the loop.
+func (c *Compiler) VisitWhileStmt(stmt *ast.WhileStmt) interface{} {
+	start := len(c.current.function.Chunk.Code)
+	c.expression(stmt.Condition)
+	exit := c.emitJump(nil, OpJumpIfFalse)
+	c.emit(nil, byte(OpPop))
+	loop := c.pushBlock(&block{kind: loopBlock})
+	c.statement(stmt.Body)
+	for _, jump := range loop.continues {
+		c.patchJump(nil, jump)
+	}
+	if stmt.Increment != nil {
+		c.expression(stmt.Increment)
+		c.emit(nil, byte(OpPop))
+	}
+	c.emitLoop(nil, start)
+	c.popBlock()
+	c.patchJump(nil, exit)
+	c.emit(nil, byte(OpPop))
+	for _, jump := range loop.breaks {
+		c.patchJump(nil, jump)
+	}
+	return nil
+}
+
+// VisitWithStmt compiles the node.
+func (c *Compiler) VisitWithStmt(stmt *ast.WithStmt) interface{} {
+	c.expression(stmt.Env)
+	c.emit(stmt.Keyword, byte(OpWith))
+	c.pushBlock(&block{kind: withBlock})
+	c.block(nil, stmt.Body)
+	c.popBlock()
+	c.emit(stmt.Keyword, byte(OpEndWith))
+	return nil
+}
+
+// Support Functions ==========================================================
+//
+
+func (c *Compiler) statement(stmt ast.Stmt) {
+	stmt.Accept(c)
+}
+
+func (c *Compiler) expression(expr ast.Expr) {
+	expr.Accept(c)
+}
+
+// block compiles the statements in a new scope. The named variable, if any,
+// is declared first in the scope and holds the value on the top of the
+// stack, e.g. a caught error.
+//
+// The functions declared in the block after another function are hoisted to
+// the start of it, so that the functions declared before them can refer to
+// them.
+//
+// A bash library imported in a local scope defines its functions by name, so
+// such a block is compiled inside a names scope.
+func (c *Compiler) block(name *token.Token, stmts []ast.Stmt) {
+	c.beginScope()
+	if name != nil {
+		c.addLocal(name, name.Lexeme)
+	}
+	functions := 0
+	for _, stmt := range stmts {
+		if fn, ok := stmt.(*ast.FnStmt); ok {
+			if functions > 0 {
+				c.emit(fn.Name, byte(OpNil))
+				c.addLocal(fn.Name, fn.Name.Lexeme)
+				c.current.locals[len(c.current.locals)-1].hoisted = true
+			}
+			functions++
+		}
+	}
+	names := importsBash(stmts)
+	if names {
+		c.emit(nil, byte(OpPushNames))
+		c.pushBlock(&block{kind: namesBlock})
+	}
+	for _, stmt := range stmts {
+		c.statement(stmt)
+	}
+	if names {
+		c.popBlock()
+		c.emit(nil, byte(OpPopNames))
+	}
+	c.endScope()
+}
+
+// function compiles the declaration and emits the instruction that creates
+// it. The body of a script function is not Glu, so it is run by the
+// interpreter.
+func (c *Compiler) function(decl *ast.FnStmt, k kind) {
+	if decl.Script != nil {
+		c.emitConstantOp(decl.Name, OpScript, decl)
+		return
+	}
+	slot0 := ""
+	if k == methodKind || k == initializerKind {
+		slot0 = "this"
+	}
+	c.begin(NewFunction(decl.Name.Lexeme, len(decl.Params)), k, slot0)
+	c.beginScope()
+	for _, param := range decl.Params {
+		c.addLocal(param, param.Lexeme)
+	}
+	c.block(nil, decl.Body)
+	if k == initializerKind {
+		c.emit(decl.Name, byte(OpGetLocal), 0)
+	} else {
+		c.emit(decl.Name, byte(OpNil))
+	}
+	c.emit(decl.Name, byte(OpReturn))
+	fn, upvalues := c.end()
+	c.closure(decl.Name, fn, upvalues)
+}
+
+// closure emits the instruction that creates a closure of the function in
+// the current function.
+func (c *Compiler) closure(at *token.Token, fn *Function, upvalues []upvalue) {
+	c.emitConstantOp(at, OpClosure, fn)
+	for _, uv := range upvalues {
+		var isLocal byte
+		if uv.isLocal {
+			isLocal = 1
+		}
+		c.emit(at, isLocal, byte(uv.index))
+	}
+}
+
+// Scopes =====================================================================
+//
+
+func (c *Compiler) beginScope() {
+	c.current.scopeDepth++
+}
+
+// endScope discards the locals of the scope.
+func (c *Compiler) endScope() {
+	fs := c.current
+	fs.scopeDepth--
+	count := 0
+	captured := false
+	for len(fs.locals) > 0 && fs.locals[len(fs.locals)-1].depth > fs.scopeDepth {
+		captured = captured || fs.locals[len(fs.locals)-1].captured
+		fs.locals = fs.locals[:len(fs.locals)-1]
+		count++
+	}
+	c.popLocals(count, captured)
+}
+
+// popLocals emits the instructions that discard the count locals on the top
+// of the stack.
+func (c *Compiler) popLocals(count int, captured bool) {
+	if count == 1 && !captured {
+		c.emit(nil, byte(OpPop))
+	} else if count > 0 {
+		c.emit(nil, byte(OpPopLocals), byte(count))
+	}
+}
+
+// declare adds a local variable to the current scope. Variables of the
+// top-level scope are globals.
+func (c *Compiler) declare(name *token.Token) {
+	if c.current.scopeDepth > 0 {
+		c.addLocal(name, name.Lexeme)
+	}
+}
+
+// define defines the global variable with the value on the top of the stack.
+// A local variable holds the value in its slot.
+func (c *Compiler) define(name *token.Token) {
+	if c.current.scopeDepth == 0 {
+		c.emitConstantOp(name, OpDefineGlobal, name.Lexeme)
+	}
+}
+
+func (c *Compiler) addLocal(at *token.Token, name string) {
+	if len(c.current.locals) == maxLocals {
+		c.error(at, "Too many local variables in function.")
+		return
+	}
+	c.current.locals = append(c.current.locals, &local{name: name, depth: c.current.scopeDepth})
+}
+
+// variable emits the instruction that reads, or assigns, the named variable.
+func (c *Compiler) variable(name *token.Token, assign bool) {
+	get, set := OpGetLocal, OpSetLocal
+	index := resolveLocal(c.current, name.Lexeme, false)
+	if index < 0 {
+		get, set = OpGetUpvalue, OpSetUpvalue
+		index = c.resolveUpvalue(c.current, name)
+	}
+	op := get
+	if assign {
+		op = set
+	}
+	if index < 0 {
+		op = OpGetGlobal
+		if assign {
+			op = OpSetGlobal
+		}
+		c.emitConstantOp(name, op, name.Lexeme)
+		return
+	}
+	c.emit(name, byte(op), byte(index))
+}
+
+// resolveLocal returns the slot of the named local of the function, or -1.
+// Hoisted locals are only resolved for the functions they are captured by.
+func resolveLocal(fs *state, name string, hoisted bool) int {
+	for idx := len(fs.locals) - 1; idx >= 0; idx-- {
+		if fs.locals[idx].name == name && (hoisted || !fs.locals[idx].hoisted) {
+			return idx
+		}
+	}
+	return -1
+}
+
+// hoisted returns the slot of the named function hoisted by the current
+// scope, or -1.
+func (c *Compiler) hoisted(name string) int {
+	fs := c.current
+	for idx := len(fs.locals) - 1; idx >= 0 && fs.locals[idx].depth == fs.scopeDepth; idx-- {
+		if fs.locals[idx].name == name && fs.locals[idx].hoisted {
+			return idx
+		}
+	}
+	return -1
+}
+
+// resolveUpvalue returns the index of the upvalue of the function that
+// captures the named variable of an enclosing function, or -1.
+func (c *Compiler) resolveUpvalue(fs *state, name *token.Token) int {
+	if fs.enclosing == nil {
+		return -1
+	}
+	if local := resolveLocal(fs.enclosing, name.Lexeme, true); local >= 0 {
+		fs.enclosing.locals[local].captured = true
+		return c.addUpvalue(fs, name, local, true)
+	}
+	if upvalue := c.resolveUpvalue(fs.enclosing, name); upvalue >= 0 {
+		return c.addUpvalue(fs, name, upvalue, false)
+	}
+	return -1
+}
+
+func (c *Compiler) addUpvalue(fs *state, name *token.Token, index int, isLocal bool) int {
+	for idx, uv := range fs.upvalues {
+		if uv.index == index && uv.isLocal == isLocal {
+			return idx
+		}
+	}
+	if len(fs.upvalues) == maxUpvalues {
+		c.error(name, "Too many closure variables in function.")
+		return 0
+	}
+	fs.upvalues = append(fs.upvalues, upvalue{index: index, isLocal: isLocal})
+	return len(fs.upvalues) - 1
+}
+
+// Blocks =====================================================================
+//
+
+func (c *Compiler) pushBlock(b *block) *block {
+	b.locals = len(c.current.locals)
+	c.current.blocks = append(c.current.blocks, b)
+	return b
+}
+
+func (c *Compiler) popBlock() {
+	c.current.blocks = c.current.blocks[:len(c.current.blocks)-1]
+}
+
+// loop returns the index of the innermost enclosing loop block, or -1.
+func (c *Compiler) loop() int {
+	for idx := len(c.current.blocks) - 1; idx >= 0; idx-- {
+		if c.current.blocks[idx].kind == loopBlock {
+			return idx
+		}
+	}
+	return -1
+}
+
+// exit emits the instructions that leave the blocks above the target block,
+// innermost first, discarding their locals. If result is true the value on
+// the top of the stack is kept, and the locals are left for the return.
+func (c *Compiler) exit(at *token.Token, target int, result bool) {
+	fs := c.current
+	height := len(fs.locals)
+	for idx := len(fs.blocks) - 1; idx > target; idx-- {
+		b := fs.blocks[idx]
+		switch b.kind {
+		case handlerBlock:
+			if b.finally == nil {
+				c.emit(at, byte(OpEndTry))
+				continue
+			}
+			if result {
+				c.emit(at, byte(OpSetLocal), byte(b.result), byte(OpPop))
+			}
+			c.popLocals(height-b.locals, true)
+			height = b.locals
+			c.emit(at, byte(OpEndTry))
+			c.inline(idx, b.finally)
+			if result {
+				c.emit(at, byte(OpGetLocal), byte(b.result))
+			}
+		case withBlock:
+			c.emit(at, byte(OpEndWith))
+		case namesBlock:
+			c.emit(at, byte(OpPopNames))
+		}
+	}
+	if !result && target >= 0 {
+		c.popLocals(height-fs.blocks[target].locals, true)
+	}
+}
+
+// inline compiles a copy of a finally block as if outside the blocks from the
+// index, and the locals declared within them.
+func (c *Compiler) inline(index int, stmts []ast.Stmt) {
+	fs := c.current
+	blocks, locals := fs.blocks, fs.locals
+	height := fs.blocks[index].locals
+	fs.blocks = blocks[:index:index]
+	fs.locals = locals[:height:height]
+	c.block(nil, stmts)
+	fs.blocks, fs.locals = blocks, locals
+}
+
+// Emit Functions =============================================================
+//
+
+func (c *Compiler) emit(at *token.Token, bytes ...byte) {
+	chunk := c.current.function.Chunk
+	for _, b := range bytes {
+		chunk.Write(b, at)
+	}
+}
+
+func (c *Compiler) emit16(at *token.Token, operand int) {
+	c.emit(at, byte(operand>>8), byte(operand))
+}
+
+// emitConstantOp emits an instruction with a constant operand.
+func (c *Compiler) emitConstantOp(at *token.Token, op OpCode, value interface{}) {
+	constant := c.constant(at, value)
+	c.emit(at, byte(op))
+	c.emit16(at, constant)
+}
+
+// constant adds the value to the constant pool of the current function.
+func (c *Compiler) constant(at *token.Token, value interface{}) int {
+	constant := c.current.function.Chunk.AddConstant(value)
+	if constant > maxOperand {
+		c.error(at, "Too many constants in one chunk.")
+		return 0
+	}
+	return constant
+}
+
+// count checks the number of operands of an instruction.
+func (c *Compiler) count(at *token.Token, count int) int {
+	if count > maxOperand {
+		c.error(at, "Too many elements.")
+		return 0
+	}
+	return count
+}
+
+// emitJump emits a jump instruction and returns the offset of its operand to
+// be patched.
+func (c *Compiler) emitJump(at *token.Token, op OpCode) int {
+	c.emit(at, byte(op), 0xff, 0xff)
+	return len(c.current.function.Chunk.Code) - 2
+}
+
+// patchJump sets the operand of the jump to the current offset.
+func (c *Compiler) patchJump(at *token.Token, offset int) {
+	code := c.current.function.Chunk.Code
+	jump := len(code) - offset - 2
+	if jump > maxOperand {
+		c.error(at, "Too much code to jump over.")
+	}
+	code[offset] = byte(jump >> 8)
+	code[offset+1] = byte(jump)
+}
+
+// emitLoop emits a jump backward to the start offset.
+func (c *Compiler) emitLoop(at *token.Token, start int) {
+	c.emit(at, byte(OpLoop))
+	jump := len(c.current.function.Chunk.Code) - start + 2
+	if jump > maxOperand {
+		c.error(at, "Loop body too large.")
+	}
+	c.emit16(at, jump)
+}
+
+func (c *Compiler) error(at *token.Token, message string) {
+	c.Errors = append(c.Errors, NewError(at, message))
+}
+
+// flattenPipe returns the operands and operators of a left-associative chain
+// of '|' operators in source order.
+func flattenPipe(expr ast.Expr) ([]ast.Expr, []*token.Token) {
+	binary, ok := expr.(*ast.Binary)
+	if !ok || binary.Operator.Type != token.Pipe {
+		return []ast.Expr{expr}, nil
+	}
+	operands, operators := flattenPipe(binary.Left)
+	return append(operands, binary.Right), append(operators, binary.Operator)
+}
+
+// importsBash returns true if the statements import a bash library.
+func importsBash(stmts []ast.Stmt) bool {
+	for _, stmt := range stmts {
+		if is, ok := stmt.(*ast.ImportStmt); ok && is.Kind != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// declarationName returns the name bound by an exportable declaration.
+func declarationName(stmt ast.Stmt) string {
+	switch declaration := stmt.(type) {
+	case *ast.ClassStmt:
+		return declaration.Name.Lexeme
+	case *ast.FnStmt:
+		return declaration.Name.Lexeme
+	case *ast.VariableStmt:
+		return declaration.Name.Lexeme
+	}
+	return ""
+}
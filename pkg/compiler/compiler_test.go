@@ -0,0 +1,278 @@
+package compiler
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/templecloud/glu/pkg/ast"
+	"github.com/templecloud/glu/pkg/lexer"
+	"github.com/templecloud/glu/pkg/parser"
+)
+
+func TestCompile_Script(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"log 1 + 2;", "CONSTANT CONSTANT ADD LOG NIL RETURN"},
+		{"var a = 1; a = a * 2;", "CONSTANT DEFINE_GLOBAL GET_GLOBAL CONSTANT MULTIPLY SET_GLOBAL POP NIL RETURN"},
+		{"{ var a = 1; log -a; }", "CONSTANT GET_LOCAL NEGATE LOG POP NIL RETURN"},
+		{"log a and b;", "GET_GLOBAL JUMP_IF_FALSE POP GET_GLOBAL LOG NIL RETURN"},
+		{"log 1 | 2 | 4;", "CONSTANT CONSTANT CONSTANT PIPE LOG NIL RETURN"},
+		{"log [1][0:];", "CONSTANT LIST CONSTANT SLICE LOG NIL RETURN"},
+		{"log {\"a\": 1};", "MAP CONSTANT CONSTANT MAP_ENTRY LOG NIL RETURN"},
+		{"log \"${a}!\";", "GET_GLOBAL CONSTANT CONCAT LOG NIL RETURN"},
+		{"while (a) a = a - 1;", "GET_GLOBAL JUMP_IF_FALSE POP GET_GLOBAL CONSTANT SUBTRACT SET_GLOBAL POP LOOP POP NIL RETURN"},
+		{"class P { var x = 1; m() {} }", "CLASS CLOSURE FIELDS CLOSURE METHOD DEFINE_GLOBAL NIL RETURN"},
+		{"o.x = 1;", "GET_GLOBAL CHECK_INSTANCE CONSTANT SET_PROPERTY POP NIL RETURN"},
+		{"with env {} { log $A; }", "MAP WITH GET_ENV LOG END_WITH NIL RETURN"},
+		{"{ import bash \"a.sh\"; }", "PUSH_NAMES IMPORT_BASH POP_NAMES NIL RETURN"},
+		{"import \"m.glu\" as m;", "IMPORT_MODULE DEFINE_GLOBAL NIL RETURN"},
+		{"export var a;", "NIL DEFINE_GLOBAL EXPORT NIL RETURN"},
+	}
+	for idx, tt := range tests {
+		c := New()
+		fn := c.Compile(parse(tt.input))
+		if len(c.Errors) > 0 {
+			t.Fatalf("test[%d] - Unexpected errors: %v", idx, c.Errors[0])
+		}
+		actual := opCodes(fn)
+		if tt.expected != actual {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actual)
+		}
+	}
+}
+
+func TestCompile_Disassemble(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			"{ var a = 1; func f() { return a + 1; } log f(); }",
+			"== script ==\n" +
+				"0000 CONSTANT 0 '1'\n" +
+				"0003 CLOSURE 1 '<fn f>' local:1\n" +
+				"0008 GET_LOCAL 2\n" +
+				"0010 CALL 0\n" +
+				"0012 LOG\n" +
+				"0013 POP_LOCALS 2\n" +
+				"0015 NIL\n" +
+				"0016 RETURN\n" +
+				"== f ==\n" +
+				"0000 GET_UPVALUE 0\n" +
+				"0002 CONSTANT 0 '1'\n" +
+				"0005 ADD\n" +
+				"0006 RETURN\n" +
+				"0007 NIL\n" +
+				"0008 RETURN\n",
+		},
+		{
+			"{ func f() { return g(); } func g() { return 1; } }",
+			"== script ==\n" +
+				"0000 NIL\n" +
+				"0001 CLOSURE 0 '<fn f>' local:1\n" +
+				"0006 CLOSURE 1 '<fn g>'\n" +
+				"0009 SET_LOCAL 1\n" +
+				"0011 POP\n" +
+				"0012 POP_LOCALS 2\n" +
+				"0014 NIL\n" +
+				"0015 RETURN\n" +
+				"== f ==\n" +
+				"0000 GET_UPVALUE 0\n" +
+				"0002 CALL 0\n" +
+				"0004 RETURN\n" +
+				"0005 NIL\n" +
+				"0006 RETURN\n" +
+				"== g ==\n" +
+				"0000 CONSTANT 0 '1'\n" +
+				"0003 RETURN\n" +
+				"0004 NIL\n" +
+				"0005 RETURN\n",
+		},
+		{
+			"while (a) { if (b) break; continue; }",
+			"== script ==\n" +
+				"0000 GET_GLOBAL 0 'a'\n" +
+				"0003 JUMP_IF_FALSE -> 0027\n" +
+				"0006 POP\n" +
+				"0007 GET_GLOBAL 1 'b'\n" +
+				"0010 JUMP_IF_FALSE -> 0020\n" +
+				"0013 POP\n" +
+				"0014 JUMP -> 0028\n" +
+				"0017 JUMP -> 0021\n" +
+				"0020 POP\n" +
+				"0021 JUMP -> 0024\n" +
+				"0024 LOOP -> 0000\n" +
+				"0027 POP\n" +
+				"0028 NIL\n" +
+				"0029 RETURN\n",
+		},
+		{
+			"try { log 1; } catch (e) { log e; } finally { log 2; }",
+			"== script ==\n" +
+				"0000 NIL\n" +
+				"0001 TRY -> 0027\n" +
+				"0004 TRY -> 0015\n" +
+				"0007 CONSTANT 0 '1'\n" +
+				"0010 LOG\n" +
+				"0011 END_TRY\n" +
+				"0012 JUMP -> 0019\n" +
+				"0015 GET_LOCAL 2\n" +
+				"0017 LOG\n" +
+				"0018 POP\n" +
+				"0019 END_TRY\n" +
+				"0020 CONSTANT 1 '2'\n" +
+				"0023 LOG\n" +
+				"0024 JUMP -> 0032\n" +
+				"0027 CONSTANT 1 '2'\n" +
+				"0030 LOG\n" +
+				"0031 RETHROW\n" +
+				"0032 POP\n" +
+				"0033 NIL\n" +
+				"0034 RETURN\n",
+		},
+		{
+			"func outer() { var x = 1; func middle() { func inner() { return x; } return inner; } return middle; }",
+			"== script ==\n" +
+				"0000 CLOSURE 0 '<fn outer>'\n" +
+				"0003 DEFINE_GLOBAL 1 'outer'\n" +
+				"0006 NIL\n" +
+				"0007 RETURN\n" +
+				"== outer ==\n" +
+				"0000 CONSTANT 0 '1'\n" +
+				"0003 CLOSURE 1 '<fn middle>' local:1\n" +
+				"0008 GET_LOCAL 2\n" +
+				"0010 RETURN\n" +
+				"0011 POP_LOCALS 2\n" +
+				"0013 NIL\n" +
+				"0014 RETURN\n" +
+				"== middle ==\n" +
+				"0000 CLOSURE 0 '<fn inner>' upvalue:0\n" +
+				"0005 GET_LOCAL 1\n" +
+				"0007 RETURN\n" +
+				"0008 POP\n" +
+				"0009 NIL\n" +
+				"0010 RETURN\n" +
+				"== inner ==\n" +
+				"0000 GET_UPVALUE 0\n" +
+				"0002 RETURN\n" +
+				"0003 NIL\n" +
+				"0004 RETURN\n",
+		},
+		{
+			"if (a) log 1; else log 2; log b or c;",
+			"== script ==\n" +
+				"0000 GET_GLOBAL 0 'a'\n" +
+				"0003 JUMP_IF_FALSE -> 0014\n" +
+				"0006 POP\n" +
+				"0007 CONSTANT 1 '1'\n" +
+				"0010 LOG\n" +
+				"0011 JUMP -> 0019\n" +
+				"0014 POP\n" +
+				"0015 CONSTANT 2 '2'\n" +
+				"0018 LOG\n" +
+				"0019 GET_GLOBAL 3 'b'\n" +
+				"0022 JUMP_IF_TRUE -> 0029\n" +
+				"0025 POP\n" +
+				"0026 GET_GLOBAL 4 'c'\n" +
+				"0029 LOG\n" +
+				"0030 NIL\n" +
+				"0031 RETURN\n",
+		},
+	}
+	for idx, tt := range tests {
+		c := New()
+		fn := c.Compile(parse(tt.input))
+		if len(c.Errors) > 0 {
+			t.Fatalf("test[%d] - Unexpected errors: %v", idx, c.Errors[0])
+		}
+		actual := Disassemble(fn)
+		if tt.expected != actual {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actual)
+		}
+	}
+}
+
+func TestCompile_Stmt(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1 + 2;", "CONSTANT CONSTANT ADD RETURN"},
+		{"log 1;", "CONSTANT LOG NIL RETURN"},
+		{"func f() { return; }", "CLOSURE DEFINE_GLOBAL NIL RETURN"},
+	}
+	for idx, tt := range tests {
+		c := New()
+		fn := c.CompileStmt(parse(tt.input)[0])
+		if len(c.Errors) > 0 {
+			t.Fatalf("test[%d] - Unexpected errors: %v", idx, c.Errors[0])
+		}
+		actual := opCodes(fn)
+		if tt.expected != actual {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actual)
+		}
+	}
+}
+
+func TestCompileError_Return(t *testing.T) {
+	c := New()
+	c.Compile(parse("log 1;\nreturn 2;"))
+	expected := "Cannot return from top-level code. At Line: 2, Column: 1, Token: {return: 'return'}."
+	if len(c.Errors) != 1 {
+		t.Fatalf("Wrong number of errors. Expected=%d, Actual=%d", 1, len(c.Errors))
+	}
+	if expected != c.Errors[0].Error() {
+		t.Fatalf("Expected=%q, Actual=%q", expected, c.Errors[0].Error())
+	}
+}
+
+func TestCompileError_Limits(t *testing.T) {
+	var locals strings.Builder
+	for idx := 0; idx < 256; idx++ {
+		fmt.Fprintf(&locals, "var v%d; ", idx)
+	}
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"func f() { " + locals.String() + "}", "Too many local variables in function."},
+		{"if (a) { " + strings.Repeat("a = 1; ", 10000) + "}", "Too much code to jump over."},
+		{"while (a) { " + strings.Repeat("a = 1; ", 10000) + "}", "Loop body too large."},
+	}
+	for idx, tt := range tests {
+		c := New()
+		c.Compile(parse(tt.input))
+		if len(c.Errors) == 0 {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%v", idx, tt.expected, nil)
+		}
+		if !strings.HasPrefix(c.Errors[0].Error(), tt.expected) {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, c.Errors[0].Error())
+		}
+	}
+}
+
+// Support Functions ==========================================================
+//
+
+func parse(input string) []ast.Stmt {
+	tokens, _ := lexer.New(input).ScanTokens()
+	return parser.New(tokens).Parse()
+}
+
+// opCodes returns the names of the instructions of the function, without
+// their operands.
+func opCodes(fn *Function) string {
+	var ops []string
+	for _, line := range strings.Split(Disassemble(fn), "\n")[1:] {
+		if strings.HasPrefix(line, "==") {
+			break
+		}
+		if fields := strings.Fields(line); len(fields) > 1 {
+			ops = append(ops, fields[1])
+		}
+	}
+	return strings.Join(ops, " ")
+}
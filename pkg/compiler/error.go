@@ -0,0 +1,41 @@
+package compiler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/templecloud/glu/pkg/token"
+)
+
+// Error represents an error encountered during compilation.
+type Error struct {
+	token   *token.Token
+	message string
+}
+
+// NewError create a compile error.
+func NewError(token *token.Token, message string) *Error {
+	return &Error{token: token, message: message}
+}
+
+func (e Error) Error() string {
+	var builder strings.Builder
+
+	if e.token != nil && e.token.Source.Origin != "" {
+		builder.WriteString(e.token.Source.Origin)
+		builder.WriteString(" ")
+	}
+
+	builder.WriteString(e.message)
+
+	if e.token != nil {
+		builder.WriteString(" ")
+		loc := fmt.Sprintf("At Line: %d, Column: %d", e.token.Source.Line+1, e.token.Source.Column+1)
+		builder.WriteString(loc)
+		builder.WriteString(", ")
+		lex := fmt.Sprintf("Token: {%s: '%s'}.", e.token.Type, e.token.Lexeme)
+		builder.WriteString(lex)
+	}
+
+	return builder.String()
+}
@@ -2,6 +2,7 @@ package interpreter
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -43,16 +44,1266 @@ func TestBinary_AssignExpr(t *testing.T) {
 	}
 }
 
+func TestBinary_BashFnStmt(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"func @bash greet(name) { echo \"Hello, $1\" } log greet(\"World\");", "Hello, World"},
+		{"func @bash add(a, b) { echo $(( $1 + $2 )) } log add(1, 2) + \"!\";", "3!"},
+		{"func @bash name() { echo $0 } log name();", "name"},
+		{"func @bash count() { echo $# } log count();", "0"},
+		{"func @bash args(a, b) { echo \"[$1][$2]\" } log args(nil, 1.5);", "[][1.5]"},
+		{"func @bash lines() {\n  for i in 1 2; do\n    echo \"$i\"\n  done\n}\nlog lines();", "1\n2"},
+		{"func @bash braces() { if true; then { echo \"}\"; }; fi } log braces();", "}"},
+		{"func @bash env() { echo $GLU_A } with env {\"GLU_A\": \"a\"} { log env(); }", "a"},
+		{"class C { @bash m(x) { echo \"m $1\" } } log C().m(1);", "m 1"},
+		{"func @bash f() { echo 1 } var g = f; log g;", "<fn f>"},
+		{"func @bash fail() { exit 3 } try { fail(); } catch (e) { log e.message; }",
+			"Function 'fail' exited with status 3."},
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to initialise test: %v", err)
+	}
+	pwd = filepath.Dir(filepath.Dir(pwd))
+
+	for idx, tt := range tests {
+		cmd := fmt.Sprintf("%s/%s", pwd, "dist/glu")
+		out, err := exec.Command(cmd, tt.input).Output()
+		if err != nil {
+			t.Fatalf(
+				"test[%d] Expected no error - Input=%s, ExpectedValue=%v, Error=%v",
+				idx, tt.input, tt.expected, err)
+		}
+		actual := string(out)
+		if tt.expected != actual {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actual)
+		}
+	}
+}
+
+func TestBinaryError_BashFnStmt(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedResult string
+		expectedError  string
+	}{
+		{"func @bash fail() { exit 2 } fail();", "",
+			"Lexeme:) Source:{Origin: Line:0 Column:34 Length:1}}, Function 'fail' exited with status 2."},
+		{"func @bash f(a) { echo $1 } f();", "", "Expected 1 arguments, but, got 0."},
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to initialise test: %v", err)
+	}
+	pwd = filepath.Dir(filepath.Dir(pwd))
+
+	for idx, tt := range tests {
+		cmd := fmt.Sprintf("%s/%s", pwd, "dist/glu")
+		out, err := exec.Command(cmd, tt.input).Output()
+		if err != nil {
+			t.Fatalf(
+				"test[%d] Expected no error - Input=%s, ExpectedValue=%v, Error=%v",
+				idx, tt.input, tt.expectedResult, err)
+		}
+		actual := string(out)
+		if !strings.Contains(actual, tt.expectedResult) {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expectedResult, actual)
+		}
+		if !strings.Contains(actual, tt.expectedError) {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expectedError, actual)
+		}
+	}
+}
+
 func TestBinary_BlockExpr(t *testing.T) {
 	tests := []struct {
 		input    string
 		expected string
 	}{
-		{"{var a = 5; log a; { var a = 4; log a; } log a;}", "545"},
-		{"{var a = 5; log a; { a = 4; log a; } log a;}", "544"},
-		{"{var a = \"a\"; log a; { var a = \"a2\"; log a; } log a;}", "aa2a"},
-		{"var a = 5; log a; { var a = 4; log a; } log a;", "545"},
-		{"var a = 5; log a; { a = 4; log a; } log a;", "544"},
+		{"{var a = 5; log a; { var a = 4; log a; } log a;}", "545"},
+		{"{var a = 5; log a; { a = 4; log a; } log a;}", "544"},
+		{"{var a = \"a\"; log a; { var a = \"a2\"; log a; } log a;}", "aa2a"},
+		{"var a = 5; log a; { var a = 4; log a; } log a;", "545"},
+		{"var a = 5; log a; { a = 4; log a; } log a;", "544"},
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to initialise test: %v", err)
+	}
+	pwd = filepath.Dir(filepath.Dir(pwd))
+
+	for idx, tt := range tests {
+		cmd := fmt.Sprintf("%s/%s", pwd, "dist/glu")
+		out, err := exec.Command(cmd, tt.input).Output()
+		if err != nil {
+			t.Fatalf(
+				"test[%d] Expected no error - Input=%s, ExpectedValue=%v, Error=%v",
+				idx, tt.input, tt.expected, err)
+		}
+		actual := string(out)
+		if tt.expected != actual {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actual)
+		}
+	}
+}
+
+func TestBinaryError_BlockExpr(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedResult string
+		expectedError  string
+	}{
+		{"{var a = 5; log a; { var b = 2; log b; var a = 4; log a; } log a; log b;}",
+			"524",
+			"Undefined variable 'b'"},
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to initialise test: %v", err)
+	}
+	pwd = filepath.Dir(filepath.Dir(pwd))
+
+	for idx, tt := range tests {
+		cmd := fmt.Sprintf("%s/%s", pwd, "dist/glu")
+		out, err := exec.Command(cmd, tt.input).Output()
+		if err != nil {
+			t.Fatalf(
+				"test[%d] Expected no error - Input=%s, ExpectedValue=%v, Error=%v",
+				idx, tt.input, tt.expectedResult, err)
+		}
+		actual := string(out)
+		if !strings.Contains(actual, tt.expectedResult) {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expectedResult, actual)
+		}
+		if !strings.Contains(actual, tt.expectedError) {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expectedError, actual)
+		}
+	}
+}
+
+func TestBinary_BreakStmt(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"while (true) { log 1; break; log 2; }", "1"},
+		{"var x = 0; while (x < 5) { x = x + 1; if (x == 2) continue; log x; }", "1345"},
+		{"var x = 0; while (true) { x = x + 1; if (x > 3) break; log x; }", "123"},
+		{"for (var i = 0; i < 10; i = i + 1) { if (i == 3) break; log i; }", "012"},
+		{"for (var i = 0; i < 5; i = i + 1) { if (i == 1 or i == 3) continue; log i; }", "024"},
+		{"for (var i = 0; i < 3; i = i + 1) { for (var j = 0; j < 3; j = j + 1) { if (j == 1) break; log i; log j; } }", "001020"},
+		{"for (var i = 0; i < 3; i = i + 1) { try { continue; } finally { log i; } }", "012"},
+		{"func f() { for (;;) { return 1; } } log f();", "1"},
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to initialise test: %v", err)
+	}
+	pwd = filepath.Dir(filepath.Dir(pwd))
+
+	for idx, tt := range tests {
+		cmd := fmt.Sprintf("%s/%s", pwd, "dist/glu")
+		out, err := exec.Command(cmd, tt.input).Output()
+		if err != nil {
+			t.Fatalf(
+				"test[%d] Expected no error - Input=%s, ExpectedValue=%v, Error=%v",
+				idx, tt.input, tt.expected, err)
+		}
+		actual := string(out)
+		if tt.expected != actual {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actual)
+		}
+	}
+}
+
+func TestBinary_BuildBash(t *testing.T) {
+	tests := []string{
+		"func fib(n) { if (n < 2) return n; return fib(n - 1) + fib(n - 2); }\nlog fib(10);",
+		"var s = \"\";\nfor (var i = 0; i < 5; i = i + 1) { if (i == 3) continue; s = s + i; }\nlog s;",
+		"var x;\nlog x or \"default\";\nlog 7 // -2;\nlog `echo hi` + \"!\";",
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to initialise test: %v", err)
+	}
+	pwd = filepath.Dir(filepath.Dir(pwd))
+
+	for idx, input := range tests {
+		path := filepath.Join(t.TempDir(), "script.glu")
+		if err := ioutil.WriteFile(path, []byte(input), 0644); err != nil {
+			t.Fatalf("Failed to initialise test: %v", err)
+		}
+		cmd := fmt.Sprintf("%s/%s", pwd, "dist/glu")
+		expected, err := exec.Command(cmd, "-f", path).Output()
+		if err != nil {
+			t.Fatalf("test[%d] Expected no error - Input=%s, Error=%v", idx, input, err)
+		}
+		script, err := exec.Command(cmd, "build", "--target", "bash", path).Output()
+		if err != nil {
+			t.Fatalf("test[%d] Expected no build error - Input=%s, Error=%v", idx, input, err)
+		}
+		actual, err := exec.Command("bash", "-c", string(script)).Output()
+		if err != nil {
+			t.Fatalf("test[%d] Expected no error - Script=%s, Error=%v", idx, script, err)
+		}
+		if string(expected) != string(actual) {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, expected, actual)
+		}
+	}
+}
+
+func TestBinaryError_BuildBash(t *testing.T) {
+	tests := []struct {
+		target        string
+		input         string
+		expectedError string
+	}{
+		{"bash", "log 1;\nvar l = [1];",
+			"Compile Error: Lists are not supported by the bash target. At Line: 2, Column: 9, Token: {LeftBracket: '['}.\n"},
+		{"bash", "log (;", "Parse Error: "},
+		{"zsh", "log 1;", "Unknown build target 'zsh'.\n"},
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to initialise test: %v", err)
+	}
+	pwd = filepath.Dir(filepath.Dir(pwd))
+
+	for idx, tt := range tests {
+		path := filepath.Join(t.TempDir(), "script.glu")
+		if err := ioutil.WriteFile(path, []byte(tt.input), 0644); err != nil {
+			t.Fatalf("Failed to initialise test: %v", err)
+		}
+		cmd := exec.Command(fmt.Sprintf("%s/%s", pwd, "dist/glu"), "build", "--target", tt.target, path)
+		var stderr strings.Builder
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err == nil {
+			t.Fatalf("test[%d] Expected an error - Input=%s, Output=%s", idx, tt.input, out)
+		}
+		if len(out) != 0 {
+			t.Fatalf("test[%d] - Expected no output, Actual=%q", idx, out)
+		}
+		if !strings.HasPrefix(stderr.String(), tt.expectedError) {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expectedError, stderr.String())
+		}
+	}
+}
+
+func TestBinary_CallExpr(t *testing.T) {
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to initialise test: %v", err)
+	}
+	pwd = filepath.Dir(filepath.Dir(pwd))
+	cmd := fmt.Sprintf("%s/%s", pwd, "dist/glu")
+	out, err := exec.Command(cmd, "var x = time(); log x;").Output()
+	if err != nil {
+		t.Fatalf("tet[0] Expected no error, Error=%v", err)
+	}
+	actual := string(out)
+	if "" == actual {
+		t.Fatalf("test[0] - Expected result. Actual=%q", actual)
+	}
+}
+
+func TestBinaryError_CallExpr(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedResult string
+		expectedError  string
+	}{
+		// {"\"non-func\"()", "", "Can only call functions."}, // TODO
+		// {"somefunc(a,b,c,d,e,f,g,h,i)", "", ""}, // TODO
+		{"func a() { b(); } func b() { len(1); } a();", "",
+			"Cannot take the length of '1'.}\n    at len (Line: 1, Column: 35)\n    at b (Line: 1, Column: 14)\n    at a (Line: 1, Column: 42)\n"},
+		{"func f(n) { return f(n + 1); } f(0);", "",
+			"Maximum call depth of 10000 exceeded.}\n    at f (Line: 1, Column: 27)\n    at f (Line: 1, Column: 27)\n    at f (Line: 1, Column: 27)\n    ... repeated 9996 more times\n    at f (Line: 1, Column: 35)\n"},
+		{"func f() { f(); } try { f(); } catch (e) { log e.message; }", "Maximum call depth of 10000 exceeded.", ""},
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to initialise test: %v", err)
+	}
+	pwd = filepath.Dir(filepath.Dir(pwd))
+
+	for idx, tt := range tests {
+		cmd := fmt.Sprintf("%s/%s", pwd, "dist/glu")
+		out, err := exec.Command(cmd, tt.input).Output()
+		if err != nil {
+			t.Fatalf(
+				"test[%d] Expected error - Input=%s, ExpectedValue=%v, Error=%v",
+				idx, tt.input, tt.expectedResult, err)
+		}
+		actual := string(out)
+		if !strings.Contains(actual, tt.expectedResult) {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expectedResult, actual)
+		}
+		if !strings.Contains(actual, tt.expectedError) {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expectedError, actual)
+		}
+	}
+}
+
+func TestBinaryError_MaxDepth(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"--max-depth", "3", "func f(n) { if (n > 0) f(n - 1); } f(2); log \"ok\";"}, "ok"},
+		{[]string{"--max-depth", "3", "func f(n) { if (n > 0) f(n - 1); } f(3);"}, "Maximum call depth of 3 exceeded."},
+		{[]string{"--vm", "--max-depth", "3", "func f(n) { if (n > 0) f(n - 1); } f(3);"}, "Maximum call depth of 3 exceeded."},
+		{[]string{"--max-depth", "3", "--vm", "func f(n) { if (n > 0) f(n - 1); } f(2); log \"ok\";"}, "ok"},
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to initialise test: %v", err)
+	}
+	pwd = filepath.Dir(filepath.Dir(pwd))
+
+	for idx, tt := range tests {
+		cmd := fmt.Sprintf("%s/%s", pwd, "dist/glu")
+		out, err := exec.Command(cmd, tt.args...).Output()
+		if err != nil {
+			t.Fatalf("test[%d] Expected no error - Args=%v, Error=%v", idx, tt.args, err)
+		}
+		actual := string(out)
+		if !strings.Contains(actual, tt.expected) {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actual)
+		}
+	}
+}
+
+func TestBinary_ClassStmt(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"class Point { } log Point;", "Point"},
+		{"class Point { } log Point();", "Point instance"},
+		{"class Point { } var p = Point(); p.x = 1; log p.x;", "1"},
+		{"class Point { var x = 1; var y; } var p = Point(); log p.x; log p.y;", "1nil"},
+		{"class Point { init(x, y) { this.x = x; this.y = y; } } var p = Point(2, 3); log p.x; log p.y;", "23"},
+		{"class Point { init(x) { this.x = x; } getX() { return this.x; } } log Point(4).getX();", "4"},
+		{"class Point { init(x) { this.x = x; } func getX() { return this.x; } } var f = Point(5).getX; log f();", "5"},
+		{"class Point { init() { this.x = 1; return; } } var p = Point(); log p.init().x;", "1"},
+		{"class C { var n = 0; inc() { this.n = this.n + 1; return this; } } log C().inc().inc().n;", "2"},
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to initialise test: %v", err)
+	}
+	pwd = filepath.Dir(filepath.Dir(pwd))
+
+	for idx, tt := range tests {
+		cmd := fmt.Sprintf("%s/%s", pwd, "dist/glu")
+		out, err := exec.Command(cmd, tt.input).Output()
+		if err != nil {
+			t.Fatalf(
+				"test[%d] Expected no error - Input=%s, ExpectedValue=%v, Error=%v",
+				idx, tt.input, tt.expected, err)
+		}
+		actual := string(out)
+		if tt.expected != actual {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actual)
+		}
+	}
+}
+
+func TestBinaryError_ClassStmt(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedResult string
+		expectedError  string
+	}{
+		{"class Point { } log Point().x;", "", "Undefined property 'x'."},
+		{"var p = 1; log p.x;", "", "Only instances have properties."},
+		{"var p = 1; p.x = 2;", "", "Only instances have fields."},
+		{"class Point { init(x) { } } Point();", "", "Expected 1 arguments, but, got 0."},
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to initialise test: %v", err)
+	}
+	pwd = filepath.Dir(filepath.Dir(pwd))
+
+	for idx, tt := range tests {
+		cmd := fmt.Sprintf("%s/%s", pwd, "dist/glu")
+		out, err := exec.Command(cmd, tt.input).Output()
+		if err != nil {
+			t.Fatalf(
+				"test[%d] Expected error - Input=%s, ExpectedValue=%v, Error=%v",
+				idx, tt.input, tt.expectedResult, err)
+		}
+		actual := string(out)
+		if !strings.Contains(actual, tt.expectedResult) {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expectedResult, actual)
+		}
+		if !strings.Contains(actual, tt.expectedError) {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expectedError, actual)
+		}
+	}
+}
+
+func TestBinary_CommandExpr(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"log `echo hello`;", "hello"},
+		{"log $(echo hello);", "hello"},
+		{"log `printf 'a\\nb\\n\\n'`;", "a\nb"},
+		{"var x = $(echo \"a)b\" | tr a-z A-Z); log x + \"!\";", "A)B!"},
+		{"log $(echo $(echo nested));", "nested"},
+		{"log len(`true`);", "0"},
+		{"log `echo out; echo err >&2`;", "out"},
+		{"try { `exit 3`; } catch (e) { log e.message; }", "Command exited with status 3."},
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to initialise test: %v", err)
+	}
+	pwd = filepath.Dir(filepath.Dir(pwd))
+
+	for idx, tt := range tests {
+		cmd := fmt.Sprintf("%s/%s", pwd, "dist/glu")
+		out, err := exec.Command(cmd, tt.input).Output()
+		if err != nil {
+			t.Fatalf(
+				"test[%d] Expected no error - Input=%s, ExpectedValue=%v, Error=%v",
+				idx, tt.input, tt.expected, err)
+		}
+		actual := string(out)
+		if tt.expected != actual {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actual)
+		}
+	}
+}
+
+func TestBinaryError_CommandExpr(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedResult string
+		expectedError  string
+	}{
+		{"log 1; log `exit 3`;", "1",
+			"Lexeme:exit 3 Source:{Origin: Line:0 Column:11 Length:8}}, Command exited with status 3."},
+		{"var x =\n  $(false);", "",
+			"Lexeme:false Source:{Origin: Line:1 Column:2 Length:8}}, Command exited with status 1."},
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to initialise test: %v", err)
+	}
+	pwd = filepath.Dir(filepath.Dir(pwd))
+
+	for idx, tt := range tests {
+		cmd := fmt.Sprintf("%s/%s", pwd, "dist/glu")
+		out, err := exec.Command(cmd, tt.input).Output()
+		if err != nil {
+			t.Fatalf(
+				"test[%d] Expected no error - Input=%s, ExpectedValue=%v, Error=%v",
+				idx, tt.input, tt.expectedResult, err)
+		}
+		actual := string(out)
+		if !strings.Contains(actual, tt.expectedResult) {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expectedResult, actual)
+		}
+		if !strings.Contains(actual, tt.expectedError) {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expectedError, actual)
+		}
+	}
+}
+
+func TestBinary_EnvVarExpr(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"log $GLU_TEST;", "test"},
+		{"log $GLU_UNSET;", "nil"},
+		{"log \"${$GLU_TEST}!\";", "test!"},
+		{"$GLU_TEST = \"changed\"; log $GLU_TEST; log `echo $GLU_TEST`;", "changedchanged"},
+		{"$GLU_NEW = 42; log run(\"sh\", \"-c\", \"printf $GLU_NEW\").stdout;", "42"},
+		{"$GLU_NEW = 1; exec \"sh\", \"-c\", \"printf $GLU_NEW\";", "1"},
+		{"$GLU_NEW = 2; log sh(\"printf $GLU_NEW\") | sh(\"cat\");", "2"},
+		{"$GLU_TEST = nil; log $GLU_TEST; log `echo ${GLU_TEST-unset}`;", "nilunset"},
+		{"$PATH = \"\"; log run(\"ls\").stderr;", "exec: \"ls\": executable file not found in $PATH\n"},
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to initialise test: %v", err)
+	}
+	pwd = filepath.Dir(filepath.Dir(pwd))
+
+	for idx, tt := range tests {
+		cmd := exec.Command(fmt.Sprintf("%s/%s", pwd, "dist/glu"), tt.input)
+		cmd.Env = append(os.Environ(), "GLU_TEST=test")
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf(
+				"test[%d] Expected no error - Input=%s, ExpectedValue=%v, Error=%v",
+				idx, tt.input, tt.expected, err)
+		}
+		actual := string(out)
+		if tt.expected != actual {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actual)
+		}
+	}
+}
+
+func TestBinary_ExecStmt(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"exec \"echo\", \"a  b\";", "a  b\n"},
+		{"exec \"echo\", 1 + 2, [\"a\", \"b\"];", "3 a b\n"},
+		{"exec \"echo\", \"hi\" > \"out.txt\"; log `cat out.txt`;", "hi"},
+		{"exec \"echo\", \"a\" > \"out.txt\"; exec \"echo\", \"b\" >> \"out.txt\"; log `cat out.txt`;", "a\nb"},
+		{"var f = \"out\"; exec \"echo\", \"x\" > f + \".txt\"; log `cat out.txt`;", "x"},
+		{"exec \"sh\", \"-c\", \"echo o; echo e >&2\" > \"out.txt\" 2>&1; log `cat out.txt`;", "o\ne"},
+		{"exec \"sh\", \"-c\", \"echo o; echo e >&2\" 2> \"err.txt\"; log `cat err.txt`;", "o\ne"},
+		{"exec \"sh\", \"-c\", \"echo e >&2\" 2>> \"err.txt\" > \"out.txt\"; log `cat err.txt`;", "e"},
+		{"exec \"echo\", \"in\" > \"in.txt\"; exec \"tr\", \"a-z\", \"A-Z\" < \"in.txt\";", "IN\n"},
+		{"exec \"sh\", \"-c\", \"echo e >&2\" 2>&1;", "e\n"},
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to initialise test: %v", err)
+	}
+	pwd = filepath.Dir(filepath.Dir(pwd))
+
+	for idx, tt := range tests {
+		cmd := exec.Command(fmt.Sprintf("%s/%s", pwd, "dist/glu"), tt.input)
+		cmd.Dir = t.TempDir()
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf(
+				"test[%d] Expected no error - Input=%s, ExpectedValue=%v, Error=%v",
+				idx, tt.input, tt.expected, err)
+		}
+		actual := string(out)
+		if tt.expected != actual {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actual)
+		}
+	}
+}
+
+func TestBinaryError_ExecStmt(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedResult string
+		expectedError  string
+	}{
+		{"exec \"cat\" < \"missing.txt\";", "",
+			"Lexeme:< Source:{Origin: Line:0 Column:11 Length:1}}, Cannot open file 'missing.txt': no such file or directory."},
+		{"exec \"echo\" > \"no/such/dir.txt\";", "",
+			"Lexeme:> Source:{Origin: Line:0 Column:12 Length:1}}, Cannot open file 'no/such/dir.txt': no such file or directory."},
+		{"exec \"echo\" > 1;", "", "Lexeme:> Source:{Origin: Line:0 Column:12 Length:1}}, Redirect target must be a string."},
+		{"exec \"false\";", "", "Lexeme:exec Source:{Origin: Line:0 Column:0 Length:4}}, Command exited with status 1."},
+		{"exec \"glu-no-such-command\";", "", "Command failed: "},
+		{"exec [];", "", "Expected a command to run."},
+		{"exec \"echo\", nil;", "", "Command arguments must not be nil."},
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to initialise test: %v", err)
+	}
+	pwd = filepath.Dir(filepath.Dir(pwd))
+
+	for idx, tt := range tests {
+		cmd := exec.Command(fmt.Sprintf("%s/%s", pwd, "dist/glu"), tt.input)
+		cmd.Dir = t.TempDir()
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf(
+				"test[%d] Expected no error - Input=%s, ExpectedValue=%v, Error=%v",
+				idx, tt.input, tt.expectedResult, err)
+		}
+		actual := string(out)
+		if !strings.Contains(actual, tt.expectedResult) {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expectedResult, actual)
+		}
+		if !strings.Contains(actual, tt.expectedError) {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expectedError, actual)
+		}
+	}
+}
+
+func TestBinary_FnStmt(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"func sayHi(name) { log \"Hello, \"; log name; } sayHi(\"Tim!\");",
+			"Hello, Tim!"},
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to initialise test: %v", err)
+	}
+	pwd = filepath.Dir(filepath.Dir(pwd))
+
+	for idx, tt := range tests {
+		cmd := fmt.Sprintf("%s/%s", pwd, "dist/glu")
+		out, err := exec.Command(cmd, tt.input).Output()
+		if err != nil {
+			t.Fatalf(
+				"test[%d] Expected no error - Input=%s, ExpectedValue=%v, Error=%v",
+				idx, tt.input, tt.expected, err)
+		}
+		actual := string(out)
+		if tt.expected != actual {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actual)
+		}
+	}
+}
+
+func TestBinary_IfStmt(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"if (1 + 1 == 2) log \"test1\";", "test1"},
+		{"if (1 + 1 == 5) log \"test1\";", ""},
+		{"if (1 + 1 == 5) log \"test1\"; else log \"test2\";", "test2"},
+		{"if (1 + 1 == 2) { log \"test1\"; }", "test1"},
+		{"if (1 + 1 == 5) { log \"test1\"; }", ""},
+		{"if (1 + 1 == 5) { log \"test1\"; } else { log \"test2\"; }", "test2"},
+		{"if (1 + 1 == 2) { log \"test1\"; } else { log \"test2\"; }", "test1"},
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to initialise test: %v", err)
+	}
+	pwd = filepath.Dir(filepath.Dir(pwd))
+
+	for idx, tt := range tests {
+		cmd := fmt.Sprintf("%s/%s", pwd, "dist/glu")
+		out, err := exec.Command(cmd, tt.input).Output()
+		if err != nil {
+			t.Fatalf(
+				"test[%d] Expected no error - Input=%s, ExpectedValue=%v, Error=%v",
+				idx, tt.input, tt.expected, err)
+		}
+		actual := string(out)
+		if tt.expected != actual {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actual)
+		}
+	}
+}
+
+func TestBinary_LambdaExpr(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"var add = func (a, b) { return a + b; }; log add(1, 2);", "3"},
+		{"var inc = (a) => a + 1; log inc(1);", "2"},
+		{"log (() => \"hi\")();", "hi"},
+		{"func apply(f, x) { return f(x); } log apply((x) => x * 2, 4);", "8"},
+		{"func apply(f, x) { return f(x); } log apply(func (x) { return x - 1; }, 4);", "3"},
+		{"var add = (a) => (b) => a + b; log add(1)(2);", "3"},
+		{"func makeCounter() { var i = 0; return () => { i = i + 1; return i; }; } var c = makeCounter(); c(); log c();", "2"},
+		{"var fs = [(x) => x + 1, (x) => x * 10]; log fs[1](fs[0](1));", "20"},
+		{"var f = func () { }; log f;", "<fn lambda>"},
+		{"func (a) { log a + 1; }(1); func () { log \"!\"; }();", "2!"},
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to initialise test: %v", err)
+	}
+	pwd = filepath.Dir(filepath.Dir(pwd))
+
+	for idx, tt := range tests {
+		cmd := fmt.Sprintf("%s/%s", pwd, "dist/glu")
+		out, err := exec.Command(cmd, tt.input).Output()
+		if err != nil {
+			t.Fatalf(
+				"test[%d] Expected no error - Input=%s, ExpectedValue=%v, Error=%v",
+				idx, tt.input, tt.expected, err)
+		}
+		actual := string(out)
+		if tt.expected != actual {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actual)
+		}
+	}
+}
+
+func TestBinary_ListExpr(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"log [];", "[]"},
+		{"log [1, \"a\", [true, nil]];", "[1, \"a\", [true, nil]]"},
+		{"var xs = [1, 2, 3]; log xs[0]; log xs[2];", "13"},
+		{"var xs = [1, 2, 3]; log xs[-1]; log xs[-3];", "31"},
+		{"var xs = [[1, 2], [3, 4]]; log xs[1][0];", "3"},
+		{"var xs = [1, 2, 3]; xs[1] = 5; log xs;", "[1, 5, 3]"},
+		{"var xs = [1, 2, 3]; xs[-1] = 5; log xs;", "[1, 2, 5]"},
+		{"var xs = [1, 2, 3]; var ys = xs; ys[0] = 0; log xs;", "[0, 2, 3]"},
+		{"var xs = [1, 2, 3, 4]; log xs[1:3]; log xs[:2]; log xs[2:];", "[2, 3][1, 2][3, 4]"},
+		{"var xs = [1, 2, 3, 4]; log xs[-2:]; log xs[:-1]; log xs[3:1]; log xs[:10];", "[3, 4][1, 2, 3][][1, 2, 3, 4]"},
+		{"var xs = [1, 2]; var ys = xs[:]; ys[0] = 0; log xs;", "[1, 2]"},
+		{"var xs = []; log len(xs); push(xs, 1); push(xs, 2); log len(xs); log xs;", "02[1, 2]"},
+		{"var xs = [1, 2]; log pop(xs); log xs;", "2[1]"},
+		{"log len(\"abc\");", "3"},
+		// Lists that contain themselves.
+		{"var xs = [1]; push(xs, xs); log xs; log \"${xs}\";", "[1, [...]][1, [...]]"},
+		{"var xs = [1]; push(xs, xs); log [xs, xs];", "[[1, [...]], [1, [...]]]"},
+		{"var xs = [1]; push(xs, xs); log xs == xs;", "true"},
+		{"var xs = [1]; push(xs, xs); var ys = [1]; push(ys, ys); log xs == ys;", "true"},
+		{"var xs = [1]; push(xs, xs); var ys = [2]; push(ys, ys); log xs == ys;", "false"},
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to initialise test: %v", err)
+	}
+	pwd = filepath.Dir(filepath.Dir(pwd))
+
+	for idx, tt := range tests {
+		cmd := fmt.Sprintf("%s/%s", pwd, "dist/glu")
+		out, err := exec.Command(cmd, tt.input).Output()
+		if err != nil {
+			t.Fatalf(
+				"test[%d] Expected no error - Input=%s, ExpectedValue=%v, Error=%v",
+				idx, tt.input, tt.expected, err)
+		}
+		actual := string(out)
+		if tt.expected != actual {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actual)
+		}
+	}
+}
+
+func TestBinaryError_ListExpr(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedResult string
+		expectedError  string
+	}{
+		{"var xs = [1]; log xs[1];", "", "List index out of range."},
+		{"var xs = [1]; log xs[-2];", "", "List index out of range."},
+		{"var xs = [1]; xs[1] = 2;", "", "List index out of range."},
+		{"var xs = [1]; log xs[0.5];", "", "Index must be an integer."},
+		{"var xs = [1]; log xs[\"a\"];", "", "Index must be an integer."},
+		{"var x = 1; log x[0];", "", "Only lists and maps can be indexed."},
+		{"var x = 1; log x[0:1];", "", "Only lists can be sliced."},
+		{"pop([]);", "", "Cannot pop from an empty list."},
+		{"push(1, 2);", "", "Argument must be a list."},
+		{"len(1);", "", "Cannot take the length of '1'."},
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to initialise test: %v", err)
+	}
+	pwd = filepath.Dir(filepath.Dir(pwd))
+
+	for idx, tt := range tests {
+		cmd := fmt.Sprintf("%s/%s", pwd, "dist/glu")
+		out, err := exec.Command(cmd, tt.input).Output()
+		if err != nil {
+			t.Fatalf(
+				"test[%d] Expected error - Input=%s, ExpectedValue=%v, Error=%v",
+				idx, tt.input, tt.expectedResult, err)
+		}
+		actual := string(out)
+		if !strings.Contains(actual, tt.expectedResult) {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expectedResult, actual)
+		}
+		if !strings.Contains(actual, tt.expectedError) {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expectedError, actual)
+		}
+	}
+}
+
+func TestBinary_ImportStmt(t *testing.T) {
+	library := strings.Join([]string{
+		"echo 'sourced'",
+		"greet() { echo \"Hello, ${1:-nobody}\"; }",
+		"fail() { echo 'failed' >&2; return 4; }",
+		"count() { echo $#; }",
+		"home() { echo \"$GLU_HOME\"; }",
+	}, "\n")
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"import bash \"lib.sh\"; log greet(\"World\").stdout;", "Hello, World\n"},
+		{"import bash \"lib.sh\"; log greet().ok;", "true"},
+		{"import bash \"lib.sh\"; var r = fail(); log r.code; log r.ok; log r.stderr;", "4falsefailed\n"},
+		{"import bash \"lib.sh\"; log count(1, [2, 3], \"a b\").stdout;", "4\n"},
+		{"import bash \"lib.sh\"; with env {\"GLU_HOME\": \"h\"} { log home().stdout; }", "h\n"},
+		{"import bash \"lib.sh\"; log greet;", "<bash fn greet>"},
+		{"{ import bash \"lib.sh\"; } try { greet; } catch (e) { log e.message; }",
+			"Undefined variable 'greet'."},
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to initialise test: %v", err)
+	}
+	pwd = filepath.Dir(filepath.Dir(pwd))
+
+	for idx, tt := range tests {
+		cmd := exec.Command(fmt.Sprintf("%s/%s", pwd, "dist/glu"), tt.input)
+		cmd.Dir = t.TempDir()
+		err := ioutil.WriteFile(filepath.Join(cmd.Dir, "lib.sh"), []byte(library), 0644)
+		if err != nil {
+			t.Fatalf("Failed to initialise test: %v", err)
+		}
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf(
+				"test[%d] Expected no error - Input=%s, ExpectedValue=%v, Error=%v",
+				idx, tt.input, tt.expected, err)
+		}
+		actual := string(out)
+		if tt.expected != actual {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actual)
+		}
+	}
+}
+
+func TestBinaryError_ImportStmt(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedResult string
+		expectedError  string
+	}{
+		{"import bash \"missing.sh\";", "",
+			"Lexeme:missing.sh Source:{Origin: Line:0 Column:14 Length:10}}, Cannot import 'missing.sh': no such file or directory."},
+		{"import bash \"bad.sh\";", "", "Cannot import 'bad.sh': exited with status 3."},
+		{"import bash \"bad.sh\"; log 1;", "", "Cannot import 'bad.sh': exited with status 3."},
+		{"import bash \"lib.sh\"; func f() { greet(nil); } f();", "",
+			"Command arguments must not be nil.}\n    at greet (Line: 1, Column: 43)\n    at f (Line: 1, Column: 50)\n"},
+		{"import bash \"lib.sh\"; log greet; log len;", "<bash fn greet><native fn len>", ""},
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to initialise test: %v", err)
+	}
+	pwd = filepath.Dir(filepath.Dir(pwd))
+
+	for idx, tt := range tests {
+		cmd := exec.Command(fmt.Sprintf("%s/%s", pwd, "dist/glu"), tt.input)
+		cmd.Dir = t.TempDir()
+		for name, library := range map[string]string{"bad.sh": "exit 3", "lib.sh": "greet() { :; }"} {
+			err := ioutil.WriteFile(filepath.Join(cmd.Dir, name), []byte(library), 0644)
+			if err != nil {
+				t.Fatalf("Failed to initialise test: %v", err)
+			}
+		}
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf(
+				"test[%d] Expected no error - Input=%s, ExpectedValue=%v, Error=%v",
+				idx, tt.input, tt.expectedResult, err)
+		}
+		actual := string(out)
+		if !strings.Contains(actual, tt.expectedResult) {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expectedResult, actual)
+		}
+		if !strings.Contains(actual, tt.expectedError) {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expectedError, actual)
+		}
+	}
+}
+
+func TestBinary_ModuleImportStmt(t *testing.T) {
+	modules := map[string]string{
+		"lib/util.glu": "log \"loading \"; import \"helper.glu\" as helper; var hidden = 1; " +
+			"export var name = \"util\"; export func double(x) { return x * 2; } " +
+			"export func helped() { return helper.value; } export class Point { init(x) { this.x = x; } }",
+		"lib/helper.glu":  "export var value = \"helped\";",
+		"lib/counter.glu": "export var count = 0; export func inc() { count = count + 1; return count; }",
+		"lib/main.glu":    "import \"util.glu\" as u; log u.helped();",
+		"lib/secret.glu":  "export func reveal() { return secret; }",
+		"lib/fails.glu":   "func f() { len(1); }\nf();",
+		"path/found.glu":  "export var found = true;",
+	}
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"import \"lib/util.glu\" as util; log util.double(21);"}, "loading 42"},
+		{[]string{"import \"lib/util.glu\" as util; log util.name; log util.helped();"}, "loading utilhelped"},
+		{[]string{"import \"lib/util.glu\" as util; log util.Point(3).x;"}, "loading 3"},
+		{[]string{"import \"lib/util.glu\" as util; log util;"}, "loading <module lib/util.glu>"},
+		{[]string{"import \"lib/util.glu\" as a; import \"./lib/util.glu\" as b; log a == b;"}, "loading true"},
+		{[]string{"import \"lib/counter.glu\" as a; import \"lib/counter.glu\" as b; a.inc(); log b.inc(); log a.count;"}, "22"},
+		{[]string{"import \"found.glu\" as f; log f.found;"}, "true"},
+		{[]string{"-f", "lib/main.glu"}, "loading helped"},
+		{[]string{"-f", "lib/fails.glu"}, "Runtime Error: {&{Type:RightParen Lexeme:) " +
+			"Source:{Origin:lib/fails.glu Line:0 Column:16 Length:1}}, Cannot take the length of '1'.}\n" +
+			"    at len (Origin: lib/fails.glu, Line: 1, Column: 17)\n    at f (Origin: lib/fails.glu, Line: 2, Column: 3)\n"},
+		{[]string{"--vm", "-f", "lib/fails.glu"}, "Runtime Error: {&{Type:RightParen Lexeme:) " +
+			"Source:{Origin:lib/fails.glu Line:0 Column:16 Length:1}}, Cannot take the length of '1'.}\n" +
+			"    at len (Origin: lib/fails.glu, Line: 1, Column: 17)\n    at f (Origin: lib/fails.glu, Line: 2, Column: 3)\n"},
+		{[]string{"var secret = 1; import \"lib/secret.glu\" as s; try { s.reveal(); } catch (e) { log e.message; }"},
+			"Undefined variable 'secret'."},
+		{[]string{"export var x = 1; log x;"}, "1"},
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to initialise test: %v", err)
+	}
+	pwd = filepath.Dir(filepath.Dir(pwd))
+
+	for idx, tt := range tests {
+		cmd := exec.Command(fmt.Sprintf("%s/%s", pwd, "dist/glu"), tt.args...)
+		cmd.Dir = t.TempDir()
+		cmd.Env = append(os.Environ(), "GLU_PATH="+filepath.Join(cmd.Dir, "missing")+":"+filepath.Join(cmd.Dir, "path"))
+		writeModules(t, cmd.Dir, modules)
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf(
+				"test[%d] Expected no error - Input=%s, ExpectedValue=%v, Error=%v",
+				idx, tt.args, tt.expected, err)
+		}
+		actual := string(out)
+		if tt.expected != actual {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actual)
+		}
+	}
+}
+
+func TestBinaryError_ModuleImportStmt(t *testing.T) {
+	modules := map[string]string{
+		"a.glu":      "import \"lib/b.glu\" as b; export var a = 1;",
+		"lib/b.glu":  "import \"../a.glu\" as a; export var b = 1;",
+		"self.glu":   "import \"self.glu\" as self;",
+		"util.glu":   "var hidden = 1; export var shown = 1;",
+		"broken.glu": "var x = ;",
+		"throws.glu": "throw \"failed\";",
+	}
+	tests := []struct {
+		input          string
+		expectedResult string
+		expectedError  string
+	}{
+		{"import \"missing.glu\" as m;", "",
+			"Lexeme:missing.glu Source:{Origin: Line:0 Column:9 Length:11}}, Cannot import 'missing.glu': no such file or directory."},
+		{"import \"a.glu\" as a;", "", "Import cycle detected: a.glu -> lib/b.glu -> a.glu."},
+		{"import \"self.glu\" as s;", "", "Import cycle detected: self.glu -> self.glu."},
+		{"import \"util.glu\" as u; log u.shown; log u.hidden;", "1", "Module 'util.glu' does not export 'hidden'."},
+		{"import \"broken.glu\" as b;", "", "Cannot import 'broken.glu': broken.glu"},
+		{"import \"throws.glu\" as t;", "", "Origin:throws.glu"},
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to initialise test: %v", err)
+	}
+	pwd = filepath.Dir(filepath.Dir(pwd))
+
+	for idx, tt := range tests {
+		cmd := exec.Command(fmt.Sprintf("%s/%s", pwd, "dist/glu"), tt.input)
+		cmd.Dir = t.TempDir()
+		writeModules(t, cmd.Dir, modules)
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf(
+				"test[%d] Expected no error - Input=%s, ExpectedValue=%v, Error=%v",
+				idx, tt.input, tt.expectedResult, err)
+		}
+		actual := string(out)
+		if !strings.Contains(actual, tt.expectedResult) {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expectedResult, actual)
+		}
+		if !strings.Contains(actual, tt.expectedError) {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expectedError, actual)
+		}
+	}
+}
+
+// writeModules writes the named module sources beneath the directory.
+func writeModules(t *testing.T, dir string, modules map[string]string) {
+	for name, source := range modules {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to initialise test: %v", err)
+		}
+		if err := ioutil.WriteFile(path, []byte(source), 0644); err != nil {
+			t.Fatalf("Failed to initialise test: %v", err)
+		}
+	}
+}
+
+func TestBinary_JobFns(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"var j = spawn(\"sleep\", \"0.2\"); log j; log status(j); log j.running; log j.code;", "<job 1>runningtruenil"},
+		{"var j = spawn(\"sh\", \"-c\", \"echo out; echo err >&2; exit 3\"); var r = wait(j); " +
+			"log r.stdout; log r.stderr; log r.code; log status(j); log j.code; log j.running;",
+			"out\nerr\n3exited3false"},
+		{"var j = spawn(\"sh\", \"-c\", \"echo a; sleep 0.2; echo b\"); var c; while ((c = read(j)) != nil) log \"[\" + c + \"]\";",
+			"[a\n][b\n]"},
+		{"var j = spawn(\"sleep\", 10); log kill(j, \"TERM\"); log wait(j).code; log status(j); log kill(j, 9);",
+			"true143signaledfalse"},
+		{"var j = spawn(\"sleep\", 10); log kill(j, \"sigkill\"); log wait(j).code;", "true137"},
+		{"var a = spawn(\"sleep\", 10); var b = spawn(\"true\"); wait(b); log jobs(); kill(a, 9); wait(a); log jobs();",
+			"[<job 1>][]"},
+		{"var j = spawn(\"echo\", [\"a\", \"b\"]); wait(j); log j.command; log j.id; log j.stdout;", "echo a b1a b\n"},
+		{"$GLU_JOB = \"env\"; log wait(spawn(\"sh\", \"-c\", \"printf $GLU_JOB\")).stdout;", "env"},
+		{"var j = spawn(\"glu-no-such-command\"); var r = wait(j); log r.code; log r.stderr; log status(j); log j.pid; log jobs();",
+			"127exec: \"glu-no-such-command\": executable file not found in $PATH\nexited0[]"},
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to initialise test: %v", err)
+	}
+	pwd = filepath.Dir(filepath.Dir(pwd))
+
+	for idx, tt := range tests {
+		cmd := fmt.Sprintf("%s/%s", pwd, "dist/glu")
+		out, err := exec.Command(cmd, tt.input).Output()
+		if err != nil {
+			t.Fatalf(
+				"test[%d] Expected no error - Input=%s, ExpectedValue=%v, Error=%v",
+				idx, tt.input, tt.expected, err)
+		}
+		actual := string(out)
+		if tt.expected != actual {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actual)
+		}
+	}
+}
+
+func TestBinaryError_JobFns(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedResult string
+		expectedError  string
+	}{
+		{"spawn();", "", "Lexeme:) Source:{Origin: Line:0 Column:6 Length:1}}, Expected a command to run."},
+		{"wait(1);", "", "Argument must be a job."},
+		{"read(nil);", "", "Argument must be a job."},
+		{"status(\"x\");", "", "Argument must be a job."},
+		{"var j = spawn(\"true\"); kill(j, \"FOO\");", "", "Unknown signal 'FOO'."},
+		{"var j = spawn(\"true\"); kill(j, nil);", "", "Signal must be a name or number."},
+		{"var j = spawn(\"true\"); j.nope;", "", "Undefined property 'nope'."},
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to initialise test: %v", err)
+	}
+	pwd = filepath.Dir(filepath.Dir(pwd))
+
+	for idx, tt := range tests {
+		cmd := fmt.Sprintf("%s/%s", pwd, "dist/glu")
+		out, err := exec.Command(cmd, tt.input).Output()
+		if err != nil {
+			t.Fatalf(
+				"test[%d] Expected no error - Input=%s, ExpectedValue=%v, Error=%v",
+				idx, tt.input, tt.expectedResult, err)
+		}
+		actual := string(out)
+		if !strings.Contains(actual, tt.expectedResult) {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expectedResult, actual)
+		}
+		if !strings.Contains(actual, tt.expectedError) {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expectedError, actual)
+		}
+	}
+}
+
+func TestBinary_LogStmt(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"log 1 + 1;", "2"},
+		{"log \"Hello\";", "Hello"},
+		{"log \"Hello, \" + \"World\";", "Hello, World"},
+		{"var n = 3; log \"n=\" + n;", "n=3"},
+		{"log \"ls \" + \"-\" * 2 + \"all\";", "ls --all"},
+		{"log 1.0;", "1.0"},
+		{"log 0.1;", "0.1"},
+		{"log 9007199254740993;", "9007199254740993"},
+		{"log 1 + 0.5;", "1.5"},
+		{"log 7 / 2;", "3.5"},
+		{"log 4 / 2;", "2.0"},
+		{"log 3 * 2;", "6"},
+		{"log 2 ** 10; // 1024", "1024"},
+		{"log 7 // 2 + 7 % 2;", "4"},
+		{"log -7 % 2; log -7 // 2 * 2 + -7 % 2;", "1-7"},
+		{"var m = {1: \"a\"}; log m[1.0];", "a"},
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to initialise test: %v", err)
+	}
+	pwd = filepath.Dir(filepath.Dir(pwd))
+
+	for idx, tt := range tests {
+		cmd := fmt.Sprintf("%s/%s", pwd, "dist/glu")
+		out, err := exec.Command(cmd, tt.input).Output()
+		if err != nil {
+			t.Fatalf(
+				"test[%d] Expected no error - Input=%s, ExpectedValue=%v, Error=%v",
+				idx, tt.input, tt.expected, err)
+		}
+		actual := string(out)
+		if tt.expected != actual {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actual)
+		}
+	}
+}
+
+func TestBinary_LogicalStmt(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"if (true and true) { log true; }", "true"},
+		{"if (true or true) { log true; }", "true"},
+		{"if (true and true or true) { log true; }", "true"},
+		{"if (true and false) { log true; }else { log false; }", "false"},
+		{"if (true or false) { log true; } else { log false; }", "true"},
+		{"if (true and false or false) { log true; } else { log false; }", "false"},
+		{"if (true and false) { log true; } else { log false; }", "false"},
+		{"if (true or false) { log true; } else { log false; }", "true"},
+		{"if (false and true or true) { log true; } else { log false; }", "true"},
+		{"if (true and false or true) { log true; } else { log false; }", "true"},
+		{"if (true and true or false) { log true; } else { log false; }", "true"},
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to initialise test: %v", err)
+	}
+	pwd = filepath.Dir(filepath.Dir(pwd))
+
+	for idx, tt := range tests {
+		cmd := fmt.Sprintf("%s/%s", pwd, "dist/glu")
+		out, err := exec.Command(cmd, tt.input).Output()
+		if err != nil {
+			t.Fatalf(
+				"test[%d] Expected no error - Input=%s, ExpectedValue=%v, Error=%v",
+				idx, tt.input, tt.expected, err)
+		}
+		actual := string(out)
+		if tt.expected != actual {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actual)
+		}
+	}
+}
+
+func TestBinary_MapExpr(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"log {};", "{}"},
+		{"log {\"a\": 1, 2: [true], false: {\"b\": nil}};", "{\"a\": 1, 2: [true], false: {\"b\": nil}}"},
+		{"var m = {\"a\": 1}; log m[\"a\"];", "1"},
+		{"var m = {}; m[\"a\"] = 1; m[\"b\"] = 2; log m;", "{\"a\": 1, \"b\": 2}"},
+		{"var m = {\"a\": 1}; m[\"a\"] = 2; log m;", "{\"a\": 2}"},
+		{"var k = \"a\"; var m = {k: 1}; log m[\"a\"];", "1"},
+		{"var m = {\"z\": 1, \"a\": 2, \"m\": 3}; log keys(m); log values(m);", "[\"z\", \"a\", \"m\"][1, 2, 3]"},
+		{"var m = {\"a\": 1}; log has(m, \"a\"); log has(m, \"b\");", "truefalse"},
+		{"var m = {\"a\": 1, \"b\": 2}; log delete(m, \"a\"); log delete(m, \"a\"); log m;", "truefalse{\"b\": 2}"},
+		{"var m = {\"a\": 1, \"b\": 2}; delete(m, \"a\"); m[\"a\"] = 3; log keys(m);", "[\"b\", \"a\"]"},
+		{"log len({\"a\": 1, \"b\": 2});", "2"},
+		{"log {\"a\": [1, {\"b\": 2}]} == {\"a\": [1, {\"b\": 2}]};", "true"},
+		{"log {\"a\": 1, \"b\": 2} == {\"b\": 2, \"a\": 1};", "true"},
+		{"log {\"a\": 1} == {\"a\": 2}; log {\"a\": 1} == {\"b\": 1}; log {} == [];", "falsefalsefalse"},
+		{"log [1, [2]] == [1, [2]]; log [1] != [2];", "truetrue"},
+		// Maps that contain themselves.
+		{"var m = {}; m[\"a\"] = m; log m; log \"${m}\";", "{\"a\": {...}}{\"a\": {...}}"},
+		{"var m = {}; m[\"a\"] = [m]; log m;", "{\"a\": [{...}]}"},
+		{"var m = {}; m[\"a\"] = m; log m == m;", "true"},
+		{"var m = {}; m[\"a\"] = m; var n = {}; n[\"a\"] = n; log m == n;", "true"},
+		{"var m = {}; m[\"a\"] = m; var n = {}; n[\"b\"] = n; log m == n;", "false"},
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to initialise test: %v", err)
+	}
+	pwd = filepath.Dir(filepath.Dir(pwd))
+
+	for idx, tt := range tests {
+		cmd := fmt.Sprintf("%s/%s", pwd, "dist/glu")
+		out, err := exec.Command(cmd, tt.input).Output()
+		if err != nil {
+			t.Fatalf(
+				"test[%d] Expected no error - Input=%s, ExpectedValue=%v, Error=%v",
+				idx, tt.input, tt.expected, err)
+		}
+		actual := string(out)
+		if tt.expected != actual {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actual)
+		}
+	}
+}
+
+func TestBinaryError_MapExpr(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedResult string
+		expectedError  string
+	}{
+		{"var m = {}; log m[\"a\"];", "", "Undefined key 'a'."},
+		{"var m = {}; m[[1]] = 1;", "", "Map key must be a string, number or boolean."},
+		{"var m = {nil: 1};", "", "Map key must be a string, number or boolean."},
+		{"keys([]);", "", "Argument must be a map."},
+		{"has(1, 1);", "", "Argument must be a map."},
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to initialise test: %v", err)
+	}
+	pwd = filepath.Dir(filepath.Dir(pwd))
+
+	for idx, tt := range tests {
+		cmd := fmt.Sprintf("%s/%s", pwd, "dist/glu")
+		out, err := exec.Command(cmd, tt.input).Output()
+		if err != nil {
+			t.Fatalf(
+				"test[%d] Expected error - Input=%s, ExpectedValue=%v, Error=%v",
+				idx, tt.input, tt.expectedResult, err)
+		}
+		actual := string(out)
+		if !strings.Contains(actual, tt.expectedResult) {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expectedResult, actual)
+		}
+		if !strings.Contains(actual, tt.expectedError) {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expectedError, actual)
+		}
+	}
+}
+
+func TestBinary_NumberExpr(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"log 1.0 / 0;", "inf"},
+		{"log -1.0 / 0;", "-inf"},
+		{"log 0.0 / 0;", "nan"},
+		{"log 1 / 0.0;", "inf"},
+		{"log 1.5 % 0;", "nan"},
+		{"log inf;", "inf"},
+		{"log -inf;", "-inf"},
+		{"log nan;", "nan"},
+		{"log inf - inf;", "nan"},
+		{"log nan == nan;", "false"},
+		{"log nan != nan;", "true"},
+		{"log inf > 9223372036854775807;", "true"},
+		{"log isinf(1.0 / 0);", "true"},
+		{"log isinf(-inf);", "true"},
+		{"log isinf(1);", "false"},
+		{"log isnan(0.0 / 0);", "true"},
+		{"log isnan(1.5);", "false"},
+		{"log 9223372036854775807;", "9223372036854775807"},
+		{"log -9223372036854775807 - 1;", "-9223372036854775808"},
+		{"log 3037000499 * 3037000499;", "9223372030926249001"},
+		{"log 2 ** 62;", "4611686018427387904"},
+		{"log 9223372036854775807 + 1.0;", "9.223372036854776e+18"},
 	}
 	pwd, err := os.Getwd()
 	if err != nil {
@@ -75,15 +1326,24 @@ func TestBinary_BlockExpr(t *testing.T) {
 	}
 }
 
-func TestBinaryError_BlockExpr(t *testing.T) {
+func TestBinaryError_NumberExpr(t *testing.T) {
 	tests := []struct {
 		input          string
 		expectedResult string
 		expectedError  string
 	}{
-		{"{var a = 5; log a; { var b = 2; log b; var a = 4; log a; } log a; log b;}",
-			"524",
-			"Undefined variable 'b'"},
+		{"log 1; log 1 / 0;", "1", "Lexeme:/ Source:{Origin: Line:0 Column:13 Length:1}}, Division by zero."},
+		{"log 1; log 1 // 0;", "1", "Lexeme:// Source:{Origin: Line:0 Column:13 Length:2}}, Division by zero."},
+		{"log 1; log 1 % 0;", "1", "Lexeme:% Source:{Origin: Line:0 Column:13 Length:1}}, Division by zero."},
+		{"var x = 0; log 10 / x;", "", "Lexeme:/ Source:{Origin: Line:0 Column:18 Length:1}}, Division by zero."},
+		{"log 9223372036854775807 + 1;", "", "Lexeme:+ Source:{Origin: Line:0 Column:24 Length:1}}, Integer overflow."},
+		{"log -9223372036854775807 - 2;", "", "Lexeme:- Source:{Origin: Line:0 Column:25 Length:1}}, Integer overflow."},
+		{"log 3037000500 * 3037000500;", "", "Lexeme:* Source:{Origin: Line:0 Column:15 Length:1}}, Integer overflow."},
+		{"log 2 ** 63;", "", "Lexeme:** Source:{Origin: Line:0 Column:6 Length:2}}, Integer overflow."},
+		{"var m = -9223372036854775807 - 1; log -m;", "", "Lexeme:- Source:{Origin: Line:0 Column:38 Length:1}}, Integer overflow."},
+		{"var m = -9223372036854775807 - 1; log m // -1;", "", "Integer overflow."},
+		{"try { 1 / 0; } catch (e) { log e.message; }", "Division by zero.", ""},
+		{"var m = {}; m[0.0 / 0] = 1;", "", "Map key must not be nan."},
 	}
 	pwd, err := os.Getwd()
 	if err != nil {
@@ -109,31 +1369,56 @@ func TestBinaryError_BlockExpr(t *testing.T) {
 	}
 }
 
-func TestBinary_CallExpr(t *testing.T) {
+func TestBinary_PipelineExpr(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"log \"b\\na\\nb\\n\" | sh(\"sort\") | sh(\"uniq -c\") | sh(\"tr -s ' '\");", " 1 a\n 2 b"},
+		{"log [3, 1, 2] | sh(\"sort -n\") | sh(\"paste -sd, -\");", "1,2,3"},
+		{"log sh(\"echo hello\") | sh(\"tr a-z A-Z\");", "HELLO"},
+		{"log sh(\"seq 100000\") | sh(\"wc -l\") | sh(\"tr -d ' '\");", "100000"},
+		{"log sh(\"yes\") | sh(\"head -2\");", "y\ny"},
+		{"var sort = sh(\"sort -r\"); log \"a\\nb\" | sort;", "b\na"},
+		{"var out = \"x\" | sh(\"cat\"); log out + \"!\";", "x!"},
+		{"log 6 | 3;", "7"},
+		{"log 1 | 2 | 4;", "7"},
+	}
 	pwd, err := os.Getwd()
 	if err != nil {
 		t.Fatalf("Failed to initialise test: %v", err)
 	}
 	pwd = filepath.Dir(filepath.Dir(pwd))
-	cmd := fmt.Sprintf("%s/%s", pwd, "dist/glu")
-	out, err := exec.Command(cmd, "var x = time(); log x;").Output()
-	if err != nil {
-		t.Fatalf("tet[0] Expected no error, Error=%v", err)
-	}
-	actual := string(out)
-	if "" == actual {
-		t.Fatalf("test[0] - Expected result. Actual=%q", actual)
+
+	for idx, tt := range tests {
+		cmd := fmt.Sprintf("%s/%s", pwd, "dist/glu")
+		out, err := exec.Command(cmd, tt.input).Output()
+		if err != nil {
+			t.Fatalf(
+				"test[%d] Expected no error - Input=%s, ExpectedValue=%v, Error=%v",
+				idx, tt.input, tt.expected, err)
+		}
+		actual := string(out)
+		if tt.expected != actual {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actual)
+		}
 	}
 }
 
-func TestBinaryError_CallExpr(t *testing.T) {
+func TestBinaryError_PipelineExpr(t *testing.T) {
 	tests := []struct {
 		input          string
 		expectedResult string
 		expectedError  string
 	}{
-		// {"\"non-func\"()", "", "Can only call functions."}, // TODO
-		// {"somefunc(a,b,c,d,e,f,g,h,i)", "", ""}, // TODO
+		{"log sh(\"echo a\") | sh(\"false\") | sh(\"cat\");", "",
+			"Lexeme:| Source:{Origin: Line:0 Column:17 Length:1}}, Pipeline stage 2 'false' exited with status 1."},
+		{"log \"a\" | sh(\"cat\") | sh(\"exit 4\");", "",
+			"Lexeme:| Source:{Origin: Line:0 Column:20 Length:1}}, Pipeline stage 3 'exit 4' exited with status 4."},
+		{"log 1 | sh(\"cat\");", "", "Pipeline input must be a string or list."},
+		{"log \"a\" | sh(\"cat\") | \"b\";", "", "Pipeline stage 3 must be a command."},
+		{"log sh(1);", "", "Argument must be a string."},
+		{"log 1 | \"a\";", "", "Operands must both be integers."},
 	}
 	pwd, err := os.Getwd()
 	if err != nil {
@@ -146,7 +1431,7 @@ func TestBinaryError_CallExpr(t *testing.T) {
 		out, err := exec.Command(cmd, tt.input).Output()
 		if err != nil {
 			t.Fatalf(
-				"test[%d] Expected error - Input=%s, ExpectedValue=%v, Error=%v",
+				"test[%d] Expected no error - Input=%s, ExpectedValue=%v, Error=%v",
 				idx, tt.input, tt.expectedResult, err)
 		}
 		actual := string(out)
@@ -159,13 +1444,23 @@ func TestBinaryError_CallExpr(t *testing.T) {
 	}
 }
 
-func TestBinary_FnStmt(t *testing.T) {
+func TestBinary_Resolver(t *testing.T) {
 	tests := []struct {
 		input    string
 		expected string
 	}{
-		{"func sayHi(name) { log \"Hello, \"; log name; } sayHi(\"Tim!\");",
-			"Hello, Tim!"},
+		{"var a = \"global\"; { func show() { log a; } show(); var a = \"block\"; show(); log a; }",
+			"globalglobalblock"},
+		{"func counter() { var n = 0; return () => { n = n + 1; return n; }; } var c = counter(); c(); log c();", "2"},
+		{"var a = 1; var a = a + 1; log a;", "2"},
+		{"{ var a = 1; { var b = a; var a = 2; log b + a; } }", "3"},
+		{"class P { var x = 1; init(y) { this.y = y; } sum() { return this.x + this.y; } } log P(2).sum();", "3"},
+		{"func fib(n) { if (n < 2) return n; return fib(n - 1) + fib(n - 2); } log fib(10);", "55"},
+		{"{ func f() { return g(); } func g() { return 2; } log f(); }", "2"},
+		{"func h(n) { func even(n) { if (n == 0) return true; return odd(n - 1); } " +
+			"func odd(n) { if (n == 0) return false; return even(n - 1); } return even(n); } log h(7); log h(10);",
+			"falsetrue"},
+		{"var g = 1; { log g; func g() { return 2; } log g(); }", "12"},
 	}
 	pwd, err := os.Getwd()
 	if err != nil {
@@ -188,18 +1483,52 @@ func TestBinary_FnStmt(t *testing.T) {
 	}
 }
 
-func TestBinary_IfStmt(t *testing.T) {
+func TestBinaryError_Resolver(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedResult string
+		expectedError  string
+	}{
+		{"log 1; { var a = 1; var a = 2; }", "",
+			"Resolve Error [0]: Variable 'a' is already declared in this scope. At Line: 1, Column: 25"},
+		{"func f() { var b = b; }", "", "Cannot read local variable 'b' in its own initialiser."},
+		{"log this;", "", "Cannot use 'this' outside of a class."},
+		{"{ func f() { return g(); } f(); func g() {} }", "", "Undefined variable 'g'."},
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to initialise test: %v", err)
+	}
+	pwd = filepath.Dir(filepath.Dir(pwd))
+
+	for idx, tt := range tests {
+		cmd := fmt.Sprintf("%s/%s", pwd, "dist/glu")
+		out, err := exec.Command(cmd, tt.input).Output()
+		if err != nil {
+			t.Fatalf(
+				"test[%d] Expected no error - Input=%s, ExpectedValue=%v, Error=%v",
+				idx, tt.input, tt.expectedResult, err)
+		}
+		actual := string(out)
+		if !strings.Contains(actual, tt.expectedResult) {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expectedResult, actual)
+		}
+		if !strings.Contains(actual, tt.expectedError) {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expectedError, actual)
+		}
+		if strings.HasPrefix(actual, "1") {
+			t.Fatalf("test[%d] - Expected no statements to run, Actual=%q", idx, actual)
+		}
+	}
+}
+
+func TestBinary_ReturnStmt(t *testing.T) {
 	tests := []struct {
 		input    string
 		expected string
 	}{
-		{"if (1 + 1 == 2) log \"test1\";", "test1"},
-		{"if (1 + 1 == 5) log \"test1\";", ""},
-		{"if (1 + 1 == 5) log \"test1\"; else log \"test2\";", "test2"},
-		{"if (1 + 1 == 2) { log \"test1\"; }", "test1"},
-		{"if (1 + 1 == 5) { log \"test1\"; }", ""},
-		{"if (1 + 1 == 5) { log \"test1\"; } else { log \"test2\"; }", "test2"},
-		{"if (1 + 1 == 2) { log \"test1\"; } else { log \"test2\"; }", "test1"},
+		{"'{ func add(a, b) { return a + b; } var c = add(1, 2); log c;}'",
+			"3"},
 	}
 	pwd, err := os.Getwd()
 	if err != nil {
@@ -222,13 +1551,54 @@ func TestBinary_IfStmt(t *testing.T) {
 	}
 }
 
-func TestBinary_LogStmt(t *testing.T) {
+func TestBinaryError_ReturnExpr(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedResult string
+		expectedError  string
+	}{
+		// TODO - This test needs fixing...
+		// {"'func add(a, b) { return a + b }'", "", "Expect ';' after value."},
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to initialise test: %v", err)
+	}
+	pwd = filepath.Dir(filepath.Dir(pwd))
+
+	for idx, tt := range tests {
+		cmd := fmt.Sprintf("%s/%s", pwd, "dist/glu")
+		out, err := exec.Command(cmd, tt.input).Output()
+		if err != nil {
+			t.Fatalf(
+				"test[%d] Expected error - Input=%s, ExpectedValue=%v, Error=%v",
+				idx, tt.input, tt.expectedResult, err)
+		}
+		actual := string(out)
+		if !strings.Contains(actual, tt.expectedResult) {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expectedResult, actual)
+		}
+		if !strings.Contains(actual, tt.expectedError) {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expectedError, actual)
+		}
+	}
+}
+
+func TestBinary_RunFn(t *testing.T) {
 	tests := []struct {
 		input    string
 		expected string
 	}{
-		{"log 1 + 1;", "2"},
-		{"log \"Hello\";", "Hello"},
+		{"var r = run(\"echo\", \"a  b\"); log r.stdout;", "a  b\n"},
+		{"var r = run(\"sh\", \"-c\", \"echo e >&2; exit 3\"); log r.code; log r.stderr;", "3e\n"},
+		{"var r = run(\"true\"); log r.ok; log r.code;", "true0"},
+		{"var r = run(\"false\"); log r.ok; log r.code;", "false1"},
+		{"var r = run(\"echo\", [\"-n\", \"x\"], 1, 2.5); log r.stdout;", "x 1 2.5"},
+		{"var r = run(\"true\"); log r.duration >= 0 and r.duration < 5;", "true"},
+		{"var r = run(\"false\"); if (!r.ok) { log \"failed \" + r.code; }", "failed 1"},
+		{"log run(\"true\");", "<result 0>"},
+		{"var r = run(\"glu-no-such-command\"); log r.ok; log r.code; log r.stderr;",
+			"false127exec: \"glu-no-such-command\": executable file not found in $PATH\n"},
 	}
 	pwd, err := os.Getwd()
 	if err != nil {
@@ -251,22 +1621,57 @@ func TestBinary_LogStmt(t *testing.T) {
 	}
 }
 
-func TestBinary_LogicalStmt(t *testing.T) {
+func TestBinaryError_RunFn(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedResult string
+		expectedError  string
+	}{
+		{"log run();", "", "Expected a command to run."},
+		{"log run(\"true\", nil);", "", "Command arguments must not be nil."},
+		{"log run(\"true\").pid;", "", "Undefined property 'pid'."},
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to initialise test: %v", err)
+	}
+	pwd = filepath.Dir(filepath.Dir(pwd))
+
+	for idx, tt := range tests {
+		cmd := fmt.Sprintf("%s/%s", pwd, "dist/glu")
+		out, err := exec.Command(cmd, tt.input).Output()
+		if err != nil {
+			t.Fatalf(
+				"test[%d] Expected no error - Input=%s, ExpectedValue=%v, Error=%v",
+				idx, tt.input, tt.expectedResult, err)
+		}
+		actual := string(out)
+		if !strings.Contains(actual, tt.expectedResult) {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expectedResult, actual)
+		}
+		if !strings.Contains(actual, tt.expectedError) {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expectedError, actual)
+		}
+	}
+}
+
+func TestBinary_StringExpr(t *testing.T) {
 	tests := []struct {
 		input    string
 		expected string
 	}{
-		{"if (true and true) { log true; }", "true"},
-		{"if (true or true) { log true; }", "true"},
-		{"if (true and true or true) { log true; }", "true"},
-		{"if (true and false) { log true; }else { log false; }", "false"},
-		{"if (true or false) { log true; } else { log false; }", "true"},
-		{"if (true and false or false) { log true; } else { log false; }", "false"},
-		{"if (true and false) { log true; } else { log false; }", "false"},
-		{"if (true or false) { log true; } else { log false; }", "true"},
-		{"if (false and true or true) { log true; } else { log false; }", "true"},
-		{"if (true and false or true) { log true; } else { log false; }", "true"},
-		{"if (true and true or false) { log true; } else { log false; }", "true"},
+		{"log \"a\\tb\";", "a\tb"},
+		{"log \"a\\nb\";", "a\nb"},
+		{"log \"say \\\"hi\\\"\";", "say \"hi\""},
+		{"log \"back\\\\slash\";", "back\\slash"},
+		{"log \"\\u{48}\\u{49} \\u{1F600}\";", "HI 😀"},
+		{"log \"a\nb\";", "a\nb"},
+		{"var name = \"World\"; log \"Hello ${name}!\";", "Hello World!"},
+		{"log \"${1 + 2} = 3\";", "3 = 3"},
+		{"log \"${[1, \"a\"]} ${ {\"k\": nil} }\";", "[1, \"a\"] {\"k\": nil}"},
+		{"var n = 2; log \"a${\"b${n * 2}\"}c\";", "ab4c"},
+		{"log \"\\${name} costs $5\";", "${name} costs $5"},
+		{"func f(x) { return \"<${x}>\"; } log f(1.5);", "<1.5>"},
 	}
 	pwd, err := os.Getwd()
 	if err != nil {
@@ -289,13 +1694,28 @@ func TestBinary_LogicalStmt(t *testing.T) {
 	}
 }
 
-func TestBinary_ReturnStmt(t *testing.T) {
+func TestBinary_TryStmt(t *testing.T) {
 	tests := []struct {
 		input    string
 		expected string
 	}{
-		{"'{ func add(a, b) { return a + b; } var c = add(1, 2); log c;}'",
-			"3"},
+		{"try { log 1; } catch (e) { log 2; }", "1"},
+		{"try { throw \"boom\"; log 1; } catch (e) { log e; }", "boom"},
+		{"try { throw \"boom\"; } catch (e) { log e.message; log e.line; log e.column; }", "boom17"},
+		{"try { throw 1 + 1; } catch (e) { log e.value + 1; }", "3"},
+		{"try { log 1; } finally { log 2; }", "12"},
+		{"try { throw \"a\"; } catch (e) { log e; } finally { log \"b\"; }", "ab"},
+		{"try { try { throw \"a\"; } finally { log \"b\"; } } catch (e) { log e; }", "ba"},
+		{"try { throw \"a\"; } catch (e) { try { throw e; } catch (e2) { log e2; } }", "a"},
+		{"func f() { try { return 1; } finally { log \"f\"; } } log f();", "f1"},
+		{"func f() { try { throw \"a\"; } catch (e) { return e.message; } } log f();", "a"},
+		// Native runtime errors.
+		{"try { log x; } catch (e) { log e.message; }", "Undefined variable 'x'."},
+		{"try { 1 + true; } catch (e) { log e.message; }", "Operands must both be numbers."},
+		{"func f(a) { } try { f(); } catch (e) { log e.message; }", "Expected 1 arguments, but, got 0."},
+		// Glu stack.
+		{"func a() { throw \"x\"; } func b() { a(); } try { b(); } catch (e) { log e.stack; }",
+			"at a (Line: 1, Column: 38)\nat b (Line: 1, Column: 51)"},
 	}
 	pwd, err := os.Getwd()
 	if err != nil {
@@ -318,14 +1738,15 @@ func TestBinary_ReturnStmt(t *testing.T) {
 	}
 }
 
-func TestBinaryError_ReturnExpr(t *testing.T) {
+func TestBinaryError_TryStmt(t *testing.T) {
 	tests := []struct {
 		input          string
 		expectedResult string
 		expectedError  string
 	}{
-		// TODO - This test needs fixing...
-		// {"'func add(a, b) { return a + b }'", "", "Expect ';' after value."},
+		{"throw \"boom\";", "", "boom"},
+		{"try { throw \"a\"; } finally { log \"b\"; }", "b", "Runtime Error"},
+		{"try { throw \"a\"; } catch (e) { log e.nope; }", "", "Undefined property 'nope'."},
 	}
 	pwd, err := os.Getwd()
 	if err != nil {
@@ -444,3 +1865,148 @@ func TestBinary_ForStmt(t *testing.T) {
 		}
 	}
 }
+
+func TestBinary_WithStmt(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"with env {\"GLU_A\": \"a\"} { log `echo $GLU_A`; } log $GLU_A;", "anil"},
+		{"with env {\"GLU_TEST\": 1} { log $GLU_TEST; } log $GLU_TEST;", "1test"},
+		{"with env {\"GLU_TEST\": nil} { log $GLU_TEST; } log $GLU_TEST;", "niltest"},
+		{"var e = {\"GLU_A\": \"x\"}; with env e { log run(\"sh\", \"-c\", \"printf $GLU_A\").stdout; }", "x"},
+		{"with env {\"GLU_A\": \"1\"} { with env {\"GLU_A\": \"2\"} { log $GLU_A; } log $GLU_A; } log $GLU_A;", "21nil"},
+		{"with env {\"GLU_A\": \"1\"} { $GLU_B = \"2\"; } log $GLU_A; log $GLU_B;", "nil2"},
+		{"func f() { with env {\"GLU_A\": \"1\"} { return $GLU_A; } } log f(); log $GLU_A;", "1nil"},
+		{"try { with env {\"GLU_A\": \"1\"} { throw \"x\"; } } catch (e) { log $GLU_A; }", "nil"},
+		{"for (var i = 0; i < 2; i = i + 1) { with env {\"GLU_A\": i} { break; } } log $GLU_A;", "nil"},
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to initialise test: %v", err)
+	}
+	pwd = filepath.Dir(filepath.Dir(pwd))
+
+	for idx, tt := range tests {
+		cmd := exec.Command(fmt.Sprintf("%s/%s", pwd, "dist/glu"), tt.input)
+		cmd.Env = append(os.Environ(), "GLU_TEST=test")
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf(
+				"test[%d] Expected no error - Input=%s, ExpectedValue=%v, Error=%v",
+				idx, tt.input, tt.expected, err)
+		}
+		actual := string(out)
+		if tt.expected != actual {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actual)
+		}
+	}
+}
+
+func TestBinaryError_WithStmt(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedResult string
+		expectedError  string
+	}{
+		{"with env 1 { }", "", "Lexeme:with Source:{Origin: Line:0 Column:0 Length:4}}, Expected a map of environment variables."},
+		{"with env {1: \"a\"} { }", "", "Environment variable names must be strings."},
+		{"with env {\"A\": [1]} { }", "", "Environment variable values must be strings, numbers or booleans."},
+		{"$A = {};", "", "Environment variable values must be strings, numbers or booleans."},
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to initialise test: %v", err)
+	}
+	pwd = filepath.Dir(filepath.Dir(pwd))
+
+	for idx, tt := range tests {
+		cmd := fmt.Sprintf("%s/%s", pwd, "dist/glu")
+		out, err := exec.Command(cmd, tt.input).Output()
+		if err != nil {
+			t.Fatalf(
+				"test[%d] Expected no error - Input=%s, ExpectedValue=%v, Error=%v",
+				idx, tt.input, tt.expectedResult, err)
+		}
+		actual := string(out)
+		if !strings.Contains(actual, tt.expectedResult) {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expectedResult, actual)
+		}
+		if !strings.Contains(actual, tt.expectedError) {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expectedError, actual)
+		}
+	}
+}
+
+func TestBinary_VM(t *testing.T) {
+	tests := []string{
+		"func f() { try { return 1; } finally { log \"f\"; } } log f();",
+		"func f() { var a = 1; try { var b = 2; return a + b; } finally { var c = 10; log c; } } log f();",
+		"func f() { try { throw \"x\"; } catch (e) { return e.message; } finally { log \"fin\"; } } log f();",
+		"func f() { try { throw \"x\"; } finally { return 5; } } log f();",
+		"for (var i = 0; i < 3; i = i + 1) { try { if (i == 1) continue; log i; } finally { log \"f\"; } }",
+		"for (var i = 0; i < 3; i = i + 1) { try { if (i == 1) break; log i; } finally { log \"f\"; } } log \"end\";",
+		"var fs = []; for (var i = 0; i < 3; i = i + 1) { var j = i; push(fs, () => j); } log fs[0]() + fs[1]() + fs[2]();",
+		"var fs = []; for (var i = 0; i < 3; i = i + 1) { push(fs, () => i); } log fs[0]() + fs[1]() + fs[2]();",
+		"while (true) { with env {\"GLU_X\": \"1\"} { log $GLU_X; break; } } log $GLU_X;",
+		"{ import bash \"examples/none.sh\"; }",
+		"func a() { b(); } func b() { throw \"boom\"; } try { a(); } catch (e) { log e.stack; }",
+		"func a() { b(); } func b() { len(1, 2); } a();",
+		"func (a) { log a + 1; }(1);",
+		"func a() { b(); } func b() { len(1); } try { a(); } catch (e) { log e.stack; log e.message; }",
+		"class A { var x = y; } try { A(); } catch (e) { log e.stack; log e.message; }",
+		"class A { init(a) { this.a = a; return 7; } } var o = A(3); log o.a; log o; log o.init(4); log o.a;",
+		"class C { var n = 0; inc() { this.n = this.n + 1; return this; } } log C().inc().inc().n;",
+		"class C { m() { return () => this.v; } } var c = C(); c.v = 9; log c.m()();",
+		"var x = 1; x.y = (log \"side\");",
+		"try { try { throw 1; } finally { log \"a\"; } } catch (e) { log e.value; }",
+		"try { throw [1,2]; } catch (e) { log e.value; }",
+		"func counter() { var c = 0; return () => { c = c + 1; return c; }; } var k = counter(); k(); log k();",
+		"log 9223372036854775807 + 1;",
+		"log 1 + 2.5; log \"a\" + 1; log 3 - 5; log 7 // 2; log 2 ** 10; log 1 < 2.5;",
+		"func f(n) { if (n == 0) throw \"deep\"; f(n - 1); } try { f(3); } catch (e) { log e.stack; }",
+		"var m = {\"a\": 1}; m[\"b\"] = 2; log m; log [1,2,3][1:]; log [1,2,3][:2]; log \"${1 + 1}x\";",
+		"log 1 | 2 | 4; log `echo hi`; log \"abc\" | `tr a-z A-Z`;",
+		"func outer() { var x = 1; func inner() { x = x + 1; return x; } inner(); return inner(); } log outer();",
+		"{ var a = 1; { var b = 2; func g() { return a + b; } log g(); } }",
+		"func f() { try { return 1; } finally { try { log \"in\"; } finally { log \"out\"; } } } log f();",
+		"func f() { while (true) { try { try { break; } finally { log 1; } } finally { log 2; } } return 3; } log f();",
+		"log fibo;",
+		"class P { @bash hi(x) { echo \"hi $1\" } } log P().hi(2); log P().hi;",
+		"func f() { return; } log f();",
+		"var a = 1; { var a = 2; log a; } log a;",
+		"{ func f() { return g(); } func g() { return 2; } log f(); }",
+		"func h(n) { func even(n) { if (n == 0) return true; return odd(n - 1); } " +
+			"func odd(n) { if (n == 0) return false; return even(n - 1); } return even(n); } log h(7); log h(10);",
+		"var g = 1; { log g; var x = 3; func g() { return x; } log g(); }",
+		"class K {} log K; log K(); log K().x;",
+		"log len; log clock;",
+		"func f(n) { return f(n + 1); } f(0);",
+		"func f() { f(); } try { f(); } catch (e) { log e.message; log len(e.stack); }",
+		"class A { init() { A(); } } A();",
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to initialise test: %v", err)
+	}
+	pwd = filepath.Dir(filepath.Dir(pwd))
+
+	for idx, input := range tests {
+		cmd := fmt.Sprintf("%s/%s", pwd, "dist/glu")
+		walk := exec.Command(cmd, input)
+		walk.Dir = pwd
+		expected, err := walk.Output()
+		if err != nil {
+			t.Fatalf("test[%d] Expected no error - Input=%s, Error=%v", idx, input, err)
+		}
+		machine := exec.Command(cmd, "--vm", input)
+		machine.Dir = pwd
+		actual, err := machine.Output()
+		if err != nil {
+			t.Fatalf("test[%d] Expected no error - Input=%s, Error=%v", idx, input, err)
+		}
+		if string(expected) != string(actual) {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, expected, actual)
+		}
+	}
+}
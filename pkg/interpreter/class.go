@@ -0,0 +1,135 @@
+package interpreter
+
+import (
+	"fmt"
+
+	"github.com/templecloud/glu/pkg/ast"
+	"github.com/templecloud/glu/pkg/token"
+)
+
+// initializer is the name of the method invoked when a class is instantiated.
+const initializer = "init"
+
+// GluObject ==================================================================
+//
+
+// GluObject represents a runtime value that has readable properties.
+type GluObject interface {
+	Get(name *token.Token) interface{}
+}
+
+// GluClass ===================================================================
+//
+
+// GluClass represents a class. Calling a class creates a new instance.
+type GluClass struct {
+	Name    string
+	Fields  []*ast.VariableStmt
+	Methods map[string]*GluFn
+	Closure *Environment
+}
+
+// NewGluClass creates a GluClass.
+func NewGluClass(
+	name string,
+	fields []*ast.VariableStmt,
+	methods map[string]*GluFn,
+	environment *Environment,
+) *GluClass {
+	return &GluClass{
+		Name:    name,
+		Fields:  fields,
+		Methods: methods,
+		Closure: environment,
+	}
+}
+
+// FindMethod returns the named method or nil if the class does not define it.
+func (gc *GluClass) FindMethod(name string) *GluFn {
+	if method, ok := gc.Methods[name]; ok {
+		return method
+	}
+	return nil
+}
+
+// Arity returns the number of parameters the class initializer has.
+func (gc *GluClass) Arity() int {
+	if init := gc.FindMethod(initializer); init != nil {
+		return init.Arity()
+	}
+	return 0
+}
+
+// Call / Invoke this GluClass to create a new GluInstance.
+func (gc *GluClass) Call(
+	interpreter *Interpreter,
+	arguments []interface{},
+) interface{} {
+	instance := NewGluInstance(gc)
+	gc.initialiseFields(interpreter, instance)
+	if init := gc.FindMethod(initializer); init != nil {
+		init.Bind(instance).Call(interpreter, arguments)
+	}
+	return instance
+}
+
+func (gc *GluClass) String() string {
+	return gc.Name
+}
+
+// initialiseFields evaluates the field initialisers of the class with 'this'
+// bound to the new instance.
+func (gc *GluClass) initialiseFields(
+	interpreter *Interpreter,
+	instance *GluInstance,
+) {
+	environment := NewChildEnvironment(gc.Closure)
+	environment.Declare(instance)
+	previous := interpreter.Environment
+	defer func() {
+		interpreter.Environment = previous
+	}()
+	interpreter.Environment = environment
+	for _, field := range gc.Fields {
+		var value interface{}
+		if field.Initialiser != nil {
+			value = interpreter.evaluate(field.Initialiser)
+		}
+		instance.Fields[field.Name.Lexeme] = value
+	}
+}
+
+// GluInstance ================================================================
+//
+
+// GluInstance represents an instance of a GluClass.
+type GluInstance struct {
+	Class  *GluClass
+	Fields map[string]interface{}
+}
+
+// NewGluInstance creates a GluInstance of the specified class.
+func NewGluInstance(class *GluClass) *GluInstance {
+	return &GluInstance{Class: class, Fields: make(map[string]interface{})}
+}
+
+// Get returns the named property of the instance. Fields shadow methods.
+func (gi *GluInstance) Get(name *token.Token) interface{} {
+	if value, ok := gi.Fields[name.Lexeme]; ok {
+		return value
+	}
+	if method := gi.Class.FindMethod(name.Lexeme); method != nil {
+		return method.Bind(gi)
+	}
+	err := fmt.Sprintf("Undefined property '%s'.", name.Lexeme)
+	panic(NewError(name, err))
+}
+
+// Set assigns the named property of the instance.
+func (gi *GluInstance) Set(name *token.Token, value interface{}) {
+	gi.Fields[name.Lexeme] = value
+}
+
+func (gi *GluInstance) String() string {
+	return fmt.Sprintf("%s instance", gi.Class.Name)
+}
@@ -0,0 +1,253 @@
+package interpreter
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/templecloud/glu/pkg/ast"
+	"github.com/templecloud/glu/pkg/token"
+)
+
+// Command Functions ==========================================================
+//
+
+// shell is the program used to run command expressions.
+const shell = "bash"
+
+// RunCommand runs the command text of the token with 'bash -c' and returns
+// its standard output with trailing newlines removed, like bash command
+// substitution. Standard input and standard error are inherited from the
+// interpreter. A command that cannot be started, or that exits with a
+// non-zero status, is a runtime error at the token.
+func RunCommand(command *token.Token, env *ProcessEnvironment) string {
+	var stdout bytes.Buffer
+	cmd := env.Command(shell, "-c", command.Lexeme)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			msg := fmt.Sprintf("Command exited with status %d.", exitErr.ExitCode())
+			panic(NewError(command, msg))
+		}
+		panic(NewError(command, fmt.Sprintf("Command failed: %v.", err)))
+	}
+	return strings.TrimRight(stdout.String(), "\n")
+}
+
+// runScript runs the body of a '@bash' function with 'bash -c', binding the
+// arguments to the positional parameters '$1..$n' and the function name to
+// '$0'. It returns the captured standard output with trailing newlines
+// removed. Errors are raised without a token so that they are reported at the
+// call.
+func runScript(fn *ast.FnStmt, arguments []interface{}, env *ProcessEnvironment) string {
+	args := []string{"-c", fn.Script.Lexeme, fn.Name.Lexeme}
+	for _, argument := range arguments {
+		if argument == nil {
+			// nil is passed as an empty parameter, like an unset variable.
+			args = append(args, "")
+			continue
+		}
+		args = append(args, stringify(argument))
+	}
+	var stdout bytes.Buffer
+	cmd := env.Command(shell, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			msg := fmt.Sprintf("Function '%s' exited with status %d.",
+				fn.Name.Lexeme, exitErr.ExitCode())
+			panic(NewError(nil, msg))
+		}
+		panic(NewError(nil, fmt.Sprintf("Command failed: %v.", err)))
+	}
+	return strings.TrimRight(stdout.String(), "\n")
+}
+
+// execute evaluates the arguments and redirect targets of the statement and
+// runs the external program.
+func (i *Interpreter) execute(stmt *ast.ExecStmt) {
+	var arguments, targets []interface{}
+	for _, argument := range stmt.Arguments {
+		arguments = append(arguments, i.evaluate(argument))
+	}
+	for _, redirect := range stmt.Redirects {
+		if redirect.Target != nil {
+			targets = append(targets, i.evaluate(redirect.Target))
+		}
+	}
+	Execute(stmt.Keyword, arguments, stmt.Redirects, targets, i.Process)
+}
+
+// Execute runs an external program with its standard streams redirected as
+// specified. The targets are the values of the redirects that have a target,
+// in order. A program that cannot be started, or that exits with a non-zero
+// status, is a runtime error at the keyword.
+func Execute(
+	keyword *token.Token,
+	arguments []interface{},
+	redirects []*ast.Redirect,
+	targets []interface{},
+	env *ProcessEnvironment,
+) {
+	args := commandArguments(keyword, arguments)
+	if len(args) == 0 {
+		panic(NewError(keyword, "Expected a command to run."))
+	}
+
+	files := []*os.File{os.Stdin, os.Stdout, os.Stderr}
+	var opened []*os.File
+	defer func() {
+		for _, file := range opened {
+			file.Close()
+		}
+	}()
+	for _, redirect := range redirects {
+		if redirect.Target == nil {
+			files[redirect.FD] = files[redirect.TargetFD]
+			continue
+		}
+		file := openRedirect(redirect.Operator, targets[0])
+		targets = targets[1:]
+		opened = append(opened, file)
+		files[redirect.FD] = file
+	}
+
+	cmd := env.Command(args[0], args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = files[0], files[1], files[2]
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			msg := fmt.Sprintf("Command exited with status %d.", exitErr.ExitCode())
+			panic(NewError(keyword, msg))
+		}
+		panic(NewError(keyword, fmt.Sprintf("Command failed: %v.", err)))
+	}
+}
+
+// openRedirect opens the target file of a redirection. Errors are reported
+// at the redirect operator.
+func openRedirect(operator *token.Token, target interface{}) *os.File {
+	name, ok := target.(string)
+	if !ok {
+		panic(NewError(operator, "Redirect target must be a string."))
+	}
+	var file *os.File
+	var err error
+	switch operator.Type {
+	case token.LessThan:
+		file, err = os.Open(name)
+	case token.GreaterThan:
+		file, err = os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	case token.GreaterThanDual:
+		file, err = os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	}
+	if err != nil {
+		if pathErr, ok := err.(*os.PathError); ok {
+			err = pathErr.Err
+		}
+		msg := fmt.Sprintf("Cannot open file '%s': %v.", name, err)
+		panic(NewError(operator, msg))
+	}
+	return file
+}
+
+// GluResult ==================================================================
+//
+
+// GluResult represents the outcome of running an external command.
+type GluResult struct {
+	Stdout   string
+	Stderr   string
+	Code     int64
+	Duration time.Duration
+}
+
+// Get returns the value of the named property of the result.
+func (gr *GluResult) Get(name *token.Token) interface{} {
+	switch name.Lexeme {
+	case "stdout":
+		return gr.Stdout
+	case "stderr":
+		return gr.Stderr
+	case "code":
+		return gr.Code
+	case "ok":
+		return gr.Code == 0
+	case "duration":
+		return gr.Duration.Seconds()
+	}
+	err := fmt.Sprintf("Undefined property '%s'.", name.Lexeme)
+	panic(NewError(name, err))
+}
+
+func (gr *GluResult) String() string {
+	return fmt.Sprintf("<result %d>", gr.Code)
+}
+
+// Command Native Functions ===================================================
+//
+
+// runFn ------------------------------
+//
+type runFn struct{}
+
+func (fn runFn) Arity() int { return Variadic }
+func (fn runFn) Call(interpreter *Interpreter, arguments []interface{}) interface{} {
+	args := commandArguments(nil, arguments)
+	if len(args) == 0 {
+		panic(NewError(nil, "Expected a command to run."))
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := interpreter.Process.Command(args[0], args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	start := time.Now()
+	err := cmd.Run()
+	result := &GluResult{Duration: time.Since(start)}
+	if err != nil {
+		result.Code = exitCode(err, &stderr)
+	}
+	result.Stdout = stdout.String()
+	result.Stderr = stderr.String()
+	return result
+}
+
+// notStartedCode is the exit status of a command that could not be started,
+// e.g. because it was not found. Bash uses the same status.
+const notStartedCode = 127
+
+// exitCode returns the exit status of a command that failed. A command that
+// could not be started fails like one that exited with notStartedCode, with
+// the reason written to its standard error.
+func exitCode(err error, stderr *bytes.Buffer) int64 {
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return int64(exitErr.ExitCode())
+	}
+	fmt.Fprintf(stderr, "%v\n", err)
+	return notStartedCode
+}
+
+// commandArguments converts Glu values into the arguments of an external
+// command. Lists are expanded in place so that an argument list can be built
+// up and passed as a single value.
+func commandArguments(token *token.Token, arguments []interface{}) []string {
+	var args []string
+	for _, argument := range arguments {
+		switch value := argument.(type) {
+		case *GluList:
+			args = append(args, commandArguments(token, value.Elements)...)
+		case nil:
+			panic(NewError(token, "Command arguments must not be nil."))
+		default:
+			args = append(args, stringify(value))
+		}
+	}
+	return args
+}
@@ -0,0 +1,48 @@
+package interpreter
+
+// Completion =================================================================
+//
+
+// CompletionKind is the way a statement completed abruptly.
+type CompletionKind int
+
+const (
+	// Return completes the enclosing function with a value.
+	Return CompletionKind = iota
+	// Break completes the enclosing loop.
+	Break
+	// Continue completes the current iteration of the enclosing loop.
+	Continue
+	// Throw completes the enclosing try statement with an error.
+	Throw
+)
+
+// Completion is the abrupt completion of a statement, returned by the
+// statement in place of a value and propagated by the statements enclosing it
+// until one handles it. A statement that completes normally returns nil.
+type Completion struct {
+	Kind  CompletionKind
+	Value interface{}
+}
+
+var (
+	breakCompletion    = &Completion{Kind: Break}
+	continueCompletion = &Completion{Kind: Continue}
+)
+
+// NewReturn creates a Completion that returns the value.
+func NewReturn(value interface{}) *Completion {
+	return &Completion{Kind: Return, Value: value}
+}
+
+// NewThrow creates a Completion that throws the error.
+func NewThrow(err *Error) *Completion {
+	return &Completion{Kind: Throw, Value: err}
+}
+
+// completion returns the abrupt completion of a statement, or nil if the
+// result is a value.
+func completion(result interface{}) *Completion {
+	c, _ := result.(*Completion)
+	return c
+}
@@ -9,10 +9,13 @@ import (
 // Environment ================================================================
 //
 
-// Environment represents a scopted set of runtime variables.
+// Environment represents a scopted set of runtime variables. Global variables
+// are held by name in Values. Local variables are held in Slots, in the order
+// they were numbered by the resolver.
 type Environment struct {
 	Parent *Environment
 	Values map[string]interface{}
+	Slots  []interface{}
 }
 
 // NewGlobalEnvironment creates a new root map based environment.
@@ -43,6 +46,38 @@ func (env *Environment) Define(name string, value interface{}) {
 	env.Values[name] = value
 }
 
+// Declare adds a new local variable to the next slot of the environment.
+func (env *Environment) Declare(value interface{}) {
+	env.Slots = append(env.Slots, value)
+}
+
+// GetAt retrieves the local variable in the slot of the ancestor environment at
+// the specified depth.
+func (env *Environment) GetAt(name *token.Token, depth int, slot int) interface{} {
+	return env.declared(name, depth, slot).Slots[slot]
+}
+
+// AssignAt assigns a new value to the local variable in the slot of the
+// ancestor environment at the specified depth.
+func (env *Environment) AssignAt(name *token.Token, depth int, slot int, value interface{}) {
+	env.declared(name, depth, slot).Slots[slot] = value
+}
+
+// declared returns the ancestor environment at the specified depth. A local
+// function may be referred to before its declaration is reached, in which
+// case its slot is not yet declared.
+func (env *Environment) declared(name *token.Token, depth int, slot int) *Environment {
+	environment := env
+	for idx := 0; idx < depth; idx++ {
+		environment = environment.Parent
+	}
+	if slot >= len(environment.Slots) {
+		err := fmt.Sprintf("Undefined variable '%s'.", name.Lexeme)
+		panic(NewError(name, err))
+	}
+	return environment
+}
+
 // Get attempts to retrieve the specified environment variable.
 func (env *Environment) Get(name *token.Token) interface{} {
 	if lexeme, ok := env.Values[name.Lexeme]; ok {
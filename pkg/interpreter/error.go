@@ -2,6 +2,7 @@ package interpreter
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/templecloud/glu/pkg/token"
 )
@@ -18,6 +19,10 @@ type error interface {
 type Error struct {
 	token   *token.Token
 	message string
+	// value is the Glu value raised by a 'throw' statement, if any.
+	value interface{}
+	// stack is the Glu call stack the error unwound through, innermost first.
+	stack []*Frame
 }
 
 // NewError create an parse error.
@@ -25,6 +30,116 @@ func NewError(token *token.Token, message string) *Error {
 	return &Error{token: token, message: message}
 }
 
+// NewThrownError creates an error raised by a 'throw' statement.
+func NewThrownError(token *token.Token, value interface{}) *Error {
+	return &Error{token: token, message: stringify(value), value: value}
+}
+
 func (e Error) Error() string {
 	return fmt.Sprintf("{%+v, %s}\n", e.token, e.message)
 }
+
+// Locate sets the position of an error raised without one, e.g. by a native
+// function.
+func (e *Error) Locate(token *token.Token) {
+	if e.token == nil {
+		e.token = token
+	}
+}
+
+// Unwind records a call frame that the error unwound through.
+func (e *Error) Unwind(frame *Frame) {
+	e.stack = append(e.stack, frame)
+}
+
+// Get returns the named property of the error. This allows a caught error to
+// be inspected from Glu.
+func (e *Error) Get(name *token.Token) interface{} {
+	switch name.Lexeme {
+	case "message":
+		return e.message
+	case "value":
+		if e.value == nil {
+			return e.message
+		}
+		return e.value
+	case "origin":
+		return e.token.Source.Origin
+	case "line":
+		return int64(e.token.Source.Line + 1)
+	case "column":
+		return int64(e.token.Source.Column + 1)
+	case "stack":
+		return e.Stack()
+	}
+	err := fmt.Sprintf("Undefined property '%s'.", name.Lexeme)
+	panic(NewError(name, err))
+}
+
+// Stack returns a string representation of the Glu call stack the error
+// unwound through, innermost call first.
+func (e *Error) Stack() string {
+	var builder strings.Builder
+	for idx, frame := range e.stack {
+		builder.WriteString(frame.String())
+		if idx < len(e.stack)-1 {
+			builder.WriteString("\n")
+		}
+	}
+	return builder.String()
+}
+
+// maxTraceRepeats is the number of times a frame is repeated in a trace.
+const maxTraceRepeats = 3
+
+// Trace returns the Glu call stack the error unwound through for display,
+// innermost call first. Runs of identical frames, e.g. from a runaway
+// recursion, are elided after maxTraceRepeats.
+func (e *Error) Trace() string {
+	var lines []string
+	for idx := 0; idx < len(e.stack); {
+		frame := e.stack[idx].String()
+		run := 1
+		for idx+run < len(e.stack) && e.stack[idx+run].String() == frame {
+			run++
+		}
+		shown := run
+		if run > maxTraceRepeats+1 {
+			shown = maxTraceRepeats
+		}
+		for n := 0; n < shown; n++ {
+			lines = append(lines, "    "+frame)
+		}
+		if shown < run {
+			lines = append(lines, fmt.Sprintf("    ... repeated %d more times", run-shown))
+		}
+		idx += run
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Frame ======================================================================
+//
+
+// Frame represents a Glu function call: the name of the function, and the
+// call-site token and the origin of its source.
+type Frame struct {
+	name   string
+	token  *token.Token
+	origin string
+}
+
+// NewFrame creates a Frame for a call to the named function at the specified
+// call-site token.
+func NewFrame(name string, token *token.Token) *Frame {
+	return &Frame{name: name, token: token, origin: token.Source.Origin}
+}
+
+func (f *Frame) String() string {
+	if f.origin != "" {
+		return fmt.Sprintf("at %s (Origin: %s, Line: %d, Column: %d)",
+			f.name, f.origin, f.token.Source.Line+1, f.token.Source.Column+1)
+	}
+	return fmt.Sprintf("at %s (Line: %d, Column: %d)",
+		f.name, f.token.Source.Line+1, f.token.Source.Column+1)
+}
@@ -1,6 +1,8 @@
 package interpreter
 
 import (
+	"fmt"
+	"math"
 	"time"
 
 	"github.com/templecloud/glu/pkg/ast"
@@ -15,13 +17,18 @@ type GluCallable interface {
 	Call(interpreter *Interpreter, arguments []interface{}) interface{}
 }
 
+// Variadic is the Arity of a GluCallable that accepts any number of
+// arguments.
+const Variadic = -1
+
 // GluFunction ================================================================
 //
 
 // GluFn represents a function.
 type GluFn struct {
-	Declaration *ast.FnStmt
-	Closure     *Environment
+	Declaration   *ast.FnStmt
+	Closure       *Environment
+	IsInitializer bool
 }
 
 // NewGluFn represents a function.
@@ -29,6 +36,26 @@ func NewGluFn(declaration *ast.FnStmt, environment *Environment) *GluFn {
 	return &GluFn{Declaration: declaration, Closure: environment}
 }
 
+// NewGluMethod represents a method of a class.
+func NewGluMethod(
+	declaration *ast.FnStmt,
+	environment *Environment,
+	isInitializer bool,
+) *GluFn {
+	return &GluFn{
+		Declaration:   declaration,
+		Closure:       environment,
+		IsInitializer: isInitializer,
+	}
+}
+
+// Bind returns a copy of the method with 'this' bound to the instance.
+func (gf GluFn) Bind(instance *GluInstance) *GluFn {
+	environment := NewChildEnvironment(gf.Closure)
+	environment.Declare(instance)
+	return NewGluMethod(gf.Declaration, environment, gf.IsInitializer)
+}
+
 // Arity returns the number of parameters the function has.
 func (gf GluFn) Arity() int {
 	return len(gf.Declaration.Params)
@@ -38,38 +65,84 @@ func (gf GluFn) Arity() int {
 func (gf GluFn) Call(
 	interpreter *Interpreter,
 	arguments []interface{},
-) (result interface{}) {
+) interface{} {
+	// An attributed function runs its script instead of a Glu body.
+	if gf.Declaration.Script != nil {
+		return runScript(gf.Declaration, arguments, interpreter.Process)
+	}
 	// Define a new function environment and set the parameters.
 	environment := NewChildEnvironment(gf.Closure)
-	for idx, argument := range arguments {
-		environment.Define(gf.Declaration.Params[idx].Lexeme, argument)
+	for _, argument := range arguments {
+		environment.Declare(argument)
+	}
+	// Execute the function block. An error thrown by the body unwinds the
+	// expressions of the caller.
+	c := completion(interpreter.executeBlock(gf.Declaration.Body, environment))
+	if c != nil && c.Kind == Throw {
+		panic(c.Value)
 	}
-	// Set-up a defferred function to handle the dodgy panic based function
-	// return.
-	defer func() {
-		if r := recover(); r != nil {
-			switch res := r.(type) {
-			case *Return:
-				// Dodgy! Catch *Return type structs and return the value.
-				result = res.value
-			case *Error:
-				panic(res)
-			default:
-				panic(res)
-			}
-		}
-	}()
-	// Execute the function block.
-	interpreter.executeBlock(gf.Declaration.Body, environment)
-	return
+	if gf.IsInitializer {
+		return gf.Closure.Slots[0]
+	}
+	if c != nil {
+		return c.Value
+	}
+	return nil
+}
+
+func (gf GluFn) String() string {
+	return fmt.Sprintf("<fn %s>", gf.Declaration.Name.Lexeme)
 }
 
 // Native Functions ===========================================================
 //
 
+// GluNativeFn represents a function implemented by the interpreter and
+// registered under a name.
+type GluNativeFn struct {
+	Name string
+	fn   GluCallable
+}
+
+// Arity returns the number of parameters the function has.
+func (gn *GluNativeFn) Arity() int {
+	return gn.fn.Arity()
+}
+
+// Call / Invoke this GluNativeFn.
+func (gn *GluNativeFn) Call(interpreter *Interpreter, arguments []interface{}) interface{} {
+	return gn.fn.Call(interpreter, arguments)
+}
+
+func (gn *GluNativeFn) String() string {
+	return fmt.Sprintf("<native fn %s>", gn.Name)
+}
+
 func defineNativeFunctions() *Environment {
 	native := NewGlobalEnvironment()
-	native.Define("time", nowFn{})
+	define := func(name string, fn GluCallable) {
+		native.Define(name, &GluNativeFn{Name: name, fn: fn})
+	}
+	define("time", nowFn{})
+	define("len", lenFn{})
+	define("push", pushFn{})
+	define("pop", popFn{})
+	define("keys", keysFn{})
+	define("values", valuesFn{})
+	define("has", hasFn{})
+	define("delete", deleteFn{})
+	native.Define("inf", math.Inf(1))
+	native.Define("nan", math.NaN())
+	define("isinf", isInfFn{})
+	define("isnan", isNaNFn{})
+	define("run", runFn{})
+	define("sh", shFn{})
+	define("spawn", spawnFn{})
+	define("wait", waitFn{})
+	define("kill", killFn{})
+	define("status", statusFn{})
+	define("read", readFn{})
+	define("jobs", jobsFn{})
 	return native
 }
 
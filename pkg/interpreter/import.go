@@ -0,0 +1,108 @@
+package interpreter
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/templecloud/glu/pkg/token"
+)
+
+// Import Functions ===========================================================
+//
+
+// discoverScript lists the functions declared in the shell environment before
+// and after sourcing a library, separated by a '--' line.
+const discoverScript = `declare -F; echo --; source "$1" >/dev/null || exit; declare -F`
+
+// callScript sources a library and calls one of its functions with the
+// remaining arguments.
+const callScript = `source "$1" >/dev/null || exit; shift; "$@"`
+
+// ImportBash sources the bash library at the resolved path and returns a
+// callable for each function it declares. Functions that are already present in the shell
+// environment, e.g. exported by the parent process, are not imported. Errors
+// are reported at the path.
+func ImportBash(path *token.Token, resolved string, env *ProcessEnvironment) []*GluBashFn {
+	if _, err := os.Stat(resolved); err != nil {
+		if pathErr, ok := err.(*os.PathError); ok {
+			err = pathErr.Err
+		}
+		panic(NewError(path, fmt.Sprintf("Cannot import '%s': %v.", path.Lexeme, err)))
+	}
+	var stdout bytes.Buffer
+	cmd := env.Command(shell, "-c", discoverScript, "glu", resolved)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			msg := fmt.Sprintf("Cannot import '%s': exited with status %d.",
+				path.Lexeme, exitErr.ExitCode())
+			panic(NewError(path, msg))
+		}
+		panic(NewError(path, fmt.Sprintf("Cannot import '%s': %v.", path.Lexeme, err)))
+	}
+	// 'declare -F' lists each function as 'declare -f name'.
+	existing := map[string]bool{}
+	sourced := false
+	var fns []*GluBashFn
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		fields := strings.Fields(line)
+		switch {
+		case line == "--":
+			sourced = true
+		case len(fields) != 3 || fields[0] != "declare":
+		case !sourced:
+			existing[fields[2]] = true
+		case !existing[fields[2]]:
+			fns = append(fns, &GluBashFn{Name: fields[2], Path: resolved})
+		}
+	}
+	return fns
+}
+
+// GluBashFn ==================================================================
+//
+
+// GluBashFn represents a function imported from a bash library.
+type GluBashFn struct {
+	Name string
+	Path string
+}
+
+// Arity returns the number of parameters the function has. Bash functions
+// accept any number of arguments.
+func (fn *GluBashFn) Arity() int { return Variadic }
+
+// Call / Invoke this GluBashFn. The library is sourced in a new shell and the
+// function run with the arguments as its positional parameters. The result
+// holds its output and exit status.
+func (fn *GluBashFn) Call(interpreter *Interpreter, arguments []interface{}) interface{} {
+	args := append([]string{"-c", callScript, "glu", fn.Path, fn.Name},
+		commandArguments(nil, arguments)...)
+	var stdout, stderr bytes.Buffer
+	cmd := interpreter.Process.Command(shell, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	start := time.Now()
+	err := cmd.Run()
+	result := &GluResult{Duration: time.Since(start)}
+	if err != nil {
+		exitErr, ok := err.(*exec.ExitError)
+		if !ok {
+			panic(NewError(nil, fmt.Sprintf("Command failed: %v.", err)))
+		}
+		result.Code = int64(exitErr.ExitCode())
+	}
+	result.Stdout = stdout.String()
+	result.Stderr = stderr.String()
+	return result
+}
+
+func (fn *GluBashFn) String() string {
+	return fmt.Sprintf("<bash fn %s>", fn.Name)
+}
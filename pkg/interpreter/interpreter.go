@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/templecloud/glu/pkg/ast"
+	"github.com/templecloud/glu/pkg/resolver"
 	"github.com/templecloud/glu/pkg/token"
 )
 
@@ -16,14 +17,50 @@ import (
 type Interpreter struct {
 	Globals *Environment
 	*Environment
+	Process *ProcessEnvironment
+	Jobs    *JobTable
+	// Module is the module whose statements are being evaluated.
+	Module *GluModule
+	// natives is the environment enclosing the globals of every module.
+	natives *Environment
+	// modules caches the imported modules by absolute path.
+	modules map[string]*GluModule
+	// loading is the chain of modules being imported.
+	loading []*GluModule
+	// locals holds the positions of the local variables found by the resolver.
+	locals map[ast.Expr]resolver.Local
+	// frames is the Glu call stack, innermost call last.
+	frames []Frame
+	// MaxDepth is the maximum depth of the call stack.
+	MaxDepth int
 }
 
+// DefaultMaxDepth is the default maximum depth of the call stack. It is well
+// within the depth at which the Go stack of the interpreter overflows.
+const DefaultMaxDepth = 10000
+
 // New creates a Interpeter.
 func New() *Interpreter {
-	globals := defineNativeFunctions()
+	natives := defineNativeFunctions()
+	globals := NewChildEnvironment(natives)
 	return &Interpreter{
 		Environment: globals,
 		Globals:     globals,
+		Process:     NewProcessEnvironment(),
+		Jobs:        NewJobTable(),
+		Module:      NewGluModule("", globals),
+		natives:     natives,
+		modules:     map[string]*GluModule{},
+		locals:      map[ast.Expr]resolver.Local{},
+		MaxDepth:    DefaultMaxDepth,
+	}
+}
+
+// Resolve records the positions of the local variables found by a resolver.
+// Variables that are not resolved are looked up by name.
+func (i *Interpreter) Resolve(locals map[ast.Expr]resolver.Local) {
+	for expr, local := range locals {
+		i.locals[expr] = local
 	}
 }
 
@@ -43,6 +80,12 @@ func (i *Interpreter) Eval(stmt ast.Stmt) (result interface{}, err *Error) {
 		}
 	}()
 	result = i.evaluate(stmt)
+	if c := completion(result); c != nil {
+		result = c.Value
+		if c.Kind == Throw {
+			result, err = nil, c.Value.(*Error)
+		}
+	}
 	return
 }
 
@@ -63,51 +106,22 @@ func (i *Interpreter) evaluate(stmt ast.Stmt) interface{} {
 // expressions.
 func (i *Interpreter) VisitAssignExpr(expr *ast.Assign) interface{} {
 	value := i.evaluate(expr.Value)
-	i.Environment.Assign(expr.Name, value)
+	if local, ok := i.locals[expr]; ok {
+		i.Environment.AssignAt(expr.Name, local.Depth, local.Slot, value)
+	} else {
+		i.Environment.Assign(expr.Name, value)
+	}
 	return value
 }
 
 // VisitBinaryExpr evaluates the node.
 func (i *Interpreter) VisitBinaryExpr(expr *ast.Binary) interface{} {
+	if expr.Operator.Type == token.Pipe {
+		return i.pipe(expr)
+	}
 	left := i.evaluate(expr.Left)
 	right := i.evaluate(expr.Right)
-
-	switch expr.Operator.Type {
-	// Compators
-	case token.GreaterThan:
-		checkNumberOperands(expr.Operator, left, right)
-		return left.(float64) > right.(float64)
-	case token.GreaterThanOrEqual:
-		checkNumberOperands(expr.Operator, left, right)
-		return left.(float64) >= right.(float64)
-	case token.LessThan:
-		checkNumberOperands(expr.Operator, left, right)
-		return left.(float64) < right.(float64)
-	case token.LessThanOrEqual:
-		checkNumberOperands(expr.Operator, left, right)
-		return left.(float64) <= right.(float64)
-	// Equality
-	case token.NotEqual:
-		return !isEqual(left, right)
-	case token.EqualEqual:
-		return isEqual(left, right)
-	// Arithmetic
-	case token.Plus:
-		// TODO: Handle Strings
-		checkNumberOperands(expr.Operator, left, right)
-		return left.(float64) + right.(float64)
-	case token.Minus:
-		checkNumberOperands(expr.Operator, left, right)
-		return left.(float64) - right.(float64)
-	case token.ForwardSlash:
-		checkNumberOperands(expr.Operator, left, right)
-		return left.(float64) / right.(float64)
-	case token.Star:
-		checkNumberOperands(expr.Operator, left, right)
-		return left.(float64) * right.(float64)
-	}
-	// Unreachable.
-	return nil
+	return Binary(expr.Operator, left, right)
 }
 
 // VisitCallExpr evaluates the node.
@@ -118,17 +132,56 @@ func (i *Interpreter) VisitCallExpr(expr *ast.Call) interface{} {
 		arguments = append(arguments, i.evaluate(argument))
 	}
 
-	fn, ok := callee.(GluCallable)
-	if !ok {
-		panic(NewError(expr.Paren, "Can only call functions."))
+	fn := CheckCall(expr.Paren, callee, len(arguments))
+	CheckDepth(expr.Paren, len(i.frames), i.MaxDepth)
+	i.frames = append(i.frames, Frame{name: CallableName(fn), token: expr.Paren, origin: expr.Paren.Source.Origin})
+
+	// Record the call in the Glu stack of any error unwinding through it.
+	defer func() {
+		if r := recover(); r != nil {
+			if err, ok := r.(*Error); ok {
+				// Native functions raise errors without a position.
+				err.Locate(expr.Paren)
+				frame := i.frames[len(i.frames)-1]
+				err.Unwind(&frame)
+			}
+			i.frames = i.frames[:len(i.frames)-1]
+			panic(r)
+		}
+		i.frames = i.frames[:len(i.frames)-1]
+	}()
+	return fn.Call(i, arguments)
+}
+
+// VisitCommandExpr evaluates the node by running the command in the shell to
+// return its standard output.
+func (i *Interpreter) VisitCommandExpr(expr *ast.Command) interface{} {
+	return RunCommand(expr.Token, i.Process)
+}
+
+// VisitConcatExpr evaluates the node to return the concatenation of the
+// string value of its parts.
+func (i *Interpreter) VisitConcatExpr(expr *ast.Concat) interface{} {
+	parts := make([]interface{}, 0, len(expr.Parts))
+	for _, part := range expr.Parts {
+		parts = append(parts, i.evaluate(part))
 	}
+	return Concat(parts)
+}
 
-	if len(arguments) != fn.Arity() {
-		msg := fmt.Sprintf("Expected %d arguments, but, got %d.", fn.Arity(), len(arguments))
-		panic(NewError(expr.Paren, msg))
+// VisitEnvVarExpr evaluates the node to return the value of the process
+// environment variable, or nil if it is not set.
+func (i *Interpreter) VisitEnvVarExpr(expr *ast.EnvVar) interface{} {
+	if value, ok := i.Process.Get(expr.Name.Lexeme); ok {
+		return value
 	}
+	return nil
+}
 
-	return fn.Call(i, arguments)
+// VisitGetExpr evaluates the node.
+func (i *Interpreter) VisitGetExpr(expr *ast.Get) interface{} {
+	object := i.evaluate(expr.Object)
+	return Property(expr.Name, object)
 }
 
 // VisitGroupingExpr evaluates the node.
@@ -136,18 +189,33 @@ func (i *Interpreter) VisitGroupingExpr(expr *ast.Grouping) interface{} {
 	return i.evaluate(expr.Expr)
 }
 
+// VisitIndexExpr evaluates the node.
+func (i *Interpreter) VisitIndexExpr(expr *ast.Index) interface{} {
+	object := i.evaluate(expr.Object)
+	index := i.evaluate(expr.Index)
+	return Index(expr.Bracket, object, index)
+}
+
+// VisitLambdaExpr evaluates the node to create a closure over the current
+// environment.
+func (i *Interpreter) VisitLambdaExpr(expr *ast.Lambda) interface{} {
+	return NewGluFn(expr.Fn, i.Environment)
+}
+
+// VisitListExpr evaluates the node.
+func (i *Interpreter) VisitListExpr(expr *ast.List) interface{} {
+	elements := make([]interface{}, 0, len(expr.Elements))
+	for _, element := range expr.Elements {
+		elements = append(elements, i.evaluate(element))
+	}
+	return NewGluList(elements)
+}
+
 // VisitLiteralExpr evaluates the node and terminates recursion to return
 // a literal value.
 func (i *Interpreter) VisitLiteralExpr(expr *ast.Literal) interface{} {
 	if expr.Value == nil {
-		return "nil"
-	}
-	if expr.TokenType == token.Number {
-		number, err := strconv.ParseFloat(expr.Value.(string), 32)
-		if err != nil {
-			return expr.Value
-		}
-		return number
+		return nil
 	}
 	return expr.Value
 }
@@ -167,54 +235,125 @@ func (i *Interpreter) VisitLogicalExpr(expr *ast.Logical) interface{} {
 	return i.evaluate(expr.Right)
 }
 
-// VisitReturnExpr evaluates the node.
+// VisitMapExpr evaluates the node.
+func (i *Interpreter) VisitMapExpr(expr *ast.Map) interface{} {
+	m := NewGluMap()
+	for idx, key := range expr.Keys {
+		m.Set(expr.Brace, i.evaluate(key), i.evaluate(expr.Values[idx]))
+	}
+	return m
+}
+
+// VisitNumeralExpr evaluates the node and terminates recursion to return an
+// integer or float value.
+func (i *Interpreter) VisitNumeralExpr(expr *ast.Numeral) interface{} {
+	return expr.Value
+}
+
+// VisitReturnExpr evaluates the node to complete the enclosing function.
 func (i *Interpreter) VisitReturnExpr(expr *ast.Return) interface{} {
 	var value interface{}
 	if expr.Value != nil {
 		value = i.evaluate(expr.Value)
 	}
-	// Dodgy as hell but WTF! This fake panic is return the result.
-	panic(NewReturn(value))
+	return NewReturn(value)
+}
+
+// VisitSetExpr evaluates the node.
+func (i *Interpreter) VisitSetExpr(expr *ast.Set) interface{} {
+	object := i.evaluate(expr.Object)
+	instance, ok := object.(*GluInstance)
+	if !ok {
+		panic(NewError(expr.Name, "Only instances have fields."))
+	}
+	value := i.evaluate(expr.Value)
+	instance.Set(expr.Name, value)
+	return value
+}
+
+// VisitSetEnvExpr evaluates the node. The value is converted to a string and
+// passed to all commands subsequently run; nil unsets the variable.
+func (i *Interpreter) VisitSetEnvExpr(expr *ast.SetEnv) interface{} {
+	value := i.evaluate(expr.Value)
+	return SetEnv(expr.Name, value, i.Process)
+}
+
+// VisitSetIndexExpr evaluates the node.
+func (i *Interpreter) VisitSetIndexExpr(expr *ast.SetIndex) interface{} {
+	object := i.evaluate(expr.Object)
+	index := i.evaluate(expr.Index)
+	value := i.evaluate(expr.Value)
+	SetIndex(expr.Bracket, object, index, value)
+	return value
+}
+
+// VisitSliceExpr evaluates the node.
+func (i *Interpreter) VisitSliceExpr(expr *ast.Slice) interface{} {
+	object := i.evaluate(expr.Object)
+	var start, end interface{}
+	if expr.Start != nil {
+		start = i.evaluate(expr.Start)
+	}
+	if expr.End != nil {
+		end = i.evaluate(expr.End)
+	}
+	return Slice(expr.Bracket, object, start, end)
+}
+
+// VisitThisExpr evaluates the node.
+func (i *Interpreter) VisitThisExpr(expr *ast.This) interface{} {
+	return i.lookUp(expr, expr.Keyword)
 }
 
 // VisitUnaryExpr evaluates the node.
 func (i *Interpreter) VisitUnaryExpr(expr *ast.Unary) interface{} {
 	right := i.evaluate(expr.Right)
-	switch expr.Operator.Type {
-	case token.Not:
-		return !isTruthy(right)
-	case token.Minus:
-		checkNumberOperand(expr.Operator, right)
-		return -right.(float64)
-	}
-	return nil
+	return Unary(expr.Operator, right)
 }
 
 // VisitVarExpr evaluates the node.
 func (i *Interpreter) VisitVarExpr(ve *ast.VarExpr) interface{} {
-	return i.Environment.Get(ve.Name)
+	return i.lookUp(ve, ve.Name)
+}
+
+// lookUp returns the value of a variable by its resolved position, or by name
+// if it was not resolved.
+func (i *Interpreter) lookUp(expr ast.Expr, name *token.Token) interface{} {
+	if local, ok := i.locals[expr]; ok {
+		return i.Environment.GetAt(name, local.Depth, local.Slot)
+	}
+	return i.Environment.Get(name)
 }
 
 // Expr Runtime Error Functions ===============================================
 //
 
 func checkNumberOperand(operator *token.Token, operand interface{}) {
-	switch operand.(type) {
-	case float64:
-		return
-	default:
+	if !isNumber(operand) {
 		panic(NewError(operator, "Operand must be a number."))
 	}
 }
 
 func checkNumberOperands(
 	operator *token.Token, leftOperand, rightOperand interface{}) {
-	if _, ok := leftOperand.(float64); ok {
-		if _, ok := rightOperand.(float64); ok {
-			return
-		}
+	if !isNumber(leftOperand) || !isNumber(rightOperand) {
+		panic(NewError(operator, "Operands must both be numbers."))
+	}
+}
+
+func checkIntegerOperand(operator *token.Token, operand interface{}) {
+	if _, ok := operand.(int64); !ok {
+		panic(NewError(operator, "Operand must be an integer."))
+	}
+}
+
+func checkIntegerOperands(
+	operator *token.Token, leftOperand, rightOperand interface{}) {
+	_, lok := leftOperand.(int64)
+	_, rok := rightOperand.(int64)
+	if !lok || !rok {
+		panic(NewError(operator, "Operands must both be integers."))
 	}
-	panic(NewError(operator, "Operands must both be numbers."))
 }
 
 // Stmt Functions =============================================================
@@ -222,7 +361,41 @@ func checkNumberOperands(
 
 // VisitBlockStmt evaluates the node.
 func (i *Interpreter) VisitBlockStmt(stmt *ast.BlockStmt) interface{} {
-	i.executeBlock(stmt.Stmts, NewChildEnvironment(i.Environment))
+	return i.executeBlock(stmt.Stmts, NewChildEnvironment(i.Environment))
+}
+
+// VisitBreakStmt evaluates the node.
+func (i *Interpreter) VisitBreakStmt(stmt *ast.BreakStmt) interface{} {
+	return breakCompletion
+}
+
+// VisitClassStmt evaluates the node.
+func (i *Interpreter) VisitClassStmt(stmt *ast.ClassStmt) interface{} {
+	methods := make(map[string]*GluFn)
+	for _, method := range stmt.Methods {
+		isInitializer := method.Name.Lexeme == initializer
+		methods[method.Name.Lexeme] = NewGluMethod(method, i.Environment, isInitializer)
+	}
+	class := NewGluClass(stmt.Name.Lexeme, stmt.Fields, methods, i.Environment)
+	i.define(stmt.Name.Lexeme, class)
+	return nil
+}
+
+// VisitContinueStmt evaluates the node.
+func (i *Interpreter) VisitContinueStmt(stmt *ast.ContinueStmt) interface{} {
+	return continueCompletion
+}
+
+// VisitExecStmt evaluates the node by running the external program.
+func (i *Interpreter) VisitExecStmt(stmt *ast.ExecStmt) interface{} {
+	i.execute(stmt)
+	return nil
+}
+
+// VisitExportStmt evaluates the node.
+func (i *Interpreter) VisitExportStmt(stmt *ast.ExportStmt) interface{} {
+	i.evaluate(stmt.Declaration)
+	i.Module.Export(DeclarationName(stmt.Declaration))
 	return nil
 }
 
@@ -234,16 +407,28 @@ func (i *Interpreter) VisitExprStmt(stmt *ast.ExprStmt) interface{} {
 // VisitFnStmt evaluates the node.
 func (i *Interpreter) VisitFnStmt(fn *ast.FnStmt) interface{} {
 	function := NewGluFn(fn, i.Environment)
-	i.Environment.Define(fn.Name.Lexeme, function)
+	i.define(fn.Name.Lexeme, function)
+	return nil
+}
+
+// VisitImportStmt evaluates the node.
+func (i *Interpreter) VisitImportStmt(stmt *ast.ImportStmt) interface{} {
+	if stmt.Kind == nil {
+		i.define(stmt.Alias.Lexeme, i.importModule(stmt.Path))
+		return nil
+	}
+	for _, fn := range ImportBash(stmt.Path, i.ResolveImport(stmt.Path.Lexeme), i.Process) {
+		i.Environment.Define(fn.Name, fn)
+	}
 	return nil
 }
 
 // VisitIfStmt evaluates the node.
 func (i *Interpreter) VisitIfStmt(stmt *ast.IfStmt) interface{} {
 	if isTruthy(i.evaluate(stmt.Condition)) {
-		i.evaluate(stmt.ThenBranch)
+		return i.executeStmt(stmt.ThenBranch)
 	} else if stmt.ElseBranch != nil {
-		i.evaluate(stmt.ElseBranch)
+		return i.executeStmt(stmt.ElseBranch)
 	}
 	return nil
 }
@@ -255,33 +440,116 @@ func (i *Interpreter) VisitLogStmt(stmt *ast.LogStmt) interface{} {
 	return nil
 }
 
+// VisitThrowStmt evaluates the node.
+func (i *Interpreter) VisitThrowStmt(stmt *ast.ThrowStmt) interface{} {
+	value := i.evaluate(stmt.Value)
+	// Re-throw caught errors as they are to preserve their position and stack.
+	if err, ok := value.(*Error); ok {
+		return NewThrow(err)
+	}
+	return NewThrow(NewThrownError(stmt.Keyword, value))
+}
+
+// VisitTryStmt evaluates the node. The finally block runs however the try and
+// catch blocks complete, and its own abrupt completion takes precedence.
+func (i *Interpreter) VisitTryStmt(stmt *ast.TryStmt) interface{} {
+	result := i.attempt(stmt.Body, NewChildEnvironment(i.Environment))
+	if c := completion(result); c != nil && c.Kind == Throw && stmt.CatchName != nil {
+		environment := NewChildEnvironment(i.Environment)
+		environment.Declare(c.Value)
+		result = i.attempt(stmt.Catch, environment)
+	}
+	if stmt.Finally != nil {
+		if c := i.executeBlock(stmt.Finally, NewChildEnvironment(i.Environment)); c != nil {
+			return c
+		}
+	}
+	return result
+}
+
 // VisitVariableStmt evaluates the node. See also VisitVarExpr.
 func (i *Interpreter) VisitVariableStmt(stmt *ast.VariableStmt) interface{} {
 	var value interface{}
 	if stmt.Initialiser != nil {
 		value = i.evaluate(stmt.Initialiser)
 	}
-	i.Environment.Define(stmt.Name.Lexeme, value)
+	i.define(stmt.Name.Lexeme, value)
 	return nil
 }
 
 // VisitWhileStmt evaluates the node. See also VisitVarExpr.
 func (i *Interpreter) VisitWhileStmt(stmt *ast.WhileStmt) interface{} {
 	for isTruthy(i.evaluate(stmt.Condition)) {
-		i.evaluate(stmt.Body)
+		if c := completion(i.evaluate(stmt.Body)); c != nil && c.Kind != Continue {
+			if c.Kind == Break {
+				break
+			}
+			return c
+		}
+		if stmt.Increment != nil {
+			i.evaluate(stmt.Increment)
+		}
 	}
 	return nil
 }
 
-func (i *Interpreter) executeBlock(stmts []ast.Stmt, newEnvironment *Environment) {
+// VisitWithStmt evaluates the node. The overrides are applied to the process
+// environment for the duration of the body and then restored, even if the
+// body exits early.
+func (i *Interpreter) VisitWithStmt(stmt *ast.WithStmt) interface{} {
+	restore := Override(stmt.Keyword, i.evaluate(stmt.Env), i.Process)
+	defer restore()
+	return i.executeBlock(stmt.Body, NewChildEnvironment(i.Environment))
+}
+
+// define declares a variable in the current environment. The global variables
+// of a module are defined by name, and local variables in the next slot.
+func (i *Interpreter) define(name string, value interface{}) {
+	if i.Environment == i.Module.env {
+		i.Environment.Define(name, value)
+	} else {
+		i.Environment.Declare(value)
+	}
+}
+
+// executeBlock evaluates the statements in the environment and returns the
+// first abrupt completion, or nil if they all complete normally.
+func (i *Interpreter) executeBlock(stmts []ast.Stmt, newEnvironment *Environment) interface{} {
 	previous := i.Environment
 	defer func() {
 		i.Environment = previous
 	}()
 	for _, stmt := range stmts {
 		i.Environment = newEnvironment
-		i.evaluate(stmt)
+		if c := completion(i.evaluate(stmt)); c != nil {
+			return c
+		}
+	}
+	return nil
+}
+
+// attempt is executeBlock, except an error raised by the statements is
+// returned as a 'throw' completion.
+func (i *Interpreter) attempt(stmts []ast.Stmt, newEnvironment *Environment) (result interface{}) {
+	defer func() {
+		if r := recover(); r != nil {
+			err, ok := r.(*Error)
+			if !ok {
+				panic(r)
+			}
+			result = NewThrow(err)
+		}
+	}()
+	return i.executeBlock(stmts, newEnvironment)
+}
+
+// executeStmt evaluates the statement and returns its abrupt completion, or nil
+// if it completes normally.
+func (i *Interpreter) executeStmt(stmt ast.Stmt) interface{} {
+	if c := completion(i.evaluate(stmt)); c != nil {
+		return c
 	}
+	return nil
 }
 
 // Support Functions ==========================================================
@@ -302,12 +570,67 @@ func isTruthy(thing interface{}) bool {
 
 // isEqual defines the 'identity' semantics for Glu.
 func isEqual(t1 interface{}, t2 interface{}) bool {
+	return equal(t1, t2, nil)
+}
+
+// comparison is a pair of collections being compared.
+type comparison struct {
+	left, right interface{}
+}
+
+// equal compares two values. The pairs of collections being compared are
+// visiting, so collections that contain themselves compare equal rather than
+// recursing forever.
+func equal(t1 interface{}, t2 interface{}, visiting map[comparison]bool) bool {
 	if t1 == nil && t2 == nil {
 		return true
 	}
 	if t1 == nil {
 		return false
 	}
+	// Numbers are compared by value, regardless of type.
+	if isNumber(t1) && isNumber(t2) {
+		return isNumberEqual(t1, t2)
+	}
+	// Collections are compared by value.
+	switch c1 := t1.(type) {
+	case *GluList:
+		c2, ok := t2.(*GluList)
+		if !ok || len(c1.Elements) != len(c2.Elements) {
+			return false
+		}
+		if c1 == c2 || visiting[comparison{c1, c2}] {
+			return true
+		}
+		if visiting == nil {
+			visiting = map[comparison]bool{}
+		}
+		visiting[comparison{c1, c2}] = true
+		for idx := range c1.Elements {
+			if !equal(c1.Elements[idx], c2.Elements[idx], visiting) {
+				return false
+			}
+		}
+		return true
+	case *GluMap:
+		c2, ok := t2.(*GluMap)
+		if !ok || c1.Len() != c2.Len() {
+			return false
+		}
+		if c1 == c2 || visiting[comparison{c1, c2}] {
+			return true
+		}
+		if visiting == nil {
+			visiting = map[comparison]bool{}
+		}
+		visiting[comparison{c1, c2}] = true
+		for _, key := range c1.keys {
+			if !c2.Has(key) || !equal(c1.values[key], c2.values[key], visiting) {
+				return false
+			}
+		}
+		return true
+	}
 	return t1 == t2
 }
 
@@ -316,10 +639,14 @@ func stringify(value interface{}) string {
 		return "nil"
 	}
 	switch value.(type) {
+	case int64:
+		return strconv.FormatInt(value.(int64), 10)
 	case float64:
-		return fmt.Sprintf("%v", value.(float64))
+		return formatFloat(value.(float64))
 	case string:
 		return value.(string)
+	case *Error:
+		return value.(*Error).message
 	default:
 		return fmt.Sprintf("%v", value)
 	}
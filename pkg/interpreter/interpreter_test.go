@@ -2,11 +2,13 @@ package interpreter
 
 import (
 	"fmt"
+	"math"
 	"reflect"
 	"testing"
 
 	"github.com/templecloud/glu/pkg/lexer"
 	"github.com/templecloud/glu/pkg/parser"
+	"github.com/templecloud/glu/pkg/resolver"
 )
 
 func TestEvaluate_ExprStmt(t *testing.T) {
@@ -15,30 +17,61 @@ func TestEvaluate_ExprStmt(t *testing.T) {
 		expectedValue interface{}
 		expectedType  string
 	}{
-		{"123;", float64(123), "float64"},
-		{"-123;", float64(-123), "float64"},
+		{"123;", int64(123), "int64"},
+		{"-123;", int64(-123), "int64"},
 		{"-123.5;", float64(-123.5), "float64"},
 		{"123.5;", float64(123.5), "float64"},
-		// TODO
-		// {"123.45", float64(123.45)},
-		{"1 + 1;", float64(2), "float64"},
-		{"1 + 1 + 1;", float64(3), "float64"},
+		{"123.45;", float64(123.45), "float64"},
+		{"1 + 1;", int64(2), "int64"},
+		{"1 + 1 + 1;", int64(3), "int64"},
 		{"2.5 + 2.5;", float64(5), "float64"},
 		{"2.5 + 3.5 + 4.0;", float64(10), "float64"},
+		{"1 + 1.5;", float64(2.5), "float64"},
+		{"1.5 + 1;", float64(2.5), "float64"},
 
-		{"1 - 1;", float64(0), "float64"},
-		{"1 - 2;", float64(-1), "float64"},
+		{"1 - 1;", int64(0), "int64"},
+		{"1 - 2;", int64(-1), "int64"},
+		{"1 - 0.5;", float64(0.5), "float64"},
 
-		{"2 * 0;", float64(0), "float64"},
-		{"2 * 1;", float64(2), "float64"},
-		{"2 * 2;", float64(4), "float64"},
-		{"2 * -2;", float64(-4), "float64"},
+		{"2 * 0;", int64(0), "int64"},
+		{"2 * 1;", int64(2), "int64"},
+		{"2 * 2;", int64(4), "int64"},
+		{"2 * -2;", int64(-4), "int64"},
+		{"2 * 1.5;", float64(3), "float64"},
 
 		{"2 / 2;", float64(1), "float64"},
 		{"2 / 1;", float64(2), "float64"},
 		{"2 / (1 / 2);", float64(4), "float64"},
-		// TODO: +Inf
-		// {"2 / 0", float64(4), "float64"},
+		{"2.0 / 0;", math.Inf(1), "float64"},
+		{"-2 / 0.0;", math.Inf(-1), "float64"},
+
+		{"7 % 3;", int64(1), "int64"},
+		{"-7 % 3;", int64(2), "int64"},
+		{"7 % -3;", int64(-2), "int64"},
+		{"-7 % -3;", int64(-1), "int64"},
+		{"-6 % 3;", int64(0), "int64"},
+		{"7.5 % 2;", float64(1.5), "float64"},
+		{"-7.5 % 2;", float64(0.5), "float64"},
+		{"7.5 % -2;", float64(-0.5), "float64"},
+		{"-7 // 2 * 2 + -7 % 2;", int64(-7), "int64"},
+		{"7 // -2 * -2 + 7 % -2;", int64(7), "int64"},
+		{"-7.5 // 2 * 2 + -7.5 % 2;", float64(-7.5), "float64"},
+		{"7 // 2;", int64(3), "int64"},
+		{"-7 // 2;", int64(-4), "int64"},
+		{"7.5 // 2;", float64(3), "float64"},
+		{"2 ** 10;", int64(1024), "int64"},
+		{"2 ** 3 ** 2;", int64(512), "int64"},
+		{"-2 ** 2;", int64(-4), "int64"},
+		{"2 ** -1;", float64(0.5), "float64"},
+		{"4 ** 0.5;", float64(2), "float64"},
+
+		{"6 & 3;", int64(2), "int64"},
+		{"6 | 3;", int64(7), "int64"},
+		{"6 ^ 3;", int64(5), "int64"},
+		{"~0;", int64(-1), "int64"},
+		{"1 << 4;", int64(16), "int64"},
+		{"-16 >> 2;", int64(-4), "int64"},
+		{"493 & 7;", int64(5), "int64"},
 
 		{" 1 == 1;", true, "bool"},
 		{" 1 == 2;", false, "bool"},
@@ -50,6 +83,10 @@ func TestEvaluate_ExprStmt(t *testing.T) {
 		{" 1 < 2;", true, "bool"},
 		{" 1 < 1;", false, "bool"},
 		{" 1 < 2;", true, "bool"},
+		{" 1 == 1.0;", true, "bool"},
+		{" 1 != 1.5;", true, "bool"},
+		{" 2 > 1.5;", true, "bool"},
+		{" 1.5 < 1;", false, "bool"},
 	}
 	for idx, tt := range tests {
 		l := lexer.New(tt.input)
@@ -62,6 +99,8 @@ func TestEvaluate_ExprStmt(t *testing.T) {
 		var actualValue interface{}
 		actualType := reflect.TypeOf(actual)
 		switch actual.(type) {
+		case int64:
+			actualValue = actual.(int64)
 		case float64:
 			actualValue = actual.(float64)
 		case bool:
@@ -79,13 +118,68 @@ func TestEvaluate_ExprStmt(t *testing.T) {
 	}
 }
 
+func TestEvaluate_StringExpr(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedValue interface{}
+	}{
+		{"\"a\" + \"b\";", "ab"},
+		{"\"a\" + \"b\" + \"c\";", "abc"},
+		{"\"n=\" + 1;", "n=1"},
+		{"\"n=\" + 1.5;", "n=1.5"},
+		{"1 + \"a\";", "1a"},
+		{"1 + 2 + \"a\";", "3a"},
+		{"\"a\" + 1 + 2;", "a12"},
+		{"\"ab\" * 3;", "ababab"},
+		{"3 * \"ab\";", "ababab"},
+		{"\"ab\" * 0;", ""},
+		{"\"a\" < \"b\";", true},
+		{"\"b\" < \"a\";", false},
+		{"\"ab\" < \"b\";", true},
+		{"\"a\" <= \"a\";", true},
+		{"\"b\" > \"a\";", true},
+		{"\"a\" >= \"b\";", false},
+		{"\"a\" == \"a\";", true},
+		{"\"a\" != \"b\";", true},
+	}
+	for idx, tt := range tests {
+		l := lexer.New(tt.input)
+		tokens, _ := l.ScanTokens()
+		p := parser.New(tokens)
+		stmts := p.Parse()
+		actualValue, evalErr := New().Eval(stmts[0])
+		if evalErr != nil {
+			t.Fatalf("test[%d] - Input=%s, Unexpected error=%v", idx, tt.input, evalErr)
+		}
+		if tt.expectedValue != actualValue {
+			t.Fatalf("test[%d] - Input=%s, ExpectedValue=%v, ActualValue=%v", idx, tt.input, tt.expectedValue, actualValue)
+		}
+	}
+}
+
 func TestEvaluateError_ExprStmt(t *testing.T) {
 	tests := []struct {
 		input    string
 		expected string
 	}{
 		{"- \"test\";", "Operand must be a number."},
-		{"1 + \"test\";", "Operands must both be numbers."},
+		{"1 + true;", "Operands must both be numbers."},
+		{"\"test\" + true;", "Operands must be strings or numbers."},
+		{"\"test\" + time();", "Operands must be strings or numbers."},
+		{"\"a\" < 1;", "Operands must both be numbers or both be strings."},
+		{"1 >= \"a\";", "Operands must both be numbers or both be strings."},
+		{"\"a\" * \"b\";", "Operands must be a string and a number."},
+		{"\"a\" * -1;", "String repeat count must be a non-negative integer."},
+		{"\"a\" * 1.5;", "String repeat count must be a non-negative integer."},
+		{"\"a\" - \"b\";", "Operands must both be numbers."},
+		{"\"a\" % 2;", "Operands must both be numbers."},
+		{"2 / 0;", "Division by zero."},
+		{"1 // 0;", "Division by zero."},
+		{"1 % 0;", "Division by zero."},
+		{"1.5 & 1;", "Operands must both be integers."},
+		{"1 | true;", "Operands must both be integers."},
+		{"~1.5;", "Operand must be an integer."},
+		{"1 << -1;", "Shift count must be non-negative."},
 	}
 	for idx, tt := range tests {
 		l := lexer.New(tt.input)
@@ -214,3 +308,21 @@ func TestEvaluate_LogicalExpr(t *testing.T) {
 		}
 	}
 }
+
+func BenchmarkEvaluate_RecursiveCall(b *testing.B) {
+	tokens, _ := lexer.New(
+		"func fib(n) { if (n < 2) return n; return fib(n - 1) + fib(n - 2); } fib(20);",
+	).ScanTokens()
+	stmts := parser.New(tokens).Parse()
+	r := resolver.New()
+	r.Resolve(stmts)
+	i := New()
+	i.Resolve(r.Locals)
+	i.Eval(stmts[0])
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if _, err := i.Eval(stmts[1]); err != nil {
+			b.Fatalf("Unexpected error: %v", err)
+		}
+	}
+}
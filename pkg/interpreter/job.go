@@ -0,0 +1,328 @@
+package interpreter
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/templecloud/glu/pkg/token"
+)
+
+// GluJob =====================================================================
+//
+
+// GluJob represents an external process running in the background. Its
+// output is captured as it is written.
+type GluJob struct {
+	ID      int64
+	Command string
+	cmd     *exec.Cmd
+	start   time.Time
+	// guarded by mutex; cond is signalled on output and on exit.
+	mutex    sync.Mutex
+	cond     *sync.Cond
+	stdout   bytes.Buffer
+	stderr   bytes.Buffer
+	read     int
+	done     bool
+	code     int64
+	signaled bool
+	duration time.Duration
+}
+
+// startJob starts the command in the background and returns its job. The job
+// reads no input. A command that cannot be started is a job that has already
+// failed.
+func startJob(id int64, cmd *exec.Cmd) *GluJob {
+	job := &GluJob{ID: id, Command: strings.Join(cmd.Args, " "), cmd: cmd}
+	job.cond = sync.NewCond(&job.mutex)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		panic(NewError(nil, fmt.Sprintf("Command failed: %v.", err)))
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		panic(NewError(nil, fmt.Sprintf("Command failed: %v.", err)))
+	}
+	job.start = time.Now()
+	if err := cmd.Start(); err != nil {
+		job.code = exitCode(err, &job.stderr)
+		job.done = true
+		return job
+	}
+	var copying sync.WaitGroup
+	copying.Add(2)
+	go job.capture(stdout, &job.stdout, &copying)
+	go job.capture(stderr, &job.stderr, &copying)
+	go job.wait(&copying)
+	return job
+}
+
+// capture appends the output read from a pipe of the job to a buffer until
+// the pipe is closed.
+func (gj *GluJob) capture(pipe io.Reader, buffer *bytes.Buffer, copying *sync.WaitGroup) {
+	defer copying.Done()
+	chunk := make([]byte, 4096)
+	for {
+		n, err := pipe.Read(chunk)
+		if n > 0 {
+			gj.mutex.Lock()
+			buffer.Write(chunk[:n])
+			gj.cond.Broadcast()
+			gj.mutex.Unlock()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// wait records the exit status of the job when its process exits and its
+// output has been captured. A process killed by a signal has the status
+// 128 + signal, like bash.
+func (gj *GluJob) wait(copying *sync.WaitGroup) {
+	copying.Wait()
+	err := gj.cmd.Wait()
+	gj.mutex.Lock()
+	defer gj.mutex.Unlock()
+	gj.duration = time.Since(gj.start)
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		gj.code = int64(exitErr.ExitCode())
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+			gj.code = 128 + int64(status.Signal())
+			gj.signaled = true
+		}
+	}
+	gj.done = true
+	gj.cond.Broadcast()
+}
+
+// Running returns true if the process of the job has not exited.
+func (gj *GluJob) Running() bool {
+	gj.mutex.Lock()
+	defer gj.mutex.Unlock()
+	return !gj.done
+}
+
+// Pid returns the process id of the job, or 0 if it could not be started.
+func (gj *GluJob) Pid() int {
+	if gj.cmd.Process == nil {
+		return 0
+	}
+	return gj.cmd.Process.Pid
+}
+
+// Get returns the value of the named property of the job.
+func (gj *GluJob) Get(name *token.Token) interface{} {
+	gj.mutex.Lock()
+	defer gj.mutex.Unlock()
+	switch name.Lexeme {
+	case "id":
+		return gj.ID
+	case "pid":
+		return int64(gj.Pid())
+	case "command":
+		return gj.Command
+	case "running":
+		return !gj.done
+	case "code":
+		if !gj.done {
+			return nil
+		}
+		return gj.code
+	case "stdout":
+		return gj.stdout.String()
+	case "stderr":
+		return gj.stderr.String()
+	}
+	err := fmt.Sprintf("Undefined property '%s'.", name.Lexeme)
+	panic(NewError(name, err))
+}
+
+func (gj *GluJob) String() string {
+	return fmt.Sprintf("<job %d>", gj.ID)
+}
+
+// JobTable ===================================================================
+//
+
+// JobTable holds the jobs started by an interpreter.
+type JobTable struct {
+	mutex sync.Mutex
+	jobs  []*GluJob
+}
+
+// NewJobTable constructor.
+func NewJobTable() *JobTable {
+	return &JobTable{}
+}
+
+// Start starts the command as a new job.
+func (jt *JobTable) Start(cmd *exec.Cmd) *GluJob {
+	jt.mutex.Lock()
+	defer jt.mutex.Unlock()
+	job := startJob(int64(len(jt.jobs)+1), cmd)
+	jt.jobs = append(jt.jobs, job)
+	return job
+}
+
+// Running returns the jobs that have not exited, in the order they were
+// started.
+func (jt *JobTable) Running() []*GluJob {
+	jt.mutex.Lock()
+	defer jt.mutex.Unlock()
+	var running []*GluJob
+	for _, job := range jt.jobs {
+		if job.Running() {
+			running = append(running, job)
+		}
+	}
+	return running
+}
+
+// signals are the names of the signals that can be sent to a job.
+var signals = map[string]syscall.Signal{
+	"HUP":  syscall.SIGHUP,
+	"INT":  syscall.SIGINT,
+	"QUIT": syscall.SIGQUIT,
+	"KILL": syscall.SIGKILL,
+	"USR1": syscall.SIGUSR1,
+	"USR2": syscall.SIGUSR2,
+	"TERM": syscall.SIGTERM,
+	"CONT": syscall.SIGCONT,
+	"STOP": syscall.SIGSTOP,
+}
+
+// Job Native Functions =======================================================
+//
+
+// spawnFn ----------------------------
+//
+type spawnFn struct{}
+
+func (fn spawnFn) Arity() int { return Variadic }
+func (fn spawnFn) Call(interpreter *Interpreter, arguments []interface{}) interface{} {
+	args := commandArguments(nil, arguments)
+	if len(args) == 0 {
+		panic(NewError(nil, "Expected a command to run."))
+	}
+	return interpreter.Jobs.Start(interpreter.Process.Command(args[0], args[1:]...))
+}
+
+// waitFn -----------------------------
+//
+type waitFn struct{}
+
+func (fn waitFn) Arity() int { return 1 }
+func (fn waitFn) Call(interpreter *Interpreter, arguments []interface{}) interface{} {
+	job := checkJobArgument(arguments[0])
+	job.mutex.Lock()
+	defer job.mutex.Unlock()
+	for !job.done {
+		job.cond.Wait()
+	}
+	return &GluResult{
+		Stdout:   job.stdout.String(),
+		Stderr:   job.stderr.String(),
+		Code:     job.code,
+		Duration: job.duration,
+	}
+}
+
+// killFn -----------------------------
+//
+type killFn struct{}
+
+func (fn killFn) Arity() int { return 2 }
+func (fn killFn) Call(interpreter *Interpreter, arguments []interface{}) interface{} {
+	job := checkJobArgument(arguments[0])
+	var signal syscall.Signal
+	switch value := arguments[1].(type) {
+	case int64:
+		signal = syscall.Signal(value)
+	case string:
+		var ok bool
+		signal, ok = signals[strings.TrimPrefix(strings.ToUpper(value), "SIG")]
+		if !ok {
+			panic(NewError(nil, fmt.Sprintf("Unknown signal '%s'.", value)))
+		}
+	default:
+		panic(NewError(nil, "Signal must be a name or number."))
+	}
+	if !job.Running() {
+		return false
+	}
+	if err := job.cmd.Process.Signal(signal); err != nil {
+		if err == os.ErrProcessDone {
+			return false
+		}
+		panic(NewError(nil, fmt.Sprintf("Cannot signal job %d: %v.", job.ID, err)))
+	}
+	return true
+}
+
+// statusFn ---------------------------
+//
+type statusFn struct{}
+
+func (fn statusFn) Arity() int { return 1 }
+func (fn statusFn) Call(interpreter *Interpreter, arguments []interface{}) interface{} {
+	job := checkJobArgument(arguments[0])
+	job.mutex.Lock()
+	defer job.mutex.Unlock()
+	switch {
+	case !job.done:
+		return "running"
+	case job.signaled:
+		return "signaled"
+	}
+	return "exited"
+}
+
+// readFn -----------------------------
+//
+type readFn struct{}
+
+func (fn readFn) Arity() int { return 1 }
+func (fn readFn) Call(interpreter *Interpreter, arguments []interface{}) interface{} {
+	job := checkJobArgument(arguments[0])
+	job.mutex.Lock()
+	defer job.mutex.Unlock()
+	// Block until there is unread output or the job has exited.
+	for job.read == job.stdout.Len() && !job.done {
+		job.cond.Wait()
+	}
+	if job.read == job.stdout.Len() {
+		return nil
+	}
+	output := job.stdout.String()[job.read:]
+	job.read = job.stdout.Len()
+	return output
+}
+
+// jobsFn -----------------------------
+//
+type jobsFn struct{}
+
+func (fn jobsFn) Arity() int { return 0 }
+func (fn jobsFn) Call(interpreter *Interpreter, arguments []interface{}) interface{} {
+	var running []interface{}
+	for _, job := range interpreter.Jobs.Running() {
+		running = append(running, job)
+	}
+	return NewGluList(running)
+}
+
+func checkJobArgument(argument interface{}) *GluJob {
+	job, ok := argument.(*GluJob)
+	if !ok {
+		panic(NewError(nil, "Argument must be a job."))
+	}
+	return job
+}
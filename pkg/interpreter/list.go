@@ -0,0 +1,170 @@
+package interpreter
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/templecloud/glu/pkg/token"
+)
+
+// GluList ====================================================================
+//
+
+// GluList represents a mutable, ordered list of values.
+type GluList struct {
+	Elements []interface{}
+}
+
+// NewGluList creates a GluList containing the specified elements.
+func NewGluList(elements []interface{}) *GluList {
+	return &GluList{Elements: elements}
+}
+
+// Get returns the element at the specified index. Negative indices count back
+// from the end of the list.
+func (gl *GluList) Get(bracket *token.Token, index interface{}) interface{} {
+	return gl.Elements[gl.index(bracket, index)]
+}
+
+// Set assigns the element at the specified index. Negative indices count back
+// from the end of the list.
+func (gl *GluList) Set(bracket *token.Token, index interface{}, value interface{}) {
+	gl.Elements[gl.index(bracket, index)] = value
+}
+
+// Slice returns a new list containing the elements from start up to, but not
+// including, end. Nil bounds default to the start and end of the list, and
+// out of range bounds are clamped.
+func (gl *GluList) Slice(bracket *token.Token, start, end interface{}) *GluList {
+	length := len(gl.Elements)
+	from, to := 0, length
+	if start != nil {
+		from = clamp(toInteger(bracket, start), length)
+	}
+	if end != nil {
+		to = clamp(toInteger(bracket, end), length)
+	}
+	elements := []interface{}{}
+	if from < to {
+		elements = append(elements, gl.Elements[from:to]...)
+	}
+	return NewGluList(elements)
+}
+
+func (gl *GluList) String() string {
+	return gl.format(map[interface{}]bool{})
+}
+
+// format returns the string representation of the list. The collections being
+// formatted are visiting, so a list that contains itself is shown as '[...]'.
+func (gl *GluList) format(visiting map[interface{}]bool) string {
+	if visiting[gl] {
+		return "[...]"
+	}
+	visiting[gl] = true
+	defer delete(visiting, gl)
+	var builder strings.Builder
+	builder.WriteString("[")
+	for idx, element := range gl.Elements {
+		builder.WriteString(quote(element, visiting))
+		if idx < len(gl.Elements)-1 {
+			builder.WriteString(", ")
+		}
+	}
+	builder.WriteString("]")
+	return builder.String()
+}
+
+// index converts a Glu index value into a valid position in the list.
+func (gl *GluList) index(bracket *token.Token, value interface{}) int {
+	index := toInteger(bracket, value)
+	if index < 0 {
+		index += len(gl.Elements)
+	}
+	if index < 0 || index >= len(gl.Elements) {
+		panic(NewError(bracket, "List index out of range."))
+	}
+	return index
+}
+
+// Support Functions ==========================================================
+//
+
+// toInteger converts a Glu integer value into an int.
+func toInteger(token *token.Token, value interface{}) int {
+	number, ok := value.(int64)
+	if !ok {
+		panic(NewError(token, "Index must be an integer."))
+	}
+	return int(number)
+}
+
+// clamp converts a possibly negative slice bound into the range [0, length].
+func clamp(bound int, length int) int {
+	if bound < 0 {
+		bound += length
+	}
+	if bound < 0 {
+		return 0
+	}
+	if bound > length {
+		return length
+	}
+	return bound
+}
+
+// List Native Functions ======================================================
+//
+
+// lenFn ------------------------------
+//
+type lenFn struct{}
+
+func (fn lenFn) Arity() int { return 1 }
+func (fn lenFn) Call(interpreter *Interpreter, arguments []interface{}) interface{} {
+	switch value := arguments[0].(type) {
+	case *GluList:
+		return int64(len(value.Elements))
+	case *GluMap:
+		return int64(value.Len())
+	case string:
+		return int64(utf8.RuneCountInString(value))
+	}
+	msg := fmt.Sprintf("Cannot take the length of '%s'.", stringify(arguments[0]))
+	panic(NewError(nil, msg))
+}
+
+// pushFn -----------------------------
+//
+type pushFn struct{}
+
+func (fn pushFn) Arity() int { return 2 }
+func (fn pushFn) Call(interpreter *Interpreter, arguments []interface{}) interface{} {
+	list := checkListArgument(arguments[0])
+	list.Elements = append(list.Elements, arguments[1])
+	return int64(len(list.Elements))
+}
+
+// popFn ------------------------------
+//
+type popFn struct{}
+
+func (fn popFn) Arity() int { return 1 }
+func (fn popFn) Call(interpreter *Interpreter, arguments []interface{}) interface{} {
+	list := checkListArgument(arguments[0])
+	if len(list.Elements) == 0 {
+		panic(NewError(nil, "Cannot pop from an empty list."))
+	}
+	last := list.Elements[len(list.Elements)-1]
+	list.Elements = list.Elements[:len(list.Elements)-1]
+	return last
+}
+
+func checkListArgument(argument interface{}) *GluList {
+	list, ok := argument.(*GluList)
+	if !ok {
+		panic(NewError(nil, "Argument must be a list."))
+	}
+	return list
+}
@@ -0,0 +1,199 @@
+package interpreter
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/templecloud/glu/pkg/token"
+)
+
+// GluMap =====================================================================
+//
+
+// GluMap represents a mutable map of keys to values. Entries are kept in
+// insertion order so that iteration and printing are deterministic.
+type GluMap struct {
+	keys   []interface{}
+	values map[interface{}]interface{}
+}
+
+// NewGluMap creates an empty GluMap.
+func NewGluMap() *GluMap {
+	return &GluMap{values: make(map[interface{}]interface{})}
+}
+
+// Get returns the value of the specified key.
+func (gm *GluMap) Get(bracket *token.Token, key interface{}) interface{} {
+	checkMapKey(bracket, key)
+	key = mapKey(key)
+	if value, ok := gm.values[key]; ok {
+		return value
+	}
+	err := fmt.Sprintf("Undefined key '%s'.", stringify(key))
+	panic(NewError(bracket, err))
+}
+
+// Set assigns the value of the specified key. New keys are added after all
+// existing keys.
+func (gm *GluMap) Set(bracket *token.Token, key interface{}, value interface{}) {
+	checkMapKey(bracket, key)
+	key = mapKey(key)
+	if _, ok := gm.values[key]; !ok {
+		gm.keys = append(gm.keys, key)
+	}
+	gm.values[key] = value
+}
+
+// Has returns true if the map contains the specified key; false otherwise.
+func (gm *GluMap) Has(key interface{}) bool {
+	_, ok := gm.values[mapKey(key)]
+	return ok
+}
+
+// Delete removes the specified key and returns true if it was present.
+func (gm *GluMap) Delete(key interface{}) bool {
+	if !gm.Has(key) {
+		return false
+	}
+	key = mapKey(key)
+	delete(gm.values, key)
+	for idx, k := range gm.keys {
+		if k == key {
+			gm.keys = append(gm.keys[:idx], gm.keys[idx+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// Keys returns the keys of the map in insertion order.
+func (gm *GluMap) Keys() []interface{} {
+	return append([]interface{}{}, gm.keys...)
+}
+
+// Values returns the values of the map in key insertion order.
+func (gm *GluMap) Values() []interface{} {
+	values := make([]interface{}, 0, len(gm.keys))
+	for _, key := range gm.keys {
+		values = append(values, gm.values[key])
+	}
+	return values
+}
+
+// Len returns the number of entries in the map.
+func (gm *GluMap) Len() int {
+	return len(gm.keys)
+}
+
+func (gm *GluMap) String() string {
+	return gm.format(map[interface{}]bool{})
+}
+
+// format returns the string representation of the map. The collections being
+// formatted are visiting, so a map that contains itself is shown as '{...}'.
+func (gm *GluMap) format(visiting map[interface{}]bool) string {
+	if visiting[gm] {
+		return "{...}"
+	}
+	visiting[gm] = true
+	defer delete(visiting, gm)
+	var builder strings.Builder
+	builder.WriteString("{")
+	for idx, key := range gm.keys {
+		builder.WriteString(quote(key, visiting))
+		builder.WriteString(": ")
+		builder.WriteString(quote(gm.values[key], visiting))
+		if idx < len(gm.keys)-1 {
+			builder.WriteString(", ")
+		}
+	}
+	builder.WriteString("}")
+	return builder.String()
+}
+
+// checkMapKey panics if the key cannot be used as a map key.
+func checkMapKey(token *token.Token, key interface{}) {
+	switch key.(type) {
+	case string, int64, bool:
+		return
+	case float64:
+		if math.IsNaN(key.(float64)) {
+			panic(NewError(token, "Map key must not be nan."))
+		}
+	default:
+		panic(NewError(token, "Map key must be a string, number or boolean."))
+	}
+}
+
+// mapKey normalises a key so that numerically equal integer and float keys
+// refer to the same entry.
+func mapKey(key interface{}) interface{} {
+	if number, ok := key.(float64); ok && number == math.Trunc(number) &&
+		number >= math.MinInt64 && number < math.MaxInt64 {
+		return int64(number)
+	}
+	return key
+}
+
+// quote returns the string representation of a value nested in a collection.
+// Strings are quoted so that they are distinguishable from other values.
+func quote(value interface{}, visiting map[interface{}]bool) string {
+	switch v := value.(type) {
+	case string:
+		return strconv.Quote(v)
+	case *GluList:
+		return v.format(visiting)
+	case *GluMap:
+		return v.format(visiting)
+	}
+	return stringify(value)
+}
+
+// Map Native Functions =======================================================
+//
+
+// keysFn -----------------------------
+//
+type keysFn struct{}
+
+func (fn keysFn) Arity() int { return 1 }
+func (fn keysFn) Call(interpreter *Interpreter, arguments []interface{}) interface{} {
+	return NewGluList(checkMapArgument(arguments[0]).Keys())
+}
+
+// valuesFn ---------------------------
+//
+type valuesFn struct{}
+
+func (fn valuesFn) Arity() int { return 1 }
+func (fn valuesFn) Call(interpreter *Interpreter, arguments []interface{}) interface{} {
+	return NewGluList(checkMapArgument(arguments[0]).Values())
+}
+
+// hasFn ------------------------------
+//
+type hasFn struct{}
+
+func (fn hasFn) Arity() int { return 2 }
+func (fn hasFn) Call(interpreter *Interpreter, arguments []interface{}) interface{} {
+	return checkMapArgument(arguments[0]).Has(arguments[1])
+}
+
+// deleteFn ---------------------------
+//
+type deleteFn struct{}
+
+func (fn deleteFn) Arity() int { return 2 }
+func (fn deleteFn) Call(interpreter *Interpreter, arguments []interface{}) interface{} {
+	return checkMapArgument(arguments[0]).Delete(arguments[1])
+}
+
+func checkMapArgument(argument interface{}) *GluMap {
+	m, ok := argument.(*GluMap)
+	if !ok {
+		panic(NewError(nil, "Argument must be a map."))
+	}
+	return m
+}
@@ -0,0 +1,202 @@
+package interpreter
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/templecloud/glu/pkg/ast"
+	"github.com/templecloud/glu/pkg/lexer"
+	"github.com/templecloud/glu/pkg/parser"
+	"github.com/templecloud/glu/pkg/resolver"
+	"github.com/templecloud/glu/pkg/token"
+)
+
+// GluModule ==================================================================
+//
+
+// GluModule represents a Glu source file. Each module has its own
+// environment, and only the declarations it exports are visible to the
+// modules that import it.
+type GluModule struct {
+	// Path is the absolute path of the module file, or empty for a script that
+	// was not read from a file.
+	Path    string
+	env     *Environment
+	exports map[string]bool
+	loaded  bool
+}
+
+// NewGluModule constructor.
+func NewGluModule(path string, env *Environment) *GluModule {
+	return &GluModule{Path: path, env: env, exports: map[string]bool{}}
+}
+
+// Name returns the path of the module relative to the working directory if
+// it is beneath it, or else its absolute path.
+func (gm *GluModule) Name() string {
+	if gm.Path == "" {
+		return "<script>"
+	}
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, gm.Path); err == nil && !strings.HasPrefix(rel, "..") {
+			return rel
+		}
+	}
+	return gm.Path
+}
+
+// Dir returns the directory that the imports of the module are resolved
+// against.
+func (gm *GluModule) Dir() string {
+	if gm.Path == "" {
+		wd, _ := os.Getwd()
+		return wd
+	}
+	return filepath.Dir(gm.Path)
+}
+
+// Export makes the named declaration visible to importers.
+func (gm *GluModule) Export(name string) {
+	gm.exports[name] = true
+}
+
+// Get returns the value of the named export of the module.
+func (gm *GluModule) Get(name *token.Token) interface{} {
+	if !gm.exports[name.Lexeme] {
+		err := fmt.Sprintf("Module '%s' does not export '%s'.", gm.Name(), name.Lexeme)
+		panic(NewError(name, err))
+	}
+	return gm.env.Values[name.Lexeme]
+}
+
+// Globals returns the top-level environment of the module.
+func (gm *GluModule) Globals() *Environment {
+	return gm.env
+}
+
+func (gm *GluModule) String() string {
+	return fmt.Sprintf("<module %s>", gm.Name())
+}
+
+// Module Functions ===========================================================
+//
+
+// ResolveImport returns the absolute path of an import. A relative path is
+// resolved against the directory of the current module and then each
+// directory of the colon separated GLU_PATH. If no file is found, the path
+// relative to the current module is returned.
+func (i *Interpreter) ResolveImport(path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	candidates := []string{filepath.Join(i.Module.Dir(), path)}
+	if gluPath, ok := i.Process.Get("GLU_PATH"); ok {
+		for _, dir := range filepath.SplitList(gluPath) {
+			if dir != "" {
+				candidates = append(candidates, filepath.Join(dir, path))
+			}
+		}
+	}
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			abs, _ := filepath.Abs(candidate)
+			return abs
+		}
+	}
+	abs, _ := filepath.Abs(candidates[0])
+	return abs
+}
+
+// importModule returns the module at the path, evaluating its statements if it
+// has not been imported before.
+func (i *Interpreter) importModule(path *token.Token) *GluModule {
+	return i.LoadModule(path, func(stmts []ast.Stmt) {
+		for _, stmt := range stmts {
+			if c := completion(i.evaluate(stmt)); c != nil && c.Kind == Throw {
+				panic(c.Value)
+			}
+		}
+	})
+}
+
+// LoadModule returns the module at the path, loading it with the run function
+// if it has not been imported before. The current module and environment are
+// those of the loading module while it runs. Each module is executed once; a
+// module that is imported while it is still loading is an import cycle.
+// Errors are reported at the path.
+func (i *Interpreter) LoadModule(path *token.Token, run func(stmts []ast.Stmt)) *GluModule {
+	resolved := i.ResolveImport(path.Lexeme)
+	if module, ok := i.modules[resolved]; ok {
+		if !module.loaded {
+			panic(NewError(path, i.importCycle(module)))
+		}
+		return module
+	}
+	data, err := ioutil.ReadFile(resolved)
+	if err != nil {
+		if pathErr, ok := err.(*os.PathError); ok {
+			err = pathErr.Err
+		}
+		panic(NewError(path, fmt.Sprintf("Cannot import '%s': %v.", path.Lexeme, err)))
+	}
+	module := NewGluModule(resolved, NewChildEnvironment(i.natives))
+	stmts := i.parseModule(path, module, string(data))
+
+	i.modules[resolved] = module
+	i.loading = append(i.loading, module)
+	previousModule, previousEnv := i.Module, i.Environment
+	i.Module, i.Environment = module, module.env
+	defer func() {
+		i.Module, i.Environment = previousModule, previousEnv
+		i.loading = i.loading[:len(i.loading)-1]
+		if !module.loaded {
+			// Allow a module that failed to load to be imported again.
+			delete(i.modules, resolved)
+		}
+	}()
+	run(stmts)
+	module.loaded = true
+	return module
+}
+
+// parseModule tokenizes, parses and resolves the source of a module. The first
+// error is reported at the import path.
+func (i *Interpreter) parseModule(path *token.Token, module *GluModule, source string) []ast.Stmt {
+	tokens, lexErrs := lexer.NewWithOrigin(source, module.Name()).ScanTokens()
+	if len(lexErrs) > 0 {
+		lexErr := lexErrs[0]
+		err := fmt.Sprintf("Cannot import '%s': %s %s At Line: %d, Column: %d.",
+			path.Lexeme, lexErr.Origin, lexErr.Message, lexErr.Line+1, lexErr.Column+1)
+		panic(NewError(path, err))
+	}
+	p := parser.New(tokens)
+	stmts := p.Parse()
+	if len(p.Errors) > 0 {
+		err := fmt.Sprintf("Cannot import '%s': %s", path.Lexeme, p.Errors[0].Error())
+		panic(NewError(path, err))
+	}
+	r := resolver.New()
+	r.Resolve(stmts)
+	if len(r.Errors) > 0 {
+		err := fmt.Sprintf("Cannot import '%s': %s", path.Lexeme, r.Errors[0].Error())
+		panic(NewError(path, err))
+	}
+	i.Resolve(r.Locals)
+	return stmts
+}
+
+// importCycle describes the chain of imports from the module back to itself.
+func (i *Interpreter) importCycle(module *GluModule) string {
+	var chain []string
+	for idx := len(i.loading) - 1; idx >= 0; idx-- {
+		chain = append([]string{i.loading[idx].Name()}, chain...)
+		if i.loading[idx] == module {
+			break
+		}
+	}
+	chain = append(chain, module.Name())
+	return fmt.Sprintf("Import cycle detected: %s.", strings.Join(chain, " -> "))
+}
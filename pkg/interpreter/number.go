@@ -0,0 +1,312 @@
+package interpreter
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/templecloud/glu/pkg/token"
+)
+
+// Number Functions ===========================================================
+//
+// Glu has two numeric types; integers (int64) and floats (float64). The
+// promotion rules for binary arithmetic and comparison operators are:
+//
+//   * int op int     -> int, except '/' which always returns a float and '**'
+//                       with a negative exponent which returns a float.
+//   * int op float   -> float, the int is converted to a float.
+//   * float op float -> float.
+//
+// The bitwise operators are only defined for integers.
+//
+// Integer arithmetic that overflows, and integer division or modulo by zero,
+// are runtime errors. Float arithmetic follows IEEE 754, so '1.0 / 0' is inf
+// and '0.0 / 0' is nan.
+//
+
+// isNumber returns true if the value is a Glu integer or float.
+func isNumber(value interface{}) bool {
+	switch value.(type) {
+	case int64, float64:
+		return true
+	}
+	return false
+}
+
+// toFloat converts a Glu number into a float64.
+func toFloat(value interface{}) float64 {
+	switch number := value.(type) {
+	case int64:
+		return float64(number)
+	case float64:
+		return number
+	}
+	return math.NaN()
+}
+
+// arithmetic applies an arithmetic operator to two numbers according to the
+// Glu promotion rules.
+func arithmetic(operator *token.Token, left, right interface{}) interface{} {
+	l, lok := left.(int64)
+	r, rok := right.(int64)
+	if lok && rok && !(operator.Type == token.StarDual && r < 0) {
+		result, ok := l, true
+		switch operator.Type {
+		case token.Plus:
+			result, ok = add(l, r)
+		case token.Minus:
+			result, ok = subtract(l, r)
+		case token.Star:
+			result, ok = multiply(l, r)
+		case token.ForwardSlash:
+			checkDivisor(operator, r)
+			return float64(l) / float64(r)
+		case token.ForwardSlashDual:
+			checkDivisor(operator, r)
+			result, ok = floorDivide(l, r)
+		case token.Percent:
+			checkDivisor(operator, r)
+			result = floorModulo(l, r)
+		case token.StarDual:
+			result, ok = power(l, r)
+		}
+		return checkOverflow(operator, result, ok)
+	}
+	lf, rf := toFloat(left), toFloat(right)
+	switch operator.Type {
+	case token.Plus:
+		return lf + rf
+	case token.Minus:
+		return lf - rf
+	case token.Star:
+		return lf * rf
+	case token.ForwardSlash:
+		return lf / rf
+	case token.ForwardSlashDual:
+		return math.Floor(lf / rf)
+	case token.Percent:
+		return floatFloorModulo(lf, rf)
+	case token.StarDual:
+		return math.Pow(lf, rf)
+	}
+	// Unreachable.
+	return nil
+}
+
+// compare applies a comparison operator to two numbers according to the Glu
+// promotion rules.
+func compare(operator *token.Token, left, right interface{}) bool {
+	l, lok := left.(int64)
+	r, rok := right.(int64)
+	if lok && rok {
+		switch operator.Type {
+		case token.GreaterThan:
+			return l > r
+		case token.GreaterThanOrEqual:
+			return l >= r
+		case token.LessThan:
+			return l < r
+		case token.LessThanOrEqual:
+			return l <= r
+		}
+	}
+	lf, rf := toFloat(left), toFloat(right)
+	switch operator.Type {
+	case token.GreaterThan:
+		return lf > rf
+	case token.GreaterThanOrEqual:
+		return lf >= rf
+	case token.LessThan:
+		return lf < rf
+	case token.LessThanOrEqual:
+		return lf <= rf
+	}
+	// Unreachable.
+	return false
+}
+
+// bitwise applies a bitwise operator to two integers.
+func bitwise(operator *token.Token, left, right int64) interface{} {
+	switch operator.Type {
+	case token.Ampersand:
+		return left & right
+	case token.Pipe:
+		return left | right
+	case token.Caret:
+		return left ^ right
+	case token.LessThanDual:
+		checkShiftCount(operator, right)
+		return left << uint64(right)
+	case token.GreaterThanDual:
+		checkShiftCount(operator, right)
+		return left >> uint64(right)
+	}
+	// Unreachable.
+	return nil
+}
+
+// add returns the sum of two integers and false if it overflows.
+func add(left, right int64) (int64, bool) {
+	sum := left + right
+	return sum, (right >= 0) == (sum >= left)
+}
+
+// subtract returns the difference of two integers and false if it overflows.
+func subtract(left, right int64) (int64, bool) {
+	difference := left - right
+	return difference, (right >= 0) == (difference <= left)
+}
+
+// multiply returns the product of two integers and false if it overflows.
+func multiply(left, right int64) (int64, bool) {
+	if left == 0 || right == 0 {
+		return 0, true
+	}
+	product := left * right
+	if (left == -1 && right == math.MinInt64) ||
+		(right == -1 && left == math.MinInt64) {
+		return product, false
+	}
+	return product, product/right == left
+}
+
+// floorModulo returns the remainder of the floored division of two integers,
+// which has the sign of the divisor.
+func floorModulo(left, right int64) int64 {
+	remainder := left % right
+	if remainder != 0 && (remainder < 0) != (right < 0) {
+		remainder += right
+	}
+	return remainder
+}
+
+// floatFloorModulo returns the remainder of the floored division of two
+// floats, which has the sign of the divisor.
+func floatFloorModulo(left, right float64) float64 {
+	remainder := math.Mod(left, right)
+	if remainder != 0 && (remainder < 0) != (right < 0) {
+		remainder += right
+	}
+	return remainder
+}
+
+// floorDivide returns the quotient of two integers rounded towards negative
+// infinity and false if it overflows.
+func floorDivide(left, right int64) (int64, bool) {
+	if left == math.MinInt64 && right == -1 {
+		return left, false
+	}
+	quotient := left / right
+	if left%right != 0 && (left < 0) != (right < 0) {
+		quotient--
+	}
+	return quotient, true
+}
+
+// power raises an integer to a non-negative integer exponent and returns
+// false if it overflows.
+func power(base, exponent int64) (int64, bool) {
+	result, ok := int64(1), true
+	for exponent > 0 && ok {
+		if exponent&1 == 1 {
+			result, ok = multiply(result, base)
+		}
+		exponent >>= 1
+		if exponent > 0 && ok {
+			base, ok = multiply(base, base)
+		}
+	}
+	return result, ok
+}
+
+// negate returns the negation of a number.
+func negate(operator *token.Token, value interface{}) interface{} {
+	if number, ok := value.(int64); ok {
+		result, ok := subtract(0, number)
+		return checkOverflow(operator, result, ok)
+	}
+	return -toFloat(value)
+}
+
+// isNumberEqual returns true if two numbers have the same value, regardless
+// of their types.
+func isNumberEqual(left, right interface{}) bool {
+	l, lok := left.(int64)
+	r, rok := right.(int64)
+	if lok && rok {
+		return l == r
+	}
+	return toFloat(left) == toFloat(right)
+}
+
+// formatFloat returns the string representation of a float. Floats always
+// contain a decimal point or exponent so they are distinguishable from
+// integers, except for 'inf', '-inf' and 'nan'.
+func formatFloat(number float64) string {
+	switch {
+	case math.IsInf(number, 1):
+		return "inf"
+	case math.IsInf(number, -1):
+		return "-inf"
+	case math.IsNaN(number):
+		return "nan"
+	}
+	s := strconv.FormatFloat(number, 'g', -1, 64)
+	if strings.ContainsAny(s, ".e") {
+		return s
+	}
+	return s + ".0"
+}
+
+// checkOverflow panics if the result of an integer operation overflowed.
+func checkOverflow(operator *token.Token, result int64, ok bool) int64 {
+	if !ok {
+		panic(NewError(operator, "Integer overflow."))
+	}
+	return result
+}
+
+// checkDivisor panics if an integer divisor is zero.
+func checkDivisor(operator *token.Token, divisor int64) {
+	if divisor == 0 {
+		panic(NewError(operator, "Division by zero."))
+	}
+}
+
+// checkShiftCount panics if a shift count is negative.
+func checkShiftCount(operator *token.Token, count int64) {
+	if count < 0 {
+		panic(NewError(operator, "Shift count must be non-negative."))
+	}
+}
+
+// Number Native Functions ====================================================
+//
+
+// isInfFn ----------------------------
+//
+type isInfFn struct{}
+
+func (fn isInfFn) Arity() int { return 1 }
+func (fn isInfFn) Call(interpreter *Interpreter, arguments []interface{}) interface{} {
+	number := checkNumberArgument(arguments[0])
+	return math.IsInf(toFloat(number), 0)
+}
+
+// isNaNFn ----------------------------
+//
+type isNaNFn struct{}
+
+func (fn isNaNFn) Arity() int { return 1 }
+func (fn isNaNFn) Call(interpreter *Interpreter, arguments []interface{}) interface{} {
+	number := checkNumberArgument(arguments[0])
+	return math.IsNaN(toFloat(number))
+}
+
+func checkNumberArgument(argument interface{}) interface{} {
+	if !isNumber(argument) {
+		panic(NewError(nil, "Argument must be a number."))
+	}
+	return argument
+}
@@ -0,0 +1,278 @@
+package interpreter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/templecloud/glu/pkg/ast"
+	"github.com/templecloud/glu/pkg/token"
+)
+
+// Operations =================================================================
+//
+// The operations below define the semantics of Glu values. They are shared by
+// the tree-walking Interpreter and the bytecode VM so that both report the
+// same results and errors.
+//
+
+// Stringify returns the string representation of a value used by 'log'.
+func Stringify(value interface{}) string {
+	return stringify(value)
+}
+
+// IsTruthy returns the truthiness of a value.
+func IsTruthy(value interface{}) bool {
+	return isTruthy(value)
+}
+
+// IsEqual returns true if the values are equal.
+func IsEqual(left interface{}, right interface{}) bool {
+	return isEqual(left, right)
+}
+
+// Binary applies a binary operator, other than a pipe, to its operands.
+func Binary(operator *token.Token, left, right interface{}) interface{} {
+	switch operator.Type {
+	// Compators
+	case token.GreaterThan:
+		if l, r, ok := stringOperands(left, right); ok {
+			return l > r
+		}
+		checkComparableOperands(operator, left, right)
+		return compare(operator, left, right)
+	case token.GreaterThanOrEqual:
+		if l, r, ok := stringOperands(left, right); ok {
+			return l >= r
+		}
+		checkComparableOperands(operator, left, right)
+		return compare(operator, left, right)
+	case token.LessThan:
+		if l, r, ok := stringOperands(left, right); ok {
+			return l < r
+		}
+		checkComparableOperands(operator, left, right)
+		return compare(operator, left, right)
+	case token.LessThanOrEqual:
+		if l, r, ok := stringOperands(left, right); ok {
+			return l <= r
+		}
+		checkComparableOperands(operator, left, right)
+		return compare(operator, left, right)
+	// Equality
+	case token.NotEqual:
+		return !isEqual(left, right)
+	case token.EqualEqual:
+		return isEqual(left, right)
+	// Arithmetic
+	case token.Plus:
+		if isString(left) || isString(right) {
+			return concatenate(operator, left, right)
+		}
+		checkNumberOperands(operator, left, right)
+		return arithmetic(operator, left, right)
+	case token.Minus:
+		checkNumberOperands(operator, left, right)
+		return arithmetic(operator, left, right)
+	case token.ForwardSlash:
+		checkNumberOperands(operator, left, right)
+		return arithmetic(operator, left, right)
+	case token.Star:
+		if isString(left) || isString(right) {
+			return repeat(operator, left, right)
+		}
+		checkNumberOperands(operator, left, right)
+		return arithmetic(operator, left, right)
+	case token.ForwardSlashDual, token.Percent, token.StarDual:
+		checkNumberOperands(operator, left, right)
+		return arithmetic(operator, left, right)
+	// Bitwise
+	case token.Ampersand, token.Caret,
+		token.LessThanDual, token.GreaterThanDual:
+		checkIntegerOperands(operator, left, right)
+		return bitwise(operator, left.(int64), right.(int64))
+	}
+	// Unreachable.
+	return nil
+}
+
+// Unary applies a unary operator to its operand.
+func Unary(operator *token.Token, right interface{}) interface{} {
+	switch operator.Type {
+	case token.Not:
+		return !isTruthy(right)
+	case token.Minus:
+		checkNumberOperand(operator, right)
+		return negate(operator, right)
+	case token.Tilde:
+		checkIntegerOperand(operator, right)
+		return ^right.(int64)
+	}
+	return nil
+}
+
+// Property returns the named property of an object.
+func Property(name *token.Token, object interface{}) interface{} {
+	if instance, ok := object.(GluObject); ok {
+		return instance.Get(name)
+	}
+	panic(NewError(name, "Only instances have properties."))
+}
+
+// Index returns the element of a list or map at the index.
+func Index(bracket *token.Token, object interface{}, index interface{}) interface{} {
+	switch collection := object.(type) {
+	case *GluList:
+		return collection.Get(bracket, index)
+	case *GluMap:
+		return collection.Get(bracket, index)
+	}
+	panic(NewError(bracket, "Only lists and maps can be indexed."))
+}
+
+// SetIndex assigns the element of a list or map at the index.
+func SetIndex(bracket *token.Token, object interface{}, index interface{}, value interface{}) {
+	switch collection := object.(type) {
+	case *GluList:
+		collection.Set(bracket, index, value)
+	case *GluMap:
+		collection.Set(bracket, index, value)
+	default:
+		panic(NewError(bracket, "Only lists and maps can be indexed."))
+	}
+}
+
+// Slice returns the elements of a list between the start and end indexes. A
+// nil index is the start or end of the list.
+func Slice(bracket *token.Token, object interface{}, start, end interface{}) interface{} {
+	list, ok := object.(*GluList)
+	if !ok {
+		panic(NewError(bracket, "Only lists can be sliced."))
+	}
+	return list.Slice(bracket, start, end)
+}
+
+// Concat returns the concatenation of the string values of the parts of an
+// interpolated string.
+func Concat(parts []interface{}) string {
+	var builder strings.Builder
+	for _, part := range parts {
+		builder.WriteString(stringify(part))
+	}
+	return builder.String()
+}
+
+// SetEnv sets the process environment variable to the string value of the
+// value, or unsets it if the value is nil. It returns the value set.
+func SetEnv(name *token.Token, value interface{}, env *ProcessEnvironment) interface{} {
+	if value == nil {
+		env.Unset(name.Lexeme)
+		return nil
+	}
+	s := envValue(name, value)
+	env.Set(name.Lexeme, s)
+	return s
+}
+
+// Override applies a map of environment variables to the process environment
+// and returns a function that restores it. A nil value unsets the variable.
+func Override(keyword *token.Token, value interface{}, env *ProcessEnvironment) func() {
+	vars, ok := value.(*GluMap)
+	if !ok {
+		panic(NewError(keyword, "Expected a map of environment variables."))
+	}
+	overrides := make(map[string]*string)
+	for _, key := range vars.Keys() {
+		name, ok := key.(string)
+		if !ok {
+			panic(NewError(keyword, "Environment variable names must be strings."))
+		}
+		var value *string
+		if v := vars.values[key]; v != nil {
+			s := envValue(keyword, v)
+			value = &s
+		}
+		overrides[name] = value
+	}
+	return env.Override(overrides)
+}
+
+// Pipe applies a chain of '|' operators to the operand values as either a
+// pipeline or a bitwise or.
+func Pipe(operators []*token.Token, values []interface{}, env *ProcessEnvironment) interface{} {
+	isPipeline := false
+	for _, value := range values {
+		if _, ok := value.(*GluCommand); ok {
+			isPipeline = true
+		}
+	}
+	if !isPipeline {
+		result := values[0]
+		for idx, operator := range operators {
+			checkIntegerOperands(operator, result, values[idx+1])
+			result = bitwise(operator, result.(int64), values[idx+1].(int64))
+		}
+		return result
+	}
+	return runPipeline(values, operators, env)
+}
+
+// CheckCall returns the callee as a GluCallable if it can be called with the
+// number of arguments.
+func CheckCall(paren *token.Token, callee interface{}, count int) GluCallable {
+	fn, ok := callee.(GluCallable)
+	if !ok {
+		panic(NewError(paren, "Can only call functions."))
+	}
+	CheckArity(paren, fn.Arity(), count)
+	return fn
+}
+
+// CheckArity raises an error if the number of arguments does not match the
+// arity of a callable.
+func CheckArity(paren *token.Token, arity int, count int) {
+	if arity != Variadic && count != arity {
+		msg := fmt.Sprintf("Expected %d arguments, but, got %d.", arity, count)
+		panic(NewError(paren, msg))
+	}
+}
+
+// CheckDepth raises an error if a call at the depth of the call stack would
+// exceed the maximum depth.
+func CheckDepth(paren *token.Token, depth int, max int) {
+	if depth >= max {
+		msg := fmt.Sprintf("Maximum call depth of %d exceeded.", max)
+		panic(NewError(paren, msg))
+	}
+}
+
+// CallableName returns the name of the callable used in Glu stack frames.
+func CallableName(fn interface{}) string {
+	switch callable := fn.(type) {
+	case *GluFn:
+		return callable.Declaration.Name.Lexeme
+	case *GluClass:
+		return callable.Name
+	case *GluNativeFn:
+		return callable.Name
+	case *GluBashFn:
+		return callable.Name
+	default:
+		return fmt.Sprint(callable)
+	}
+}
+
+// Declarations ===============================================================
+//
+
+// DeclarationName returns the name bound by an exportable declaration.
+func DeclarationName(stmt ast.Stmt) string {
+	switch declaration := stmt.(type) {
+	case *ast.ClassStmt:
+		return declaration.Name.Lexeme
+	case *ast.FnStmt:
+		return declaration.Name.Lexeme
+	case *ast.VariableStmt:
+		return declaration.Name.Lexeme
+	}
+	return ""
+}
@@ -0,0 +1,190 @@
+package interpreter
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+
+	"github.com/templecloud/glu/pkg/ast"
+	"github.com/templecloud/glu/pkg/token"
+)
+
+// GluCommand =================================================================
+//
+
+// GluCommand represents an external command that can be used as a stage of a
+// pipeline. The command is run with 'bash -c'.
+type GluCommand struct {
+	Command string
+}
+
+func (gc *GluCommand) String() string {
+	return fmt.Sprintf("<sh %s>", gc.Command)
+}
+
+// Pipeline Functions =========================================================
+//
+// The '|' operator is bitwise or for integers. When any operand of a chain of
+// '|' operators is a GluCommand the chain is instead a pipeline, e.g.
+// '"a\nb" | sh("sort") | sh("uniq -c")'. The first operand may be a string or
+// list that is written to the standard input of the first command; every
+// other operand must be a command. Consecutive commands are connected with OS
+// pipes and the pipeline returns the standard output of the last command with
+// trailing newlines removed.
+//
+
+// pipe evaluates a chain of '|' operators as either a pipeline or a bitwise
+// or.
+func (i *Interpreter) pipe(expr *ast.Binary) interface{} {
+	operands, operators := flattenPipe(expr)
+	values := make([]interface{}, len(operands))
+	for idx, operand := range operands {
+		values[idx] = i.evaluate(operand)
+	}
+	return Pipe(operators, values, i.Process)
+}
+
+// flattenPipe returns the operands and operators of a left-associative chain
+// of '|' operators in source order.
+func flattenPipe(expr ast.Expr) ([]ast.Expr, []*token.Token) {
+	binary, ok := expr.(*ast.Binary)
+	if !ok || binary.Operator.Type != token.Pipe {
+		return []ast.Expr{expr}, nil
+	}
+	operands, operators := flattenPipe(binary.Left)
+	return append(operands, binary.Right), append(operators, binary.Operator)
+}
+
+// runPipeline runs the commands of a pipeline concurrently, connected by OS
+// pipes. A stage that cannot be started, or exits with a non-zero status, is
+// a runtime error at the '|' operator preceding it. Stages are numbered from
+// 1 in source order, including any input value.
+func runPipeline(values []interface{}, operators []*token.Token, env *ProcessEnvironment) string {
+	var input io.Reader = os.Stdin
+	first := 0
+	if _, ok := values[0].(*GluCommand); !ok {
+		input = pipelineInput(operators[0], values[0])
+		first = 1
+	}
+
+	cmds := make([]*exec.Cmd, 0, len(values)-first)
+	for idx := first; idx < len(values); idx++ {
+		command, ok := values[idx].(*GluCommand)
+		if !ok {
+			msg := fmt.Sprintf("Pipeline stage %d must be a command.", idx+1)
+			panic(NewError(operators[idx-1], msg))
+		}
+		cmd := env.Command(shell, "-c", command.Command)
+		cmd.Stderr = os.Stderr
+		cmds = append(cmds, cmd)
+	}
+
+	var pipes []*os.File
+	cmds[0].Stdin = input
+	for idx := 0; idx < len(cmds)-1; idx++ {
+		r, w, err := os.Pipe()
+		if err != nil {
+			panic(NewError(operators[0], fmt.Sprintf("Pipeline failed: %v.", err)))
+		}
+		cmds[idx].Stdout = w
+		cmds[idx+1].Stdin = r
+		pipes = append(pipes, r, w)
+	}
+	var stdout bytes.Buffer
+	cmds[len(cmds)-1].Stdout = &stdout
+
+	// Start every stage before waiting on any so that data flows between
+	// them. The parent closes its copies of the pipes so that each stage sees
+	// end-of-file when the previous stage exits.
+	var failure *Error
+	started := 0
+	for idx, cmd := range cmds {
+		if err := cmd.Start(); err != nil {
+			stage := idx + first
+			msg := fmt.Sprintf("Pipeline stage %d '%s' failed: %v.",
+				stage+1, values[stage].(*GluCommand).Command, err)
+			failure = NewError(stageOperator(operators, stage), msg)
+			break
+		}
+		started++
+	}
+	for _, pipe := range pipes {
+		pipe.Close()
+	}
+	for idx := 0; idx < started; idx++ {
+		err := cmds[idx].Wait()
+		if err == nil || failure != nil || isBrokenPipe(err) {
+			continue
+		}
+		stage := idx + first
+		code := -1
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			code = exitErr.ExitCode()
+		}
+		msg := fmt.Sprintf("Pipeline stage %d '%s' exited with status %d.",
+			stage+1, values[stage].(*GluCommand).Command, code)
+		failure = NewError(stageOperator(operators, stage), msg)
+	}
+	if failure != nil {
+		panic(failure)
+	}
+	return strings.TrimRight(stdout.String(), "\n")
+}
+
+// pipelineInput converts the input value of a pipeline into a reader. Lists
+// are written one element per line.
+func pipelineInput(operator *token.Token, value interface{}) io.Reader {
+	switch input := value.(type) {
+	case string:
+		return strings.NewReader(input)
+	case *GluList:
+		var builder strings.Builder
+		for _, element := range input.Elements {
+			builder.WriteString(stringify(element))
+			builder.WriteString("\n")
+		}
+		return strings.NewReader(builder.String())
+	}
+	panic(NewError(operator, "Pipeline input must be a string or list."))
+}
+
+// stageOperator returns the '|' operator preceding a pipeline stage, or the
+// first operator for the first stage.
+func stageOperator(operators []*token.Token, stage int) *token.Token {
+	if stage == 0 {
+		return operators[0]
+	}
+	return operators[stage-1]
+}
+
+// isBrokenPipe returns true if a stage was terminated by SIGPIPE because a
+// later stage stopped reading, e.g. 'sh("yes") | sh("head -1")'. Like the
+// shell this is not considered a failure.
+func isBrokenPipe(err error) bool {
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok {
+		return false
+	}
+	status, ok := exitErr.Sys().(syscall.WaitStatus)
+	return ok && status.Signaled() && status.Signal() == syscall.SIGPIPE
+}
+
+// Pipeline Native Functions ==================================================
+//
+
+// shFn -------------------------------
+//
+type shFn struct{}
+
+func (fn shFn) Arity() int { return 1 }
+func (fn shFn) Call(interpreter *Interpreter, arguments []interface{}) interface{} {
+	command, ok := arguments[0].(string)
+	if !ok {
+		panic(NewError(nil, "Argument must be a string."))
+	}
+	return &GluCommand{Command: command}
+}
@@ -0,0 +1,130 @@
+package interpreter
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/templecloud/glu/pkg/token"
+)
+
+// ProcessEnvironment =========================================================
+//
+
+// ProcessEnvironment represents the environment variables passed to external
+// commands run by the interpreter. It is initialised from the environment of
+// the interpreter process, but, changes are not written back to it.
+type ProcessEnvironment struct {
+	Values map[string]string
+}
+
+// NewProcessEnvironment creates a ProcessEnvironment from the environment of
+// the current process.
+func NewProcessEnvironment() *ProcessEnvironment {
+	values := make(map[string]string)
+	for _, entry := range os.Environ() {
+		if idx := strings.Index(entry, "="); idx > 0 {
+			values[entry[:idx]] = entry[idx+1:]
+		}
+	}
+	return &ProcessEnvironment{Values: values}
+}
+
+// Get returns the value of the named variable and whether it is set.
+func (pe *ProcessEnvironment) Get(name string) (string, bool) {
+	value, ok := pe.Values[name]
+	return value, ok
+}
+
+// Set assigns the value of the named variable.
+func (pe *ProcessEnvironment) Set(name, value string) {
+	pe.Values[name] = value
+}
+
+// Unset removes the named variable.
+func (pe *ProcessEnvironment) Unset(name string) {
+	delete(pe.Values, name)
+}
+
+// Environ returns the variables in the 'KEY=value' form used by os/exec.
+func (pe *ProcessEnvironment) Environ() []string {
+	environ := make([]string, 0, len(pe.Values))
+	for name, value := range pe.Values {
+		environ = append(environ, name+"="+value)
+	}
+	sort.Strings(environ)
+	return environ
+}
+
+// Command returns an exec.Cmd that runs the named program with the
+// environment. Unlike exec.Command the program is found using the PATH of the
+// environment rather than the PATH of the interpreter process.
+func (pe *ProcessEnvironment) Command(name string, args ...string) *exec.Cmd {
+	cmd := &exec.Cmd{
+		Path: name,
+		Args: append([]string{name}, args...),
+		Env:  pe.Environ(),
+	}
+	if !strings.Contains(name, string(filepath.Separator)) {
+		if path, ok := pe.lookPath(name); ok {
+			cmd.Path = path
+		} else {
+			cmd.Err = &exec.Error{Name: name, Err: exec.ErrNotFound}
+		}
+	}
+	return cmd
+}
+
+// lookPath searches the PATH of the environment for the named executable.
+func (pe *ProcessEnvironment) lookPath(name string) (string, bool) {
+	for _, dir := range filepath.SplitList(pe.Values["PATH"]) {
+		if dir == "" {
+			dir = "."
+		}
+		path := filepath.Join(dir, name)
+		info, err := os.Stat(path)
+		if err == nil && info.Mode().IsRegular() && info.Mode()&0111 != 0 {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// Override applies the specified variables and returns a function that
+// restores their previous values. A nil value unsets a variable.
+func (pe *ProcessEnvironment) Override(overrides map[string]*string) func() {
+	previous := make(map[string]*string)
+	for name, value := range overrides {
+		if old, ok := pe.Values[name]; ok {
+			previous[name] = &old
+		} else {
+			previous[name] = nil
+		}
+		pe.apply(name, value)
+	}
+	return func() {
+		for name, value := range previous {
+			pe.apply(name, value)
+		}
+	}
+}
+
+func (pe *ProcessEnvironment) apply(name string, value *string) {
+	if value == nil {
+		pe.Unset(name)
+	} else {
+		pe.Set(name, *value)
+	}
+}
+
+// envValue converts a Glu value into the value of an environment variable.
+// Collections and other objects are not allowed.
+func envValue(token *token.Token, value interface{}) string {
+	switch value.(type) {
+	case string, int64, float64, bool:
+		return stringify(value)
+	}
+	panic(NewError(token, "Environment variable values must be strings, numbers or booleans."))
+}
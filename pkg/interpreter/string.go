@@ -0,0 +1,66 @@
+package interpreter
+
+import (
+	"strings"
+
+	"github.com/templecloud/glu/pkg/token"
+)
+
+// String Operator Functions ==================================================
+//
+
+// isString returns true if the value is a Glu string; false otherwise.
+func isString(value interface{}) bool {
+	_, ok := value.(string)
+	return ok
+}
+
+// stringOperands returns the operands as strings if they are both strings.
+func stringOperands(left, right interface{}) (string, string, bool) {
+	l, lok := left.(string)
+	r, rok := right.(string)
+	return l, r, lok && rok
+}
+
+// concatenate joins a string with a string or a number. Numbers are converted
+// with the same rules as 'stringify'.
+func concatenate(operator *token.Token, left, right interface{}) string {
+	checkConcatenateOperand(operator, left)
+	checkConcatenateOperand(operator, right)
+	return stringify(left) + stringify(right)
+}
+
+// repeat repeats a string a whole, non-negative number of times. The operands
+// may be specified in either order.
+func repeat(operator *token.Token, left, right interface{}) string {
+	s, sok := left.(string)
+	n := right
+	if !sok {
+		s, sok = right.(string)
+		n = left
+	}
+	if !sok || !isNumber(n) {
+		panic(NewError(operator, "Operands must be a string and a number."))
+	}
+	count, ok := n.(int64)
+	if !ok || count < 0 {
+		panic(NewError(operator, "String repeat count must be a non-negative integer."))
+	}
+	return strings.Repeat(s, int(count))
+}
+
+func checkConcatenateOperand(operator *token.Token, operand interface{}) {
+	switch operand.(type) {
+	case string, int64, float64:
+		return
+	default:
+		panic(NewError(operator, "Operands must be strings or numbers."))
+	}
+}
+
+func checkComparableOperands(
+	operator *token.Token, leftOperand, rightOperand interface{}) {
+	if !isNumber(leftOperand) || !isNumber(rightOperand) {
+		panic(NewError(operator, "Operands must both be numbers or both be strings."))
+	}
+}
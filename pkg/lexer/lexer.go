@@ -2,6 +2,9 @@ package lexer
 
 import (
 	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/templecloud/glu/pkg/token"
 )
@@ -19,6 +22,14 @@ type Lexer struct {
 	current int
 	line    int
 	column  int
+	// previous token - used to disambiguate '//'
+	previous *token.Token
+	// brace depth of each open string interpolation
+	interpolations []int
+	// an attribute has been scanned and its function body is still to come
+	attributed bool
+	// the next token is the raw body of an attributed function
+	rawBody bool
 }
 
 // New creates a default instance of a Lexer for the specified input string.
@@ -38,6 +49,14 @@ func NewWithConfig(input string, config Config) *Lexer {
 		start:  0, current: 0, column: 0}
 }
 
+// NewWithOrigin creates a default instance of a Lexer for the specified input
+// string. The tokens record the origin, e.g. the name of the source file.
+func NewWithOrigin(input string, origin string) *Lexer {
+	l := New(input)
+	l.origin = origin
+	return l
+}
+
 // Lexical Methods ============================================================
 //
 
@@ -45,7 +64,7 @@ func NewWithConfig(input string, config Config) *Lexer {
 func (l *Lexer) ScanTokens() ([]*token.Token, []*Error) {
 	tokenz := []*token.Token{}
 	errors := []*Error{}
-	for !l.isAtEnd() {
+	for !l.isAtEnd() || l.rawBody {
 		l.start = l.current // start of next lexeme
 		t, e := l.ScanNextToken()
 		if e != nil {
@@ -66,6 +85,12 @@ func (l *Lexer) ScanTokens() ([]*token.Token, []*Error) {
 func (l *Lexer) ScanNextToken() (*token.Token, *Error) {
 	var t *token.Token
 	var e *Error
+	if l.rawBody {
+		l.rawBody = false
+		t, e = l.script()
+		l.previous = t
+		return t, e
+	}
 	c := l.advance()
 	lexeme := string(c)
 	switch c {
@@ -75,24 +100,63 @@ func (l *Lexer) ScanNextToken() (*token.Token, *Error) {
 	case ')':
 		t = l.createToken(token.RightParen, lexeme)
 	case '{':
+		if depth := len(l.interpolations); depth > 0 {
+			l.interpolations[depth-1]++
+		}
+		if l.attributed {
+			// the body of an attributed function is not Glu source.
+			l.attributed = false
+			l.rawBody = true
+		}
 		t = l.createToken(token.LeftBrace, lexeme)
 	case '}':
+		if depth := len(l.interpolations); depth > 0 {
+			if l.interpolations[depth-1] == 0 {
+				// end of an interpolated expression - resume the string.
+				l.interpolations = l.interpolations[:depth-1]
+				t, e = l.string()
+				break
+			}
+			l.interpolations[depth-1]--
+		}
 		t = l.createToken(token.RightBrace, lexeme)
+	case '[':
+		t = l.createToken(token.LeftBracket, lexeme)
+	case ']':
+		t = l.createToken(token.RightBracket, lexeme)
 	case ',':
 		t = l.createToken(token.Comma, lexeme)
 	case '.':
 		t = l.createToken(token.Dot, lexeme)
+	case ':':
+		t = l.createToken(token.Colon, lexeme)
 	case ';':
 		t = l.createToken(token.Semicolon, lexeme)
 	case '-':
 		t = l.createToken(token.Minus, lexeme)
 	case '+':
 		t = l.createToken(token.Plus, lexeme)
-	case '*':
-		t = l.createToken(token.Star, lexeme)
+	case '%':
+		t = l.createToken(token.Percent, lexeme)
+	case '&':
+		t = l.createToken(token.Ampersand, lexeme)
+	case '|':
+		t = l.createToken(token.Pipe, lexeme)
+	case '^':
+		t = l.createToken(token.Caret, lexeme)
+	case '~':
+		t = l.createToken(token.Tilde, lexeme)
 	// dual char tokens
+	case '*':
+		if l.matches('*') {
+			t = l.createToken(token.StarDual, fmt.Sprintf("%s%s", lexeme, "*"))
+		} else {
+			t = l.createToken(token.Star, lexeme)
+		}
 	case '/':
-		if l.matches('/') {
+		if isOperand(l.previous) && l.matches('/') {
+			t = l.createToken(token.ForwardSlashDual, fmt.Sprintf("%s%s", lexeme, "/"))
+		} else if l.matches('/') {
 			// consume '//' comments.
 			for !l.isAtEnd() && l.peek() != newLine && l.peek() != nilByte {
 				l.advance()
@@ -109,18 +173,24 @@ func (l *Lexer) ScanNextToken() (*token.Token, *Error) {
 	case '=':
 		if l.matches('=') {
 			t = l.createToken(token.EqualEqual, fmt.Sprintf("%s%s", lexeme, "="))
+		} else if l.matches('>') {
+			t = l.createToken(token.Arrow, fmt.Sprintf("%s%s", lexeme, ">"))
 		} else {
 			t = l.createToken(token.Equal, lexeme)
 		}
 	case '<':
 		if l.matches('=') {
 			t = l.createToken(token.LessThanOrEqual, fmt.Sprintf("%s%s", lexeme, "="))
+		} else if l.matches('<') {
+			t = l.createToken(token.LessThanDual, fmt.Sprintf("%s%s", lexeme, "<"))
 		} else {
 			t = l.createToken(token.LessThan, lexeme)
 		}
 	case '>':
 		if l.matches('=') {
 			t = l.createToken(token.GreaterThanOrEqual, fmt.Sprintf("%s%s", lexeme, "="))
+		} else if l.matches('>') {
+			t = l.createToken(token.GreaterThanDual, fmt.Sprintf("%s%s", lexeme, ">"))
 		} else {
 			t = l.createToken(token.GreaterThan, lexeme)
 		}
@@ -148,6 +218,26 @@ func (l *Lexer) ScanNextToken() (*token.Token, *Error) {
 		}
 	case '"':
 		t, e = l.string()
+	case '`':
+		t, e = l.command()
+	case '@':
+		if isAlpha(l.peek()) {
+			t = l.attribute()
+		} else {
+			for isAlphaNumeric(l.peek()) {
+				l.advance()
+			}
+			name := string(l.input[l.start+1 : l.current])
+			e = l.createError(fmt.Sprintf("Unknown attribute '@%s'.", name))
+		}
+	case '$':
+		if l.matches('(') {
+			t, e = l.parenCommand()
+		} else if isAlpha(l.peek()) {
+			t = l.envVar()
+		} else {
+			e = l.createError(fmt.Sprintf("Unexpected escape character: %c.", c))
+		}
 	default:
 		if isDigit(c) {
 			t = l.number()
@@ -158,28 +248,286 @@ func (l *Lexer) ScanNextToken() (*token.Token, *Error) {
 		}
 	}
 
+	if t != nil {
+		l.previous = t
+	}
 	return t, e
 }
 
-// Attempt to consume a 'string' from the character stream.
+// Attempt to consume a 'string' from the character stream. Strings may span
+// multiple lines and contain escape sequences. A '${' starts an interpolated
+// expression, in which case an Interpolation token holding the text so far is
+// returned and the rest of the string is consumed when the matching '}' is
+// reached.
 func (l *Lexer) string() (*token.Token, *Error) {
 	var t *token.Token
 	var e *Error
+	var builder strings.Builder
+	line, column, start := l.line, l.column, l.current
 	for !l.isAtEnd() && l.peek() != '"' {
-		if l.peek() != '\n' {
-			l.advance()
+		c := l.advance()
+		switch {
+		case c == '\\':
+			r, err := l.escape()
+			if err != nil && e == nil {
+				e = err
+			}
+			builder.WriteRune(r)
+		case c == '$' && l.peek() == '{':
+			l.advance() // consume '{'
+			l.interpolations = append(l.interpolations, 0)
+			t = token.New(token.Interpolation, builder.String(),
+				l.origin, line, column+1, l.current-start-2)
+			return t, e
+		case c == '\n':
+			l.line++
+			l.column = 0
+			builder.WriteRune(c)
+		default:
+			builder.WriteRune(c)
 		}
 	}
 	// Unterminated string.
 	if l.isAtEnd() {
 		e = l.createError("Unterminated string.")
+	} else if e == nil {
+		l.advance() // consume closing '"'
+		t = token.New(token.String, builder.String(),
+			l.origin, line, column+1, l.current-start-1)
 	} else {
 		l.advance() // consume closing '"'
-		t = l.createToken(token.String, string(l.input[l.start+1:l.current-1]))
 	}
 	return t, e
 }
 
+// Attempt to consume an escape sequence following a '\' in a string.
+func (l *Lexer) escape() (rune, *Error) {
+	if l.isAtEnd() {
+		return nilByte, nil
+	}
+	c := l.advance()
+	switch c {
+	case 'n':
+		return '\n', nil
+	case 't':
+		return '\t', nil
+	case 'r':
+		return '\r', nil
+	case '0':
+		return nilByte, nil
+	case '\\', '"', '$':
+		return c, nil
+	case 'u':
+		return l.unicodeEscape()
+	}
+	return c, l.createError(fmt.Sprintf("Unexpected escape character: %c.", c))
+}
+
+// Attempt to consume a '{XXXX}' unicode code point following a '\u' in a
+// string.
+func (l *Lexer) unicodeEscape() (rune, *Error) {
+	if !l.matches('{') {
+		return unicode.ReplacementChar, l.createError("Expected '{' after '\\u'.")
+	}
+	digits := 0
+	var r rune
+	for !l.isAtEnd() && isHexDigit(l.peek()) && digits < 6 {
+		r = r*16 + hexValue(l.advance())
+		digits++
+	}
+	if !l.matches('}') || digits == 0 || !utf8.ValidRune(r) {
+		return unicode.ReplacementChar, l.createError("Invalid unicode escape sequence.")
+	}
+	return r, nil
+}
+
+// Attempt to consume a '`command`' from the character stream. The command
+// text is passed verbatim to the shell.
+func (l *Lexer) command() (*token.Token, *Error) {
+	line, column := l.line, l.column-1
+	for !l.isAtEnd() && l.peek() != '`' {
+		if l.advance() == '\n' {
+			l.line++
+			l.column = 0
+		}
+	}
+	if l.isAtEnd() {
+		return nil, l.createError("Unterminated command.")
+	}
+	l.advance() // consume closing '`'
+	text := string(l.input[l.start+1 : l.current-1])
+	return token.New(token.Command, text, l.origin, line, column, l.current-l.start), nil
+}
+
+// Attempt to consume a '$(command)' from the character stream. Nested
+// parentheses must balance; parentheses inside quotes are ignored.
+func (l *Lexer) parenCommand() (*token.Token, *Error) {
+	line, column := l.line, l.column-2
+	depth := 0
+	var quote rune
+	for !l.isAtEnd() && (quote != 0 || depth > 0 || l.peek() != ')') {
+		c := l.advance()
+		switch {
+		case c == '\n':
+			l.line++
+			l.column = 0
+		case c == '\\' && quote != '\'' && !l.isAtEnd():
+			l.advance()
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		}
+	}
+	if l.isAtEnd() {
+		return nil, l.createError("Unterminated command.")
+	}
+	l.advance() // consume closing ')'
+	text := string(l.input[l.start+2 : l.current-1])
+	return token.New(token.Command, text, l.origin, line, column, l.current-l.start), nil
+}
+
+// Attempt to consume a '$NAME' environment variable from the character
+// stream. The lexeme is the name without the '$'.
+func (l *Lexer) envVar() *token.Token {
+	for isAlphaNumeric(l.peek()) {
+		l.advance()
+	}
+	name := string(l.input[l.start+1 : l.current])
+	length := l.current - l.start
+	return token.New(token.EnvVar, name, l.origin, l.line, l.column-length, length)
+}
+
+// Attempt to consume an '@name' function attribute from the character stream.
+// The lexeme is the name without the '@'. The body of the attributed function
+// is scanned raw.
+func (l *Lexer) attribute() *token.Token {
+	for isAlphaNumeric(l.peek()) {
+		l.advance()
+	}
+	l.attributed = true
+	name := string(l.input[l.start+1 : l.current])
+	length := l.current - l.start
+	return token.New(token.Attribute, name, l.origin, l.line, l.column-length, length)
+}
+
+// Attempt to consume the raw body of an attributed function, up to but not
+// including the '}' that closes it. Nested braces must balance; braces inside
+// quotes, '#' comments and heredocs are ignored.
+func (l *Lexer) script() (*token.Token, *Error) {
+	line, column := l.line, l.column
+	depth := 0
+	var quote rune
+	var heredocs []heredoc
+	for !l.isAtEnd() && (quote != 0 || depth > 0 || l.peek() != '}') {
+		c := l.advance()
+		switch {
+		case c == '\n':
+			l.line++
+			l.column = 0
+			// the bodies of the heredocs started on a line follow it.
+			for _, h := range heredocs {
+				l.heredocBody(h)
+			}
+			heredocs = nil
+		case c == '\\' && quote != '\'' && !l.isAtEnd():
+			l.advance()
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '<' && l.peek() == '<':
+			l.advance()
+			if h, ok := l.heredoc(); ok {
+				heredocs = append(heredocs, h)
+			}
+		case c == '#' && l.current-1 > l.start && !unicode.IsSpace(l.input[l.current-2]):
+			// a '#' within a word, e.g. '${#array[@]}', is not a comment.
+		case c == '#':
+			for !l.isAtEnd() && l.peek() != newLine {
+				l.advance()
+			}
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+		}
+	}
+	if l.isAtEnd() {
+		return nil, l.createError("Unterminated function body.")
+	}
+	text := string(l.input[l.start:l.current])
+	return token.New(token.Script, text, l.origin, line, column, l.current-l.start), nil
+}
+
+// heredoc is a here document started by a '<<' redirection in a script.
+type heredoc struct {
+	delimiter string
+	// stripTabs is set by '<<-', which strips leading tabs from the lines.
+	stripTabs bool
+}
+
+// Attempt to consume the delimiter of a heredoc following a '<<'. A here
+// string ('<<<') or a shift by a number, e.g. '$((1 << 2))', is not a heredoc.
+func (l *Lexer) heredoc() (heredoc, bool) {
+	if l.matches('<') {
+		return heredoc{}, false
+	}
+	h := heredoc{stripTabs: l.matches('-')}
+	for l.peek() == ' ' || l.peek() == '\t' {
+		l.advance()
+	}
+	c := l.peek()
+	if c == '\'' || c == '"' {
+		l.advance()
+		start := l.current
+		for !l.isAtEnd() && l.peek() != c && l.peek() != newLine {
+			l.advance()
+		}
+		h.delimiter = string(l.input[start:l.current])
+		return h, l.matches(c)
+	}
+	if !isAlpha(c) {
+		return heredoc{}, false
+	}
+	start := l.current
+	for isAlphaNumeric(l.peek()) {
+		l.advance()
+	}
+	h.delimiter = string(l.input[start:l.current])
+	return h, true
+}
+
+// Consume the lines of the body of a heredoc, up to and including the line
+// holding only its delimiter.
+func (l *Lexer) heredocBody(h heredoc) {
+	for !l.isAtEnd() {
+		start := l.current
+		for !l.isAtEnd() && l.peek() != newLine {
+			l.advance()
+		}
+		text := string(l.input[start:l.current])
+		if h.stripTabs {
+			text = strings.TrimLeft(text, "\t")
+		}
+		if l.matches(newLine) {
+			l.line++
+			l.column = 0
+		}
+		if text == h.delimiter {
+			return
+		}
+	}
+}
+
 // Attempt to consume a 'number' from the character stream.
 func (l *Lexer) number() *token.Token {
 	// consume integer component.
@@ -233,16 +581,42 @@ func (l *Lexer) identifier() *token.Token {
 		tt = token.While
 	case "for":
 		tt = token.For
+	case "break":
+		tt = token.Break
+	case "continue":
+		tt = token.Continue
 	case "return":
 		tt = token.Return
+	// Exceptions
+	case "throw":
+		tt = token.Throw
+	case "try":
+		tt = token.Try
+	case "catch":
+		tt = token.Catch
+	case "finally":
+		tt = token.Finally
 	// Declaration
 	case "var":
 		tt = token.Var
 	case "func":
 		tt = token.Func
+	case "class":
+		tt = token.Class
+	case "this":
+		tt = token.This
 	// Utility
 	case "log":
 		tt = token.Log
+	// Shell
+	case "exec":
+		tt = token.Exec
+	case "with":
+		tt = token.With
+	case "import":
+		tt = token.Import
+	case "export":
+		tt = token.Export
 	// Identifier (non-keyword)
 	default:
 		tt = token.Identifier
@@ -10,16 +10,20 @@ import (
 //
 
 func TestScanTokens_Structural(t *testing.T) {
-	input := "(){},.;"
+	input := "(){}[],.:;=>"
 	expected := []expectedToken{
 		{token.LeftParen, "(", 0, 0, 1},
 		{token.RightParen, ")", 0, 1, 1},
 		{token.LeftBrace, "{", 0, 2, 1},
 		{token.RightBrace, "}", 0, 3, 1},
-		{token.Comma, ",", 0, 4, 1},
-		{token.Dot, ".", 0, 5, 1},
-		{token.Semicolon, ";", 0, 6, 1},
-		{token.EOF, "", 0, 7, 0},
+		{token.LeftBracket, "[", 0, 4, 1},
+		{token.RightBracket, "]", 0, 5, 1},
+		{token.Comma, ",", 0, 6, 1},
+		{token.Dot, ".", 0, 7, 1},
+		{token.Colon, ":", 0, 8, 1},
+		{token.Semicolon, ";", 0, 9, 1},
+		{token.Arrow, "=>", 0, 10, 2},
+		{token.EOF, "", 0, 12, 0},
 	}
 	actual, _ := New(input).ScanTokens()
 	validateTestTokens(t, expected, actual)
@@ -38,6 +42,37 @@ func TestScanTokens_Arithmetic(t *testing.T) {
 	validateTestTokens(t, expected, actual)
 }
 
+func TestScanTokens_ArithmeticDual(t *testing.T) {
+	input := "a // b ** c % d"
+	expected := []expectedToken{
+		{token.Identifier, "a", 0, 0, 1},
+		{token.ForwardSlashDual, "//", 0, 2, 2},
+		{token.Identifier, "b", 0, 5, 1},
+		{token.StarDual, "**", 0, 7, 2},
+		{token.Identifier, "c", 0, 10, 1},
+		{token.Percent, "%", 0, 12, 1},
+		{token.Identifier, "d", 0, 14, 1},
+		{token.EOF, "", 0, 15, 0},
+	}
+	actual, _ := New(input).ScanTokens()
+	validateTestTokens(t, expected, actual)
+}
+
+func TestScanTokens_Bitwise(t *testing.T) {
+	input := "& | ^ ~ << >>"
+	expected := []expectedToken{
+		{token.Ampersand, "&", 0, 0, 1},
+		{token.Pipe, "|", 0, 2, 1},
+		{token.Caret, "^", 0, 4, 1},
+		{token.Tilde, "~", 0, 6, 1},
+		{token.LessThanDual, "<<", 0, 8, 2},
+		{token.GreaterThanDual, ">>", 0, 11, 2},
+		{token.EOF, "", 0, 13, 0},
+	}
+	actual, _ := New(input).ScanTokens()
+	validateTestTokens(t, expected, actual)
+}
+
 func TestScanTokens_Comparator(t *testing.T) {
 	input := "! != = == > >= < <="
 	expected := []expectedToken{
@@ -66,6 +101,24 @@ func TestScanTokens_SingleLineComments(t *testing.T) {
 	}
 }
 
+func TestScanTokens_TrailingComments(t *testing.T) {
+	input := "x = 1; // Commented out.\n(x) // 2"
+	expected := []expectedToken{
+		{token.Identifier, "x", 0, 0, 1},
+		{token.Equal, "=", 0, 2, 1},
+		{token.Number, "1", 0, 4, 1},
+		{token.Semicolon, ";", 0, 5, 1},
+		{token.LeftParen, "(", 1, 0, 1},
+		{token.Identifier, "x", 1, 1, 1},
+		{token.RightParen, ")", 1, 2, 1},
+		{token.ForwardSlashDual, "//", 1, 4, 2},
+		{token.Number, "2", 1, 7, 1},
+		{token.EOF, "", 1, 8, 0},
+	}
+	actual, _ := New(input).ScanTokens()
+	validateTestTokens(t, expected, actual)
+}
+
 func TestScanTokens_EscapedNewLine(t *testing.T) {
 	input := "test\n test\n  test\n"
 	expected := []expectedToken{
@@ -158,6 +211,112 @@ func TestScanTokens_UnterminatedString(t *testing.T) {
 	}
 }
 
+func TestScanTokens_StringEscapes(t *testing.T) {
+	input := `"a\tb\n" "\"q\" \\ \$" "\u{48}\u{1F600}"`
+	expected := []expectedToken{
+		{token.String, "a\tb\n", 0, 2, 6},
+		{token.String, "\"q\" \\ $", 0, 11, 11},
+		{token.String, "H\U0001F600", 0, 25, 15},
+		{token.EOF, "", 0, 40, 0},
+	}
+	actual, _ := New(input).ScanTokens()
+	validateTestTokens(t, expected, actual)
+}
+
+func TestScanTokens_MultiLineString(t *testing.T) {
+	input := "\"s1\ns2\" s3"
+	expected := []expectedToken{
+		{token.String, "s1\ns2", 0, 2, 5},
+		{token.Identifier, "s3", 1, 4, 2},
+		{token.EOF, "", 1, 6, 0},
+	}
+	actual, _ := New(input).ScanTokens()
+	validateTestTokens(t, expected, actual)
+}
+
+func TestScanTokens_StringInterpolation(t *testing.T) {
+	input := `"a${b}c${ {"d": "${e}"} }"`
+	expected := []expectedToken{
+		{token.Interpolation, "a", 0, 2, 1},
+		{token.Identifier, "b", 0, 4, 1},
+		{token.Interpolation, "c", 0, 7, 1},
+		{token.LeftBrace, "{", 0, 10, 1},
+		{token.String, "d", 0, 13, 1},
+		{token.Colon, ":", 0, 14, 1},
+		{token.Interpolation, "", 0, 18, 0},
+		{token.Identifier, "e", 0, 19, 1},
+		{token.String, "", 0, 22, 0},
+		{token.RightBrace, "}", 0, 22, 1},
+		{token.String, "", 0, 26, 0},
+		{token.EOF, "", 0, 26, 0},
+	}
+	actual, _ := New(input).ScanTokens()
+	validateTestTokens(t, expected, actual)
+}
+
+func TestScanTokens_BadStringEscapes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"\q"`, "Unexpected escape character: q."},
+		{`"\u41"`, "Expected '{' after '\\u'."},
+		{`"\u{}"`, "Invalid unicode escape sequence."},
+		{`"\u{110000}"`, "Invalid unicode escape sequence."},
+		{`"\u{41"`, "Invalid unicode escape sequence."},
+	}
+	for idx, tt := range tests {
+		actual, errs := New(tt.input).ScanTokens()
+		if len(actual) != 1 {
+			t.Fatalf("test[%d] - Wrong number of tokens. Expected=%d, Actual=%d",
+				idx, 1, len(actual))
+		}
+		if len(errs) == 0 || errs[0].Message != tt.expected {
+			t.Fatalf("test[%d] - Wrong Error. Expected=%q, Actual=%v", idx, tt.expected, errs)
+		}
+	}
+}
+
+func TestScanTokens_Command(t *testing.T) {
+	input := "`ls -l` $(echo \")\" $(date)) `a\nb`"
+	expected := []expectedToken{
+		{token.Command, "ls -l", 0, 0, 7},
+		{token.Command, "echo \")\" $(date)", 0, 8, 19},
+		{token.Command, "a\nb", 0, 28, 5},
+		{token.EOF, "", 1, 2, 0},
+	}
+	actual, _ := New(input).ScanTokens()
+	validateTestTokens(t, expected, actual)
+}
+
+func TestScanTokens_UnterminatedCommand(t *testing.T) {
+	tests := []string{"`ls", "$(ls", "$(echo (a)", "$(echo \")\""}
+	for idx, input := range tests {
+		actual, errs := New(input).ScanTokens()
+		if len(actual) != 1 {
+			t.Fatalf("test[%d] - Wrong number of tokens. Expected=%d, Actual=%d",
+				idx, 1, len(actual))
+		}
+		expectedMessage := "Unterminated command."
+		if len(errs) == 0 || errs[0].Message != expectedMessage {
+			t.Fatalf("test[%d] - Wrong Error. Expected=%q, Actual=%v", idx, expectedMessage, errs)
+		}
+	}
+}
+
+func TestScanTokens_EnvVar(t *testing.T) {
+	input := "$HOME $_x1+$PATH"
+	expected := []expectedToken{
+		{token.EnvVar, "HOME", 0, 0, 5},
+		{token.EnvVar, "_x1", 0, 6, 4},
+		{token.Plus, "+", 0, 10, 1},
+		{token.EnvVar, "PATH", 0, 11, 5},
+		{token.EOF, "", 0, 16, 0},
+	}
+	actual, _ := New(input).ScanTokens()
+	validateTestTokens(t, expected, actual)
+}
+
 func TestScanTokens_Numeric(t *testing.T) {
 	input := "12 12.34 .12"
 	expected := []expectedToken{
@@ -186,11 +345,39 @@ func TestScanTokens_Keyword_Logical(t *testing.T) {
 }
 
 func TestScanTokens_Keyword_Declaration(t *testing.T) {
-	input := "var func"
+	input := "var func class this"
 	expected := []expectedToken{
 		{token.Var, "var", 0, 0, 3},
 		{token.Func, "func", 0, 4, 4},
-		{token.EOF, "", 0, 8, 0},
+		{token.Class, "class", 0, 9, 5},
+		{token.This, "this", 0, 15, 4},
+		{token.EOF, "", 0, 19, 0},
+	}
+	actual, _ := New(input).ScanTokens()
+	validateTestTokens(t, expected, actual)
+}
+
+func TestScanTokens_Keyword_Exception(t *testing.T) {
+	input := "throw try catch finally"
+	expected := []expectedToken{
+		{token.Throw, "throw", 0, 0, 5},
+		{token.Try, "try", 0, 6, 3},
+		{token.Catch, "catch", 0, 10, 5},
+		{token.Finally, "finally", 0, 16, 7},
+		{token.EOF, "", 0, 23, 0},
+	}
+	actual, _ := New(input).ScanTokens()
+	validateTestTokens(t, expected, actual)
+}
+
+func TestScanTokens_Keyword_Loop(t *testing.T) {
+	input := "while for break continue"
+	expected := []expectedToken{
+		{token.While, "while", 0, 0, 5},
+		{token.For, "for", 0, 6, 3},
+		{token.Break, "break", 0, 10, 5},
+		{token.Continue, "continue", 0, 16, 8},
+		{token.EOF, "", 0, 24, 0},
 	}
 	actual, _ := New(input).ScanTokens()
 	validateTestTokens(t, expected, actual)
@@ -206,6 +393,19 @@ func TestScanTokens_Keyword_Utility(t *testing.T) {
 	validateTestTokens(t, expected, actual)
 }
 
+func TestScanTokens_Keyword_Shell(t *testing.T) {
+	input := "exec with import export"
+	expected := []expectedToken{
+		{token.Exec, "exec", 0, 0, 4},
+		{token.With, "with", 0, 5, 4},
+		{token.Import, "import", 0, 10, 6},
+		{token.Export, "export", 0, 17, 6},
+		{token.EOF, "", 0, 23, 0},
+	}
+	actual, _ := New(input).ScanTokens()
+	validateTestTokens(t, expected, actual)
+}
+
 func TestScanTokens_Keyword_Identifier(t *testing.T) {
 	input := "some_identifer a_ducker trousers59 _id _23"
 	expected := []expectedToken{
@@ -243,6 +443,83 @@ func TestScanTokens_SimpleFunction(t *testing.T) {
 	validateTestTokens(t, expected, actual)
 }
 
+func TestScanTokens_BashFunction(t *testing.T) {
+	input := "func @bash f(a) { echo \"}\" ${#1} # }\n if x; then { y; }; fi } f(1);"
+	expected := []expectedToken{
+		{token.Func, "func", 0, 0, 4},
+		{token.Attribute, "bash", 0, 5, 5},
+		{token.Identifier, "f", 0, 11, 1},
+		{token.LeftParen, "(", 0, 12, 1},
+		{token.Identifier, "a", 0, 13, 1},
+		{token.RightParen, ")", 0, 14, 1},
+		{token.LeftBrace, "{", 0, 16, 1},
+		{token.Script, " echo \"}\" ${#1} # }\n if x; then { y; }; fi ", 0, 17, 43},
+		{token.RightBrace, "}", 1, 23, 1},
+		{token.Identifier, "f", 1, 25, 1},
+		{token.LeftParen, "(", 1, 26, 1},
+		{token.Number, "1", 1, 27, 1},
+		{token.RightParen, ")", 1, 28, 1},
+		{token.Semicolon, ";", 1, 29, 1},
+		{token.EOF, "", 1, 30, 0},
+	}
+	actual, _ := New(input).ScanTokens()
+	validateTestTokens(t, expected, actual)
+}
+
+func TestScanTokens_BashFunctionHeredoc(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"func @bash f() { cat <<EOF\n}\nEOF\n} f();", " cat <<EOF\n}\nEOF\n"},
+		{"func @bash f() { cat <<-'END' | tr a b\n\t{ }\n\tEND\n}", " cat <<-'END' | tr a b\n\t{ }\n\tEND\n"},
+		{"func @bash f() { cat <<A <<\"B\"\n}\nA\n{\nB\n}", " cat <<A <<\"B\"\n}\nA\n{\nB\n"},
+		{"func @bash f() { echo $((1 << 2)); cat <<< \"}\" }", " echo $((1 << 2)); cat <<< \"}\" "},
+	}
+	for idx, tt := range tests {
+		actual, errs := New(tt.input).ScanTokens()
+		if len(errs) > 0 {
+			t.Fatalf("test[%d] - Unexpected errors: %v", idx, errs)
+		}
+		script, brace := actual[6], actual[7]
+		if script.Type != token.Script || script.Lexeme != tt.expected {
+			t.Fatalf("test[%d] - Wrong script. Expected=%q, Actual=%q", idx, tt.expected, script.Lexeme)
+		}
+		if brace.Type != token.RightBrace {
+			t.Fatalf("test[%d] - Wrong token.Type. Expected=%q, Actual=%q", idx, token.RightBrace, brace.Type)
+		}
+	}
+}
+
+func TestScanTokens_UnknownAttribute(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"func @1x f() {}", "Unknown attribute '@1x'."},
+		{"func @ f() {}", "Unknown attribute '@'."},
+	}
+	for idx, tt := range tests {
+		_, errs := New(tt.input).ScanTokens()
+		if len(errs) == 0 || errs[0].Message != tt.expected {
+			t.Fatalf("test[%d] - Wrong Error. Expected=%q, Actual=%v", idx, tt.expected, errs)
+		}
+	}
+}
+
+func TestScanTokens_UnterminatedBashFunction(t *testing.T) {
+	tests := []string{
+		"func @bash f() {", "func @bash f() { { }", "func @bash f() { echo \"} }", "func @bash f() { cat <<EOF\n}\n",
+	}
+	for idx, input := range tests {
+		_, errs := New(input).ScanTokens()
+		expectedMessage := "Unterminated function body."
+		if len(errs) == 0 || errs[0].Message != expectedMessage {
+			t.Fatalf("test[%d] - Wrong Error. Expected=%q, Actual=%v", idx, expectedMessage, errs)
+		}
+	}
+}
+
 // Support Functions ==========================================================
 //
 
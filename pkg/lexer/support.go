@@ -3,6 +3,8 @@ package lexer
 import (
 	"regexp"
 	"unicode"
+
+	"github.com/templecloud/glu/pkg/token"
 )
 
 // Support Functions ===========================================================
@@ -23,7 +25,38 @@ func isDigit(c rune) bool {
 	return unicode.IsDigit(c)
 }
 
+// Return true if the input is a hexadecimal digit; false otherwise.
+func isHexDigit(c rune) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
+
+// Return the value of a hexadecimal digit.
+func hexValue(c rune) rune {
+	switch {
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10
+	}
+	return c - '0'
+}
+
 // Return true if the input is alphanumeric; false otherwise.
 func isAlphaNumeric(c rune) bool {
 	return isDigit(c) || isAlpha(c)
-}
\ No newline at end of file
+}
+
+// Return true if the token can end an operand; false otherwise. A '//'
+// following an operand is integer division; otherwise it starts a comment.
+func isOperand(t *token.Token) bool {
+	if t == nil {
+		return false
+	}
+	switch t.Type {
+	case token.Identifier, token.Number, token.String, token.Command, token.EnvVar,
+		token.RightParen, token.RightBracket,
+		token.True, token.False, token.Nil, token.This:
+		return true
+	}
+	return false
+}
@@ -46,6 +46,44 @@ func (p *Parser) peek() *token.Token {
 	return p.tokens[p.current]
 }
 
+func (p *Parser) peekAt(offset int) *token.Token {
+	if p.current+offset >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.current+offset]
+}
+
+// isMapLiteral returns true if the current '{' opens a map literal rather than
+// a block, i.e. it is followed by a literal key and a ':'.
+func (p *Parser) isMapLiteral() bool {
+	switch p.peekAt(1).Type {
+	case token.String, token.Number:
+		return p.peekAt(2).Type == token.Colon
+	}
+	return false
+}
+
+// isArrow returns true if the current '(' opens the parameter list of an arrow
+// function, i.e. it is followed by identifiers separated by commas, a ')' and
+// a '=>'.
+func (p *Parser) isArrow() bool {
+	offset := 1
+	if p.peekAt(offset).Type != token.RightParen {
+		for {
+			if p.peekAt(offset).Type != token.Identifier {
+				return false
+			}
+			offset++
+			if p.peekAt(offset).Type != token.Comma {
+				break
+			}
+			offset++
+		}
+	}
+	return p.peekAt(offset).Type == token.RightParen &&
+		p.peekAt(offset+1).Type == token.Arrow
+}
+
 func (p *Parser) previous() *token.Token {
 	return p.tokens[p.current-1]
 }
@@ -60,12 +98,21 @@ func (p *Parser) synchronize() {
 			return
 		}
 		switch p.peek().Type {
+		case token.Class:
 		case token.Func:
 		case token.Var:
 		case token.For:
+		case token.Break:
+		case token.Continue:
 		case token.If:
 		case token.While:
 		case token.Log:
+		case token.Exec:
+		case token.With:
+		case token.Import:
+		case token.Export:
+		case token.Throw:
+		case token.Try:
 		case token.Return:
 			return
 		}
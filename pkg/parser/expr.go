@@ -2,6 +2,8 @@ package parser
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/templecloud/glu/pkg/ast"
 	"github.com/templecloud/glu/pkg/token"
@@ -19,6 +21,12 @@ func (p *Parser) assignment() ast.Expr {
 		case *ast.VarExpr:
 			name := v.Name
 			return ast.NewAssign(name, value)
+		case *ast.Get:
+			return ast.NewSet(v.Object, v.Name, value)
+		case *ast.Index:
+			return ast.NewSetIndex(v.Object, v.Bracket, v.Index, value)
+		case *ast.EnvVar:
+			return ast.NewSetEnv(v.Name, value)
 		default:
 			err := NewError(equals, "Invalid assignment target.")
 			fmt.Printf("Parse Error: %+v\n", err)
@@ -67,6 +75,11 @@ func (p *Parser) call() ast.Expr {
 	for true {
 		if p.match(token.LeftParen) {
 			expr = p._finishCall(expr).(ast.Expr)
+		} else if p.match(token.Dot) {
+			name := p.consume(token.Identifier, "Expected property name after '.'.")
+			expr = ast.NewGet(expr, name)
+		} else if p.match(token.LeftBracket) {
+			expr = p._finishIndex(expr)
 		} else {
 			break
 		}
@@ -90,9 +103,67 @@ func (p *Parser) _finishCall(callee ast.Expr) interface{} {
 	return ast.NewCall(callee, paren, arguments)
 }
 
+func (p *Parser) _finishIndex(object ast.Expr) ast.Expr {
+	bracket := p.previous()
+	var start ast.Expr
+	if !p.check(token.Colon) {
+		start = p.expression()
+	}
+	if p.match(token.Colon) {
+		var end ast.Expr
+		if !p.check(token.RightBracket) {
+			end = p.expression()
+		}
+		p.consume(token.RightBracket, "Expected ']' after slice.")
+		return ast.NewSlice(object, bracket, start, end)
+	}
+	p.consume(token.RightBracket, "Expected ']' after index.")
+	return ast.NewIndex(object, bracket, start)
+}
+
 func (p *Parser) comparison() ast.Expr {
-	expr := p.addition()
+	expr := p.bitwiseOr()
 	for p.match(token.GreaterThan, token.GreaterThanOrEqual, token.LessThan, token.LessThanOrEqual) {
+		operator := p.previous()
+		right := p.bitwiseOr()
+		expr = ast.NewBinary(expr, operator, right)
+	}
+	return expr
+}
+
+func (p *Parser) bitwiseOr() ast.Expr {
+	expr := p.bitwiseXor()
+	for p.match(token.Pipe) {
+		operator := p.previous()
+		right := p.bitwiseXor()
+		expr = ast.NewBinary(expr, operator, right)
+	}
+	return expr
+}
+
+func (p *Parser) bitwiseXor() ast.Expr {
+	expr := p.bitwiseAnd()
+	for p.match(token.Caret) {
+		operator := p.previous()
+		right := p.bitwiseAnd()
+		expr = ast.NewBinary(expr, operator, right)
+	}
+	return expr
+}
+
+func (p *Parser) bitwiseAnd() ast.Expr {
+	expr := p.shift()
+	for p.match(token.Ampersand) {
+		operator := p.previous()
+		right := p.shift()
+		expr = ast.NewBinary(expr, operator, right)
+	}
+	return expr
+}
+
+func (p *Parser) shift() ast.Expr {
+	expr := p.addition()
+	for p.match(token.LessThanDual, token.GreaterThanDual) {
 		operator := p.previous()
 		right := p.addition()
 		expr = ast.NewBinary(expr, operator, right)
@@ -112,7 +183,7 @@ func (p *Parser) addition() ast.Expr {
 
 func (p *Parser) multiplication() ast.Expr {
 	expr := p.unary()
-	for p.match(token.ForwardSlash, token.Star) {
+	for p.match(token.ForwardSlash, token.ForwardSlashDual, token.Star, token.Percent) {
 		operator := p.previous()
 		right := p.unary()
 		expr = ast.NewBinary(expr, operator, right)
@@ -121,12 +192,24 @@ func (p *Parser) multiplication() ast.Expr {
 }
 
 func (p *Parser) unary() ast.Expr {
-	if p.match(token.Not, token.Minus) {
+	if p.match(token.Not, token.Minus, token.Tilde) {
 		operator := p.previous()
 		right := p.unary()
 		return ast.NewUnary(operator, right)
 	}
-	return p.call()
+	return p.exponent()
+}
+
+// exponent is right-associative and binds tighter than unary operators on
+// its left, so '-2 ** 2' is '-(2 ** 2)' and '2 ** -1' is '2 ** (-1)'.
+func (p *Parser) exponent() ast.Expr {
+	expr := p.call()
+	if p.match(token.StarDual) {
+		operator := p.previous()
+		right := p.unary()
+		expr = ast.NewBinary(expr, operator, right)
+	}
+	return expr
 }
 
 func (p *Parser) primary() ast.Expr {
@@ -139,16 +222,149 @@ func (p *Parser) primary() ast.Expr {
 	if p.match(token.Nil) {
 		return ast.NewLiteral(p.previous().Type, nil)
 	}
-	if p.match(token.Number, token.String) {
+	if p.match(token.Number) {
+		return p.numeral()
+	}
+	if p.match(token.String) {
 		return ast.NewLiteral(p.previous().Type, p.previous().Lexeme)
 	}
+	if p.match(token.Interpolation) {
+		return p.interpolation()
+	}
+	if p.match(token.Command) {
+		return ast.NewCommand(p.previous())
+	}
+	if p.match(token.EnvVar) {
+		return ast.NewEnvVar(p.previous())
+	}
+	if p.match(token.This) {
+		return ast.NewThis(p.previous())
+	}
 	if p.match(token.Identifier) {
 		return ast.NewVarExpr(p.previous())
 	}
+	if p.match(token.Func) {
+		return p.lambda()
+	}
+	if p.check(token.LeftParen) && p.isArrow() {
+		p.advance()
+		return p.arrow()
+	}
 	if p.match(token.LeftParen) {
 		expr := p.expression()
 		p.consume(token.RightParen, "Expected ')' after expression.")
 		return ast.NewGrouping(expr)
 	}
+	if p.match(token.LeftBracket) {
+		return p.list()
+	}
+	// In expression position a brace always opens a map literal.
+	if p.match(token.LeftBrace) {
+		return p.mapLiteral()
+	}
 	panic(NewError(p.tokens[p.current], "Token failed to match any rule."))
 }
+
+// interpolation parses the alternating string segments and expressions of an
+// interpolated string into a concatenation. Empty segments are omitted.
+func (p *Parser) interpolation() ast.Expr {
+	start := p.previous()
+	var parts []ast.Expr
+	segment := start
+	for {
+		if segment.Lexeme != "" {
+			parts = append(parts, ast.NewLiteral(token.String, segment.Lexeme))
+		}
+		if segment.Type == token.String {
+			break
+		}
+		parts = append(parts, p.expression())
+		if p.match(token.Interpolation) {
+			segment = p.previous()
+		} else {
+			segment = p.consume(token.String, "Expected '}' after interpolated expression.")
+		}
+	}
+	return ast.NewConcat(start, parts)
+}
+
+func (p *Parser) list() ast.Expr {
+	bracket := p.previous()
+	var elements []ast.Expr
+	for !p.check(token.RightBracket) && !p.isAtEnd() {
+		elements = append(elements, p.expression())
+		if !p.match(token.Comma) {
+			break
+		}
+	}
+	p.consume(token.RightBracket, "Expected ']' after list elements.")
+	return ast.NewList(bracket, elements)
+}
+
+func (p *Parser) mapLiteral() ast.Expr {
+	brace := p.previous()
+	var keys []ast.Expr
+	var values []ast.Expr
+	for !p.check(token.RightBrace) && !p.isAtEnd() {
+		keys = append(keys, p.expression())
+		p.consume(token.Colon, "Expected ':' after map key.")
+		values = append(values, p.expression())
+		if !p.match(token.Comma) {
+			break
+		}
+	}
+	p.consume(token.RightBrace, "Expected '}' after map entries.")
+	return ast.NewMap(brace, keys, values)
+}
+
+// numeral parses a number literal. Numbers with a fractional component are
+// floats, all other numbers are integers.
+func (p *Parser) numeral() ast.Expr {
+	number := p.previous()
+	if strings.Contains(number.Lexeme, ".") {
+		value, err := strconv.ParseFloat(number.Lexeme, 64)
+		if err != nil {
+			panic(NewError(number, "Invalid float literal."))
+		}
+		return ast.NewNumeral(number, value)
+	}
+	value, err := strconv.ParseInt(number.Lexeme, 10, 64)
+	if err != nil {
+		panic(NewError(number, "Invalid integer literal."))
+	}
+	return ast.NewNumeral(number, value)
+}
+
+// lambda parses an anonymous function, e.g. 'func (a) { return a + 1; }'.
+func (p *Parser) lambda() ast.Expr {
+	keyword := p.previous()
+	p.consume(token.LeftParen, "Expected '(' after 'func'.")
+	parameters := p.parameters()
+	p.consume(token.LeftBrace, "Expected '{' before lambda body.")
+	body := p.functionBody()
+	return ast.NewLambda(ast.NewFnStmt(lambdaName(keyword), parameters, body))
+}
+
+// arrow parses a short form anonymous function, e.g. '(a) => a + 1'. The body
+// is either a block or a single expression whose value is returned.
+func (p *Parser) arrow() ast.Expr {
+	paren := p.previous()
+	parameters := p.parameters()
+	arrow := p.consume(token.Arrow, "Expected '=>' after lambda parameters.")
+	var body []ast.Stmt
+	if p.check(token.LeftBrace) && !p.isMapLiteral() {
+		p.advance()
+		body = p.functionBody()
+	} else {
+		keyword := token.New(
+			token.Return, "return", arrow.Origin, arrow.Line, arrow.Column, arrow.Length)
+		body = []ast.Stmt{ast.NewReturn(keyword, p.expression())}
+	}
+	return ast.NewLambda(ast.NewFnStmt(lambdaName(paren), parameters, body))
+}
+
+// lambdaName creates the name token for an anonymous function declared at the
+// specified token.
+func lambdaName(at *token.Token) *token.Token {
+	return token.New(token.Identifier, "lambda", at.Origin, at.Line, at.Column, at.Length)
+}
@@ -21,6 +21,19 @@ func TestParse_ExprStmt(t *testing.T) {
 		{"-123 * 123;", "(#es (* (- 123) 123))"},
 		{"(-123 * 123);", "(#es (#g (* (- 123) 123)))"},
 		{"(-123 * 123) / (123 - 123);", "(#es (/ (#g (* (- 123) 123)) (#g (- 123 123))))"},
+		{"1.5 + 2;", "(#es (+ 1.5 2))"},
+		{"2.0 * 3;", "(#es (* 2.0 3))"},
+		{"7 % 2 + 1;", "(#es (+ (% 7 2) 1))"},
+		{"7 // 2 * 3;", "(#es (* (// 7 2) 3))"},
+		{"2 ** 3 ** 2;", "(#es (** 2 (** 3 2)))"},
+		{"-2 ** 2;", "(#es (- (** 2 2)))"},
+		{"2 ** -1;", "(#es (** 2 (- 1)))"},
+		{"2 * 3 ** 2;", "(#es (* 2 (** 3 2)))"},
+		{"~1 & 2;", "(#es (& (~ 1) 2))"},
+		{"1 | 2 ^ 3 & 4;", "(#es (| 1 (^ 2 (& 3 4))))"},
+		{"1 << 2 + 3;", "(#es (<< 1 (+ 2 3)))"},
+		{"1 | 2 == 3;", "(#es (== (| 1 2) 3))"},
+		{"\"a\" + b | sh(\"sort\") | sh(\"uniq\");", "(#es (| (| (+ \"a\" b) (#call-expr sh(\"sort\"))) (#call-expr sh(\"uniq\"))))"},
 	}
 	for idx, tt := range tests {
 		l := lexer.New(tt.input)
@@ -43,6 +56,7 @@ func TestParseError_ExprStmt(t *testing.T) {
 		{"1 + 1", "Expected ';' after expression."},
 		{"(1 + ", "Token failed to match any rule."},
 		{"(1 + 1", "Expected ')' after expression."},
+		{"99999999999999999999;", "Invalid integer literal."},
 	}
 	for idx, tt := range tests {
 		l := lexer.New(tt.input)
@@ -124,6 +138,311 @@ func TestParseError_CallExpr(t *testing.T) {
 	}
 }
 
+func TestParse_CommandExpr(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"`ls -l`;", "(#es (#cmd `ls -l`))"},
+		{"$(ls -l);", "(#es (#cmd `ls -l`))"},
+		{"var x = `pwd` + \"/bin\";", "(#vs x = (+ (#cmd `pwd`) \"/bin\"))"},
+		{"log $(echo $(date));", "(#ls (#cmd `echo $(date)`))"},
+	}
+	for idx, tt := range tests {
+		l := lexer.New(tt.input)
+		tokens, _ := l.ScanTokens()
+		p := New(tokens)
+		expr := p.Parse()
+		if len(expr) < 1 {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%v", idx, tt.expected, nil)
+		}
+		printer := ast.Printer{}
+		actual := printer.Print(expr[0])
+		if tt.expected != actual {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actual)
+		}
+	}
+}
+
+func TestParse_ConcatExpr(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"\"Hello ${name}!\";", "(#es (#concat \"Hello \" name \"!\"))"},
+		{"\"${a}${b}\";", "(#es (#concat a b))"},
+		{"\"${1 + 2} = 3\";", "(#es (#concat (+ 1 2) \" = 3\"))"},
+		{"\"a${\"b${c}\"}\";", "(#es (#concat \"a\" (#concat \"b\" c)))"},
+	}
+	for idx, tt := range tests {
+		l := lexer.New(tt.input)
+		tokens, _ := l.ScanTokens()
+		p := New(tokens)
+		expr := p.Parse()
+		if len(expr) < 1 {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%v", idx, tt.expected, nil)
+		}
+		printer := ast.Printer{}
+		actual := printer.Print(expr[0])
+		if tt.expected != actual {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actual)
+		}
+	}
+}
+
+func TestParseError_ConcatExpr(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"\"a${b c}\";", "Expected '}' after interpolated expression."},
+		{"\"a${b\";", "Expected '}' after interpolated expression."},
+	}
+	for idx, tt := range tests {
+		l := lexer.New(tt.input)
+		tokens, _ := l.ScanTokens()
+		p := New(tokens)
+		p.Parse()
+		actualErrorMessage := p.Errors[0].message
+		if tt.expected != actualErrorMessage {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actualErrorMessage)
+		}
+	}
+}
+
+func TestParse_EnvVarExpr(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"$HOME;", "(#es $HOME)"},
+		{"$HOME + \"/bin\";", "(#es (+ $HOME \"/bin\"))"},
+		{"$PATH = \"/bin:\" + $PATH;", "(#es (#set-env $PATH = (+ \"/bin:\" $PATH)))"},
+		{"$A = $B = nil;", "(#es (#set-env $A = (#set-env $B = nil)))"},
+	}
+	for idx, tt := range tests {
+		l := lexer.New(tt.input)
+		tokens, _ := l.ScanTokens()
+		p := New(tokens)
+		expr := p.Parse()
+		if len(expr) < 1 {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%v", idx, tt.expected, nil)
+		}
+		printer := ast.Printer{}
+		actual := printer.Print(expr[0])
+		if tt.expected != actual {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actual)
+		}
+	}
+}
+
+func TestParse_GetExpr(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"point.x;", "(#es (#get point.x))"},
+		{"a.b.c;", "(#es (#get (#get a.b).c))"},
+		{"point.add(1).x;", "(#es (#get (#call-expr (#get point.add)(1)).x))"},
+		{"point.x = 1;", "(#es (#set point.x = 1))"},
+		{"a.b.c = a.b;", "(#es (#set (#get a.b).c = (#get a.b)))"},
+	}
+	for idx, tt := range tests {
+		l := lexer.New(tt.input)
+		tokens, _ := l.ScanTokens()
+		p := New(tokens)
+		expr := p.Parse()
+		if len(expr) < 1 {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%v", idx, tt.expected, nil)
+		}
+		printer := ast.Printer{}
+		actual := printer.Print(expr[0])
+		if tt.expected != actual {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actual)
+		}
+	}
+}
+
+func TestParseError_GetExpr(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"point.;", "Expected property name after '.'."},
+		{"point.1;", "Expected property name after '.'."},
+	}
+	for idx, tt := range tests {
+		l := lexer.New(tt.input)
+		tokens, _ := l.ScanTokens()
+		p := New(tokens)
+		p.Parse()
+		actualErrorMessage := p.Errors[0].message
+		if tt.expected != actualErrorMessage {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actualErrorMessage)
+		}
+	}
+}
+
+func TestParse_LambdaExpr(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"var f = func () { log 1; };", "(#vs f = (#lambda () { (#ls 1) }))"},
+		{"var f = func (a, b) { return a + b; };", "(#vs f = (#lambda (a, b) { (return (+ a b)) }))"},
+		{"apply(func (x) { return x; }, 1);", "(#es (#call-expr apply((#lambda (x) { (return x) }), 1)))"},
+		{"var f = () => 1;", "(#vs f = (#lambda () { (return 1) }))"},
+		{"var f = (a) => a + 1;", "(#vs f = (#lambda (a) { (return (+ a 1)) }))"},
+		{"var f = (a, b) => { log a; return b; };", "(#vs f = (#lambda (a, b) { (#ls a); (return b) }))"},
+		{"var f = (a) => {\"k\": a};", "(#vs f = (#lambda (a) { (return (#map {\"k\": a})) }))"},
+		{"var f = (a) => (b) => a + b;", "(#vs f = (#lambda (a) { (return (#lambda (b) { (return (+ a b)) })) }))"},
+		{"func (a) { log a; }(1);", "(#es (#call-expr (#lambda (a) { (#ls a) })(1)))"},
+		{"func () { return 1; };", "(#es (#lambda () { (return 1) }))"},
+		{"(a);", "(#es (#g a))"},
+	}
+	for idx, tt := range tests {
+		l := lexer.New(tt.input)
+		tokens, _ := l.ScanTokens()
+		p := New(tokens)
+		expr := p.Parse()
+		if len(expr) < 1 {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%v", idx, tt.expected, nil)
+		}
+		printer := ast.Printer{}
+		actual := printer.Print(expr[0])
+		if tt.expected != actual {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actual)
+		}
+	}
+}
+
+func TestParseError_LambdaExpr(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"var f = func { };", "Expected '(' after 'func'."},
+		{"var f = func (a { };", "Expected ')' after arguments."},
+		{"var f = func (a) return a;", "Expected '{' before lambda body."},
+	}
+	for idx, tt := range tests {
+		l := lexer.New(tt.input)
+		tokens, _ := l.ScanTokens()
+		p := New(tokens)
+		p.Parse()
+		actualErrorMessage := p.Errors[0].message
+		if tt.expected != actualErrorMessage {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actualErrorMessage)
+		}
+	}
+}
+
+func TestParse_ListExpr(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[];", "(#es (#list []))"},
+		{"[1, \"a\", b];", "(#es (#list [1, \"a\", b]))"},
+		{"[1, 2,];", "(#es (#list [1, 2]))"},
+		{"[[1], [2 + 3]];", "(#es (#list [(#list [1]), (#list [(+ 2 3)])]))"},
+		{"xs[0];", "(#es (#index xs[0]))"},
+		{"xs[-1];", "(#es (#index xs[(- 1)]))"},
+		{"xs[0][1];", "(#es (#index (#index xs[0])[1]))"},
+		{"f()[0];", "(#es (#index (#call-expr f())[0]))"},
+		{"xs[0] = 1;", "(#es (#set-index xs[0] = 1))"},
+		{"xs[1:2];", "(#es (#slice xs[1:2]))"},
+		{"xs[1:];", "(#es (#slice xs[1:]))"},
+		{"xs[:2];", "(#es (#slice xs[:2]))"},
+		{"xs[:];", "(#es (#slice xs[:]))"},
+	}
+	for idx, tt := range tests {
+		l := lexer.New(tt.input)
+		tokens, _ := l.ScanTokens()
+		p := New(tokens)
+		expr := p.Parse()
+		if len(expr) < 1 {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%v", idx, tt.expected, nil)
+		}
+		printer := ast.Printer{}
+		actual := printer.Print(expr[0])
+		if tt.expected != actual {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actual)
+		}
+	}
+}
+
+func TestParseError_ListExpr(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[1, 2;", "Expected ']' after list elements."},
+		{"xs[0;", "Expected ']' after index."},
+		{"xs[0:1;", "Expected ']' after slice."},
+	}
+	for idx, tt := range tests {
+		l := lexer.New(tt.input)
+		tokens, _ := l.ScanTokens()
+		p := New(tokens)
+		p.Parse()
+		actualErrorMessage := p.Errors[0].message
+		if tt.expected != actualErrorMessage {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actualErrorMessage)
+		}
+	}
+}
+
+func TestParse_MapExpr(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"var m = {};", "(#vs m = (#map {}))"},
+		{"var m = {\"a\": 1, \"b\": [2]};", "(#vs m = (#map {\"a\": 1, \"b\": (#list [2])}))"},
+		{"var m = {k: v, 1: {\"c\": 2},};", "(#vs m = (#map {k: v, 1: (#map {\"c\": 2})}))"},
+		{"{\"a\": 1};", "(#es (#map {\"a\": 1}))"},
+		{"{ \"a\"; }", "(#bs (#es \"a\"))"},
+		{"{}", "(#bs)"},
+		{"m[\"a\"];", "(#es (#index m[\"a\"]))"},
+		{"m[\"a\"] = 1;", "(#es (#set-index m[\"a\"] = 1))"},
+	}
+	for idx, tt := range tests {
+		l := lexer.New(tt.input)
+		tokens, _ := l.ScanTokens()
+		p := New(tokens)
+		expr := p.Parse()
+		if len(expr) < 1 {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%v", idx, tt.expected, nil)
+		}
+		printer := ast.Printer{}
+		actual := printer.Print(expr[0])
+		if tt.expected != actual {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actual)
+		}
+	}
+}
+
+func TestParseError_MapExpr(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"var m = {\"a\" 1};", "Expected ':' after map key."},
+		{"var m = {\"a\": 1;", "Expected '}' after map entries."},
+	}
+	for idx, tt := range tests {
+		l := lexer.New(tt.input)
+		tokens, _ := l.ScanTokens()
+		p := New(tokens)
+		p.Parse()
+		actualErrorMessage := p.Errors[0].message
+		if tt.expected != actualErrorMessage {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actualErrorMessage)
+		}
+	}
+}
+
 func TestParse_LogicalExpr(t *testing.T) {
 	tests := []struct {
 		input    string
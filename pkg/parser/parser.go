@@ -13,6 +13,10 @@ type Parser struct {
 	tokens  []*token.Token
 	Errors  []*Error
 	current int
+	// loopDepth is the number of loops enclosing the current statement.
+	loopDepth int
+	// blockDepth is the number of blocks enclosing the current statement.
+	blockDepth int
 }
 
 // New creates a Parser from the specified set of tokens.
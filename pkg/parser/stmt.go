@@ -12,24 +12,127 @@ import (
 
 func (p *Parser) blockStatement() []ast.Stmt {
 	var stmts []ast.Stmt
+	p.blockDepth++
 	for !p.check(token.RightBrace) && !p.isAtEnd() {
 		stmts = append(stmts, p.declaration())
 	}
+	p.blockDepth--
 	p.consume(token.RightBrace, "Expected '}' after block.")
 	return stmts
 }
 
+func (p *Parser) breakStatement() ast.Stmt {
+	keyword := p.previous()
+	if p.loopDepth == 0 {
+		panic(NewError(keyword, "Cannot use 'break' outside of a loop."))
+	}
+	p.consume(token.Semicolon, "Expected ';' after 'break'.")
+	return ast.NewBreakStmt(keyword)
+}
+
+func (p *Parser) classDeclaration() ast.Stmt {
+	name := p.consume(token.Identifier, "Expected class name.")
+	p.consume(token.LeftBrace, "Expected '{' before class body.")
+	var fields []*ast.VariableStmt
+	var methods []*ast.FnStmt
+	for !p.check(token.RightBrace) && !p.isAtEnd() {
+		if p.match(token.Var) {
+			fields = append(fields, p.varDeclaration().(*ast.VariableStmt))
+			continue
+		}
+		// The 'func' keyword is optional for methods.
+		p.match(token.Func)
+		methods = append(methods, p.fnStatement("method").(*ast.FnStmt))
+	}
+	p.consume(token.RightBrace, "Expected '}' after class body.")
+	return ast.NewClassStmt(name, fields, methods)
+}
+
+func (p *Parser) continueStatement() ast.Stmt {
+	keyword := p.previous()
+	if p.loopDepth == 0 {
+		panic(NewError(keyword, "Cannot use 'continue' outside of a loop."))
+	}
+	p.consume(token.Semicolon, "Expected ';' after 'continue'.")
+	return ast.NewContinueStmt(keyword)
+}
+
 func (p *Parser) declaration() ast.Stmt {
 	// trjl: synchronise here instead?
-	if p.match(token.Func) {
+	if p.match(token.Class) {
+		return p.classDeclaration()
+	}
+	// A 'func' followed by '(' starts a lambda, e.g. 'func (a) { ... }(1);',
+	// so the statement is an expression statement.
+	if p.check(token.Func) && p.peekAt(1).Type != token.LeftParen {
+		p.advance()
 		return p.fnStatement("function")
 	}
+	if p.match(token.Import) {
+		return p.importDeclaration()
+	}
+	if p.match(token.Export) {
+		return p.exportDeclaration()
+	}
 	if p.match(token.Var) {
 		return p.varDeclaration()
 	}
 	return p.statement()
 }
 
+// execStatement parses the comma separated program and arguments of an exec
+// statement followed by any redirections. Arguments and redirect targets are
+// parsed above comparison precedence so that '<', '>' and '>>' are free to
+// introduce redirections.
+func (p *Parser) execStatement() ast.Stmt {
+	keyword := p.previous()
+	arguments := []ast.Expr{p.addition()}
+	for p.match(token.Comma) {
+		arguments = append(arguments, p.addition())
+	}
+	var redirects []*ast.Redirect
+	for !p.check(token.Semicolon) && !p.isAtEnd() {
+		redirects = append(redirects, p.redirect())
+	}
+	p.consume(token.Semicolon, "Expected ';' after exec statement.")
+	return ast.NewExecStmt(keyword, arguments, redirects)
+}
+
+// redirect parses a single redirection; '< f', '> f', '>> f', '2> f',
+// '2>> f', '2>&1' or '>&2'.
+func (p *Parser) redirect() *ast.Redirect {
+	fd := 1
+	if p.check(token.Number) {
+		number := p.advance()
+		fd = p.fileDescriptor(number)
+		if !p.check(token.GreaterThan) && !p.check(token.GreaterThanDual) {
+			panic(NewError(p.peek(), "Expected '>' or '>>' after file descriptor."))
+		}
+	} else if p.match(token.LessThan) {
+		return ast.NewRedirect(p.previous(), 0, p.addition(), 0)
+	}
+	if !p.match(token.GreaterThan, token.GreaterThanDual) {
+		panic(NewError(p.peek(), "Expected redirection."))
+	}
+	operator := p.previous()
+	if operator.Type == token.GreaterThan && p.match(token.Ampersand) {
+		number := p.consume(token.Number, "Expected file descriptor after '>&'.")
+		return ast.NewRedirect(operator, fd, nil, p.fileDescriptor(number))
+	}
+	return ast.NewRedirect(operator, fd, p.addition(), 0)
+}
+
+// fileDescriptor returns the output file descriptor named by a number token.
+func (p *Parser) fileDescriptor(number *token.Token) int {
+	switch number.Lexeme {
+	case "1":
+		return 1
+	case "2":
+		return 2
+	}
+	panic(NewError(number, "Expected file descriptor 1 or 2."))
+}
+
 func (p *Parser) expressionStatement() ast.Stmt {
 	expr := p.expression()
 	p.consume(token.Semicolon, "Expected ';' after expression.")
@@ -37,10 +140,50 @@ func (p *Parser) expressionStatement() ast.Stmt {
 }
 
 func (p *Parser) fnStatement(kind string) ast.Stmt {
+	if p.match(token.Attribute) {
+		return p.scriptFnStatement(kind)
+	}
 	// Consume function name.
 	name := p.consume(token.Identifier, fmt.Sprintf("Expected kind %s.", kind))
 	// Consume function parameters.
 	p.consume(token.LeftParen, fmt.Sprintf("Expected '(' after kind %s.", kind))
+	parameters := p.parameters()
+	// Consume function body.
+	p.consume(token.LeftBrace, fmt.Sprintf("Expected '{' before kind %s body.", kind))
+	body := p.functionBody()
+	return ast.NewFnStmt(name, parameters, body)
+}
+
+// scriptFnStatement parses an attributed function whose body is raw script
+// text, e.g. 'func @bash name(a, b) { echo "$1 $2" }'.
+func (p *Parser) scriptFnStatement(kind string) ast.Stmt {
+	attribute := p.previous()
+	if attribute.Lexeme != "bash" {
+		msg := fmt.Sprintf("Unknown function attribute '@%s'.", attribute.Lexeme)
+		panic(NewError(attribute, msg))
+	}
+	name := p.consume(token.Identifier, fmt.Sprintf("Expected kind %s.", kind))
+	p.consume(token.LeftParen, fmt.Sprintf("Expected '(' after kind %s.", kind))
+	parameters := p.parameters()
+	p.consume(token.LeftBrace, fmt.Sprintf("Expected '{' before kind %s body.", kind))
+	script := p.consume(token.Script, fmt.Sprintf("Expected kind %s body.", kind))
+	p.consume(token.RightBrace, fmt.Sprintf("Expected '}' after kind %s body.", kind))
+	return ast.NewScriptFnStmt(attribute, name, parameters, script)
+}
+
+// functionBody parses the block of a function body. Loops enclosing the
+// function do not extend into its body.
+func (p *Parser) functionBody() []ast.Stmt {
+	enclosing := p.loopDepth
+	p.loopDepth = 0
+	body := p.blockStatement()
+	p.loopDepth = enclosing
+	return body
+}
+
+// parameters consumes a function parameter list up to and including the
+// closing ')'.
+func (p *Parser) parameters() []*token.Token {
 	var parameters []*token.Token
 	if !p.check(token.RightParen) {
 		parameters = append(parameters, p.consume(token.Identifier, "Expected parameter name."))
@@ -53,10 +196,7 @@ func (p *Parser) fnStatement(kind string) ast.Stmt {
 		}
 	}
 	p.consume(token.RightParen, "Expected ')' after arguments.")
-	// Consume function body.
-	p.consume(token.LeftBrace, fmt.Sprintf("Expected '{' before kind %s body.", kind))
-	body := p.blockStatement()
-	return ast.NewFnStmt(name, parameters, body)
+	return parameters
 }
 
 func (p *Parser) forStatement() ast.Stmt {
@@ -84,20 +224,64 @@ func (p *Parser) forStatement() ast.Stmt {
 	p.consume(token.RightParen, "Expected ')' after if condition.")
 
 	// de-sugared statement
-	body := p.statement()
-	if increment != nil {
-		body = ast.NewBlockStmt([]ast.Stmt{body, ast.NewExprStmt(increment)})
-	}
+	body := p.loopBody()
 	if condition == nil {
 		condition = ast.NewLiteral(token.True, true)
 	}
-	body = ast.NewWhileStmt(condition, body)
+	// The increment is kept separate from the body so that it still runs when
+	// the body is exited by a 'continue'.
+	body = ast.NewForWhileStmt(condition, body, increment)
 	if initializer != nil {
 		body = ast.NewBlockStmt([]ast.Stmt{initializer, body})
 	}
 	return body
 }
 
+// importDeclaration parses 'import "path" as name;' or 'import bash "path";'.
+// The 'as' and 'bash' are contextual so that they remain usable as
+// identifiers.
+func (p *Parser) importDeclaration() ast.Stmt {
+	keyword := p.previous()
+	if p.check(token.Identifier) {
+		kind := p.advance()
+		if kind.Lexeme != "bash" {
+			panic(NewError(kind, "Expected 'bash' or import path after 'import'."))
+		}
+		path := p.consume(token.String, "Expected import path string.")
+		p.consume(token.Semicolon, "Expected ';' after import.")
+		return ast.NewImportStmt(keyword, kind, path, nil)
+	}
+	path := p.consume(token.String, "Expected 'bash' or import path after 'import'.")
+	as := p.consume(token.Identifier, "Expected 'as' after import path.")
+	if as.Lexeme != "as" {
+		panic(NewError(as, "Expected 'as' after import path."))
+	}
+	alias := p.consume(token.Identifier, "Expected module name after 'as'.")
+	p.consume(token.Semicolon, "Expected ';' after import.")
+	return ast.NewImportStmt(keyword, nil, path, alias)
+}
+
+// exportDeclaration parses 'export' followed by a function, variable or class
+// declaration. Only top-level declarations can be exported.
+func (p *Parser) exportDeclaration() ast.Stmt {
+	keyword := p.previous()
+	if p.blockDepth > 0 {
+		panic(NewError(keyword, "Can only export top-level declarations."))
+	}
+	var declaration ast.Stmt
+	switch {
+	case p.match(token.Class):
+		declaration = p.classDeclaration()
+	case p.match(token.Func):
+		declaration = p.fnStatement("function")
+	case p.match(token.Var):
+		declaration = p.varDeclaration()
+	default:
+		panic(NewError(p.peek(), "Expected declaration after 'export'."))
+	}
+	return ast.NewExportStmt(keyword, declaration)
+}
+
 func (p *Parser) ifStatement() ast.Stmt {
 	p.consume(token.LeftParen, "Expect '(' after if condition.")
 	condition := p.expression()
@@ -127,13 +311,23 @@ func (p *Parser) returnStatement() ast.Stmt {
 }
 
 func (p *Parser) statement() ast.Stmt {
+	if p.match(token.Break) {
+		return p.breakStatement()
+	}
+	if p.match(token.Continue) {
+		return p.continueStatement()
+	}
+	if p.match(token.Exec) {
+		return p.execStatement()
+	}
 	if p.match(token.For) {
 		return p.forStatement()
 	}
 	if p.match(token.If) {
 		return p.ifStatement()
 	}
-	if p.match(token.LeftBrace) {
+	if p.check(token.LeftBrace) && !p.isMapLiteral() {
+		p.advance()
 		return ast.NewBlockStmt(p.blockStatement())
 	}
 	if p.match(token.Log) {
@@ -142,13 +336,58 @@ func (p *Parser) statement() ast.Stmt {
 	if p.match(token.Return) {
 		return p.returnStatement()
 	}
+	if p.match(token.Throw) {
+		return p.throwStatement()
+	}
+	if p.match(token.Try) {
+		return p.tryStatement()
+	}
 	if p.match(token.While) {
 		return p.whileStatement()
 	}
+	if p.match(token.With) {
+		return p.withStatement()
+	}
+	if p.check(token.Export) {
+		panic(NewError(p.peek(), "Can only export top-level declarations."))
+	}
 
 	return p.expressionStatement()
 }
 
+func (p *Parser) throwStatement() ast.Stmt {
+	keyword := p.previous()
+	value := p.expression()
+	p.consume(token.Semicolon, "Expected ';' after throw value.")
+	return ast.NewThrowStmt(keyword, value)
+}
+
+func (p *Parser) tryStatement() ast.Stmt {
+	keyword := p.previous()
+	p.consume(token.LeftBrace, "Expected '{' after 'try'.")
+	body := p.blockStatement()
+	// catch clause
+	var catchName *token.Token
+	var catch []ast.Stmt
+	if p.match(token.Catch) {
+		p.consume(token.LeftParen, "Expected '(' after 'catch'.")
+		catchName = p.consume(token.Identifier, "Expected catch variable name.")
+		p.consume(token.RightParen, "Expected ')' after catch variable.")
+		p.consume(token.LeftBrace, "Expected '{' before catch body.")
+		catch = p.blockStatement()
+	}
+	// finally clause
+	var finally []ast.Stmt
+	if p.match(token.Finally) {
+		p.consume(token.LeftBrace, "Expected '{' before finally body.")
+		finally = append([]ast.Stmt{}, p.blockStatement()...)
+	}
+	if catchName == nil && finally == nil {
+		panic(NewError(p.peek(), "Expected 'catch' or 'finally' after try block."))
+	}
+	return ast.NewTryStmt(keyword, body, catchName, catch, finally)
+}
+
 func (p *Parser) varDeclaration() ast.Stmt {
 	name := p.consume(token.Identifier, "Expected variable name.")
 	var initialiser ast.Expr
@@ -163,6 +402,28 @@ func (p *Parser) whileStatement() ast.Stmt {
 	p.consume(token.LeftParen, "Expected '(' after while.")
 	condition := p.expression()
 	p.consume(token.RightParen, "Expected ')' after while.")
-	body := p.statement()
+	body := p.loopBody()
 	return ast.NewWhileStmt(condition, body)
 }
+
+// withStatement parses 'with env <map> { ... }'. The 'env' is contextual so
+// that it remains usable as an identifier.
+func (p *Parser) withStatement() ast.Stmt {
+	keyword := p.previous()
+	kind := p.consume(token.Identifier, "Expected 'env' after 'with'.")
+	if kind.Lexeme != "env" {
+		panic(NewError(kind, "Expected 'env' after 'with'."))
+	}
+	env := p.expression()
+	p.consume(token.LeftBrace, "Expected '{' before with body.")
+	body := p.blockStatement()
+	return ast.NewWithStmt(keyword, env, body)
+}
+
+// loopBody parses the body statement of a loop.
+func (p *Parser) loopBody() ast.Stmt {
+	p.loopDepth++
+	body := p.statement()
+	p.loopDepth--
+	return body
+}
@@ -31,6 +31,162 @@ func TestParse_BlockStmt(t *testing.T) {
 	}
 }
 
+func TestParse_BreakStmt(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"while (true) break;", "(#ws true (#break))"},
+		{"while (true) { if (x) continue; break; }",
+			"(#ws true (#bs (#is x (#continue)) (#break)))"},
+		{"for (;;) { continue; }", "(#ws true (#bs (#continue)))"},
+		{"for (var i = 0; i < 2; i = i + 1) { continue; }",
+			"(#bs (#vs i = 0) (#ws (< i 2) (#bs (#bs (#continue)) (#es (#as i = (+ i 1))))))"},
+		{"while (true) { func f() { while (true) break; } break; }",
+			"(#ws true (#bs (#fn-stmt f() { (#ws true (#break)) }) (#break)))"},
+	}
+	for idx, tt := range tests {
+		l := lexer.New(tt.input)
+		tokens, _ := l.ScanTokens()
+		p := New(tokens)
+		expr := p.Parse()
+		if len(expr) < 1 {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%v", idx, tt.expected, nil)
+		}
+		printer := ast.Printer{}
+		actual := printer.Print(expr[0])
+		if tt.expected != actual {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actual)
+		}
+	}
+}
+
+func TestParseError_BreakStmt(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"break;", "Cannot use 'break' outside of a loop."},
+		{"continue;", "Cannot use 'continue' outside of a loop."},
+		{"{ break; }", "Cannot use 'break' outside of a loop."},
+		{"if (true) continue;", "Cannot use 'continue' outside of a loop."},
+		{"while (true) { func f() { break; } }", "Cannot use 'break' outside of a loop."},
+		{"while (true) { var f = () => { continue; }; }", "Cannot use 'continue' outside of a loop."},
+		{"while (true) break", "Expected ';' after 'break'."},
+		{"while (true) continue", "Expected ';' after 'continue'."},
+	}
+	for idx, tt := range tests {
+		l := lexer.New(tt.input)
+		tokens, _ := l.ScanTokens()
+		p := New(tokens)
+		p.Parse()
+		actualErrorMessage := p.Errors[0].message
+		if tt.expected != actualErrorMessage {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actualErrorMessage)
+		}
+	}
+}
+
+func TestParse_ClassStmt(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"class Point { }", "(#class-stmt Point { })"},
+		{"class Point { var x = 1; init(x) { this.x = x; } func getX() { return this.x; } }",
+			"(#class-stmt Point { (#vs x = 1) (#fn-stmt init(x) { (#es (#set this.x = x)) }) (#fn-stmt getX() { (return (#get this.x)) }) })"},
+	}
+	for idx, tt := range tests {
+		l := lexer.New(tt.input)
+		tokens, _ := l.ScanTokens()
+		p := New(tokens)
+		expr := p.Parse()
+		if len(expr) < 1 {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%v", idx, tt.expected, nil)
+		}
+		printer := ast.Printer{}
+		actual := printer.Print(expr[0])
+		if tt.expected != actual {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actual)
+		}
+	}
+}
+
+func TestParseError_ClassStmt(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"class { }", "Expected class name."},
+		{"class Point init() { } }", "Expected '{' before class body."},
+		{"class Point { init() { }", "Expected '}' after class body."},
+		{"class Point { 1; }", "Expected kind method."},
+	}
+	for idx, tt := range tests {
+		l := lexer.New(tt.input)
+		tokens, _ := l.ScanTokens()
+		p := New(tokens)
+		p.Parse()
+		actualErrorMessage := p.Errors[0].message
+		if tt.expected != actualErrorMessage {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actualErrorMessage)
+		}
+	}
+}
+
+func TestParse_ExecStmt(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"exec \"ls\";", "(#exec \"ls\")"},
+		{"exec \"ls\", \"-l\", dir + \"/x\";", "(#exec \"ls\" \"-l\" (+ dir \"/x\"))"},
+		{"exec \"ls\" > \"out\";", "(#exec \"ls\" > \"out\")"},
+		{"exec \"ls\" >> name + \".txt\";", "(#exec \"ls\" >> (+ name \".txt\"))"},
+		{"exec \"cat\" < \"in\" > \"out\" 2> \"err\";", "(#exec \"cat\" < \"in\" > \"out\" 2> \"err\")"},
+		{"exec \"ls\" > \"out\" 2>&1;", "(#exec \"ls\" > \"out\" 2>&1)"},
+		{"exec \"ls\" 2>> \"err\" >&2;", "(#exec \"ls\" 2>> \"err\" 1>&2)"},
+		{"exec \"echo\", 2 > 1;", "(#exec \"echo\" 2 > 1)"},
+	}
+	for idx, tt := range tests {
+		l := lexer.New(tt.input)
+		tokens, _ := l.ScanTokens()
+		p := New(tokens)
+		expr := p.Parse()
+		if len(expr) < 1 {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%v", idx, tt.expected, nil)
+		}
+		printer := ast.Printer{}
+		actual := printer.Print(expr[0])
+		if tt.expected != actual {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actual)
+		}
+	}
+}
+
+func TestParseError_ExecStmt(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"exec \"ls\"", "Expected ';' after exec statement."},
+		{"exec \"ls\" \"-l\";", "Expected redirection."},
+		{"exec \"ls\" 3> \"f\";", "Expected file descriptor 1 or 2."},
+		{"exec \"ls\" 2 \"f\";", "Expected '>' or '>>' after file descriptor."},
+		{"exec \"ls\" 2>&;", "Expected file descriptor after '>&'."},
+	}
+	for idx, tt := range tests {
+		l := lexer.New(tt.input)
+		tokens, _ := l.ScanTokens()
+		p := New(tokens)
+		p.Parse()
+		actualErrorMessage := p.Errors[0].message
+		if tt.expected != actualErrorMessage {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actualErrorMessage)
+		}
+	}
+}
+
 func TestParse_ForStmt(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -88,6 +244,9 @@ func TestParse_FnStmt(t *testing.T) {
 	}{
 		{"func sayHi(name) { log \"Hello, \"; log name; }",
 			"(#fn-stmt sayHi(name) { (#ls \"Hello, \"); (#ls name) })"},
+		{"func @bash sayHi(name) { echo \"Hello, $1\" }",
+			"(#fn-stmt @bash sayHi(name) { echo \"Hello, $1\" })"},
+		{"func @bash noop() {}", "(#fn-stmt @bash noop() {  })"},
 	}
 	for idx, tt := range tests {
 		l := lexer.New(tt.input)
@@ -108,12 +267,15 @@ func TestParseError_FnStmt(t *testing.T) {
 		expected string
 	}{
 		// {"func sayHi(name) { log \"Hello, \"; log name; }", "Expect '(' after if condition."}, // TODO
-		{"func (name) { log \"Hello, \"; log name; }", "Expected kind function."},
+		{"func (name) { log \"Hello, \"; log name; }", "Expected ';' after expression."},
 		{"func sayHi name) { log \"Hello, \"; log name; }", "Expected '(' after kind function."},
 		{"func sayHi (name,) { log \"Hello, \"; log name; }", "Expected parameter name."},
 		// {"func sayHi (a,b,c,d,e,f,g,h) { log \"Hello, \"; log name; }", "Cannot have more than 8 arguments."}, // TODO
 		{"func sayHi (name { log \"Hello, \"; log name; }", "Expected ')' after arguments."},
 		{"func sayHi (name) log \"Hello, \"; log name; }", "Expected '{' before kind function body."},
+		{"func @zsh sayHi (name) { echo $1 }", "Unknown function attribute '@zsh'."},
+		{"func @bash (name) { echo $1 }", "Expected kind function."},
+		{"func @bash sayHi (name) echo $1;", "Expected '{' before kind function body."},
 	}
 	for idx, tt := range tests {
 		l := lexer.New(tt.input)
@@ -178,6 +340,98 @@ func TestParseError_IfStmt(t *testing.T) {
 	}
 }
 
+func TestParse_ImportStmt(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"import bash \"lib/helpers.sh\";", "(#import bash \"lib/helpers.sh\")"},
+		{"var bash = 1; import bash \"a.sh\";", "(#import bash \"a.sh\")"},
+		{"import \"lib/util.glu\" as util;", "(#import \"lib/util.glu\" as util)"},
+		{"var as = 1; import \"as.glu\" as as;", "(#import \"as.glu\" as as)"},
+	}
+	for idx, tt := range tests {
+		l := lexer.New(tt.input)
+		tokens, _ := l.ScanTokens()
+		p := New(tokens)
+		stmts := p.Parse()
+		printer := ast.Printer{}
+		actual := printer.Print(stmts[len(stmts)-1])
+		if tt.expected != actual {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actual)
+		}
+	}
+}
+
+func TestParseError_ImportStmt(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"import;", "Expected 'bash' or import path after 'import'."},
+		{"import zsh \"lib.sh\";", "Expected 'bash' or import path after 'import'."},
+		{"import \"util.glu\";", "Expected 'as' after import path."},
+		{"import \"util.glu\" util;", "Expected 'as' after import path."},
+		{"import \"util.glu\" as;", "Expected module name after 'as'."},
+		{"import \"util.glu\" as util", "Expected ';' after import."},
+		{"import bash lib;", "Expected import path string."},
+		{"import bash \"lib.sh\"", "Expected ';' after import."},
+	}
+	for idx, tt := range tests {
+		l := lexer.New(tt.input)
+		tokens, _ := l.ScanTokens()
+		p := New(tokens)
+		p.Parse()
+		actualErrorMessage := p.Errors[0].message
+		if tt.expected != actualErrorMessage {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actualErrorMessage)
+		}
+	}
+}
+
+func TestParse_ExportStmt(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"export var x = 1;", "(#export (#vs x = 1))"},
+		{"export func f() {}", "(#export (#fn-stmt f() {  }))"},
+	}
+	for idx, tt := range tests {
+		l := lexer.New(tt.input)
+		tokens, _ := l.ScanTokens()
+		p := New(tokens)
+		stmts := p.Parse()
+		printer := ast.Printer{}
+		actual := printer.Print(stmts[0])
+		if tt.expected != actual {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actual)
+		}
+	}
+}
+
+func TestParseError_ExportStmt(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"export 1;", "Expected declaration after 'export'."},
+		{"{ export var x = 1; }", "Can only export top-level declarations."},
+		{"func f() { export var x = 1; }", "Can only export top-level declarations."},
+		{"if (true) export var x = 1;", "Can only export top-level declarations."},
+	}
+	for idx, tt := range tests {
+		l := lexer.New(tt.input)
+		tokens, _ := l.ScanTokens()
+		p := New(tokens)
+		p.Parse()
+		actualErrorMessage := p.Errors[0].message
+		if tt.expected != actualErrorMessage {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actualErrorMessage)
+		}
+	}
+}
+
 func TestParse_LogStmt(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -260,6 +514,84 @@ func TestParseError_ReturnExpr(t *testing.T) {
 	}
 }
 
+func TestParse_ThrowStmt(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"throw \"boom\";", "(#throw \"boom\")"},
+		{"throw e;", "(#throw e)"},
+	}
+	for idx, tt := range tests {
+		l := lexer.New(tt.input)
+		tokens, _ := l.ScanTokens()
+		p := New(tokens)
+		expr := p.Parse()
+		if len(expr) < 1 {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%v", idx, tt.expected, nil)
+		}
+		printer := ast.Printer{}
+		actual := printer.Print(expr[0])
+		if tt.expected != actual {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actual)
+		}
+	}
+}
+
+func TestParse_TryStmt(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"try { log 1; } catch (e) { log e; }",
+			"(#try (#bs (#ls 1)) (#catch e (#bs (#ls e))))"},
+		{"try { log 1; } finally { log 2; }",
+			"(#try (#bs (#ls 1)) (#finally (#bs (#ls 2))))"},
+		{"try { log 1; } catch (e) { } finally { }",
+			"(#try (#bs (#ls 1)) (#catch e (#bs)) (#finally (#bs)))"},
+	}
+	for idx, tt := range tests {
+		l := lexer.New(tt.input)
+		tokens, _ := l.ScanTokens()
+		p := New(tokens)
+		expr := p.Parse()
+		if len(expr) < 1 {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%v", idx, tt.expected, nil)
+		}
+		printer := ast.Printer{}
+		actual := printer.Print(expr[0])
+		if tt.expected != actual {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actual)
+		}
+	}
+}
+
+func TestParseError_TryStmt(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"throw \"boom\"", "Expected ';' after throw value."},
+		{"try log 1;", "Expected '{' after 'try'."},
+		{"try { log 1; }", "Expected 'catch' or 'finally' after try block."},
+		{"try { log 1; } catch { }", "Expected '(' after 'catch'."},
+		{"try { log 1; } catch () { }", "Expected catch variable name."},
+		{"try { log 1; } catch (e { }", "Expected ')' after catch variable."},
+		{"try { log 1; } catch (e) log e;", "Expected '{' before catch body."},
+		{"try { log 1; } finally log 2;", "Expected '{' before finally body."},
+	}
+	for idx, tt := range tests {
+		l := lexer.New(tt.input)
+		tokens, _ := l.ScanTokens()
+		p := New(tokens)
+		p.Parse()
+		actualErrorMessage := p.Errors[0].message
+		if tt.expected != actualErrorMessage {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actualErrorMessage)
+		}
+	}
+}
+
 func TestParse_VariableStmt(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -331,3 +663,49 @@ func TestParseError_WhileStmt(t *testing.T) {
 		}
 	}
 }
+
+func TestParse_WithStmt(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"with env {\"A\": \"1\"} { log $A; }", "(#with-env (#map {\"A\": \"1\"}) (#bs (#ls $A)))"},
+		{"with env overrides { }", "(#with-env overrides (#bs))"},
+	}
+	for idx, tt := range tests {
+		l := lexer.New(tt.input)
+		tokens, _ := l.ScanTokens()
+		p := New(tokens)
+		expr := p.Parse()
+		if len(expr) < 1 {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%v", idx, tt.expected, nil)
+		}
+		printer := ast.Printer{}
+		actual := printer.Print(expr[0])
+		if tt.expected != actual {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actual)
+		}
+	}
+}
+
+func TestParseError_WithStmt(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"with {} { }", "Expected 'env' after 'with'."},
+		{"with vars {} { }", "Expected 'env' after 'with'."},
+		{"with env {} log 1;", "Expected '{' before with body."},
+		{"with env {} { log 1;", "Expected '}' after block."},
+	}
+	for idx, tt := range tests {
+		l := lexer.New(tt.input)
+		tokens, _ := l.ScanTokens()
+		p := New(tokens)
+		p.Parse()
+		actualErrorMessage := p.Errors[0].message
+		if tt.expected != actualErrorMessage {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actualErrorMessage)
+		}
+	}
+}
@@ -4,68 +4,84 @@ package repl
 //
 
 type debug struct {
-	tokenHeader    bool
-	token          bool
-	tokenErrHeader bool
-	tokenErr       bool
-	parseErrHeader bool
-	parseErr       bool
-	exprHeader     bool
-	expr           bool
-	evalErrHeader  bool
-	evalErr        bool
-	resultHeader   bool
-	result         bool
+	tokenHeader      bool
+	token            bool
+	tokenErrHeader   bool
+	tokenErr         bool
+	parseErrHeader   bool
+	parseErr         bool
+	resolveErrHeader bool
+	resolveErr       bool
+	compileErrHeader bool
+	compileErr       bool
+	exprHeader       bool
+	expr             bool
+	evalErrHeader    bool
+	evalErr          bool
+	resultHeader     bool
+	result           bool
 }
 
 func noDebug() debug {
 	return debug{
-		tokenHeader:    false,
-		token:          false,
-		tokenErrHeader: false,
-		tokenErr:       false,
-		parseErrHeader: false,
-		parseErr:       false,
-		exprHeader:     false,
-		expr:           false,
-		evalErrHeader:  true,
-		evalErr:        true,
-		resultHeader:   false,
-		result:         false,
+		tokenHeader:      false,
+		token:            false,
+		tokenErrHeader:   false,
+		tokenErr:         false,
+		parseErrHeader:   false,
+		parseErr:         false,
+		resolveErrHeader: true,
+		resolveErr:       true,
+		compileErrHeader: true,
+		compileErr:       true,
+		exprHeader:       false,
+		expr:             false,
+		evalErrHeader:    true,
+		evalErr:          true,
+		resultHeader:     false,
+		result:           false,
 	}
 }
 
 func defaultDebug() debug {
 	return debug{
-		tokenHeader:    false,
-		token:          false,
-		tokenErrHeader: true,
-		tokenErr:       true,
-		parseErrHeader: true,
-		parseErr:       true,
-		exprHeader:     false,
-		expr:           false,
-		evalErrHeader:  true,
-		evalErr:        true,
-		resultHeader:   false,
-		result:         true,
+		tokenHeader:      false,
+		token:            false,
+		tokenErrHeader:   true,
+		tokenErr:         true,
+		parseErrHeader:   true,
+		parseErr:         true,
+		resolveErrHeader: true,
+		resolveErr:       true,
+		compileErrHeader: true,
+		compileErr:       true,
+		exprHeader:       false,
+		expr:             false,
+		evalErrHeader:    true,
+		evalErr:          true,
+		resultHeader:     false,
+		result:           true,
 	}
 }
 
 func fullDebug() debug {
 	return debug{
-		tokenHeader:    true,
-		token:          true,
-		tokenErrHeader: true,
-		tokenErr:       true,
-		parseErrHeader: true,
-		parseErr:       true,
-		exprHeader:     true,
-		expr:           true,
-		evalErrHeader:  true,
-		evalErr:        true,
-		resultHeader:   true,
-		result:         true,
+		tokenHeader:      true,
+		token:            true,
+		tokenErrHeader:   true,
+		tokenErr:         true,
+		parseErrHeader:   true,
+		parseErr:         true,
+		resolveErrHeader: true,
+		resolveErr:       true,
+		compileErrHeader: true,
+		compileErr:       true,
+		exprHeader:       true,
+		expr:             true,
+		evalErrHeader:    true,
+		evalErr:          true,
+		resultHeader:     true,
+		result:           true,
 	}
 }
 
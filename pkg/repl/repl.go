@@ -5,12 +5,16 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"path/filepath"
 	"strings"
 
 	"github.com/templecloud/glu/pkg/ast"
+	"github.com/templecloud/glu/pkg/compiler"
 	"github.com/templecloud/glu/pkg/interpreter"
 	"github.com/templecloud/glu/pkg/lexer"
 	"github.com/templecloud/glu/pkg/parser"
+	"github.com/templecloud/glu/pkg/resolver"
+	"github.com/templecloud/glu/pkg/vm"
 )
 
 const (
@@ -30,6 +34,8 @@ const (
 	ansiOff = "ansi off"	
 	// run is a repl command for running a file.
 	run = "run"
+	// jobs is a repl command to list the running background jobs.
+	jobs = "jobs"
 )
 
 // Repl ===================================================================
@@ -40,6 +46,8 @@ type Repl struct {
 	ansi ANSI
 	config
 	evaluator *interpreter.Interpreter
+	// machine executes compiled statements in place of the evaluator, if set.
+	machine *vm.VM
 }
 
 // New creates a new default Repl.
@@ -60,6 +68,20 @@ func NewCmd() *Repl {
 	}
 }
 
+// WithVM configures the Repl to compile statements to bytecode and execute
+// them with a VM instead of the tree-walking interpreter.
+func (r *Repl) WithVM() *Repl {
+	r.machine = vm.New(r.evaluator)
+	return r
+}
+
+// WithMaxDepth configures the maximum depth of the call stack. Deeper calls
+// raise a runtime error.
+func (r *Repl) WithMaxDepth(depth int) *Repl {
+	r.evaluator.MaxDepth = depth
+	return r
+}
+
 // Start begins a new REPL session.
 func (r *Repl) Start(in io.Reader, out io.Writer) {
 	fmt.Printf("Glu %s\n", version)
@@ -88,14 +110,16 @@ func (r *Repl) Start(in io.Reader, out io.Writer) {
 		} else if input == ansiOff {
 			r.ansi = NewANSI(false)
 			continue
+		} else if input == jobs || input == jobs+"()" {
+			r.listJobs(out)
+			continue
 		} else if strings.HasPrefix(input, run) {
 			fp := strings.Trim(strings.Replace(input, run, "", 1), " ")
 			if fp != "" {
-				data, err := ioutil.ReadFile(fp)
-				if err != nil {
+				if err := r.ExecFile(fp); err != nil {
 					fmt.Println("Failed to open file: ", err)
 				}
-				input = string(data)
+				continue
 			} else {
 				fmt.Printf("'%s' requires a valid file.\n", run)
 			}
@@ -104,10 +128,35 @@ func (r *Repl) Start(in io.Reader, out io.Writer) {
 	}
 }
 
+// ExecFile reads and executes the specified file as the main module. Its
+// imports are resolved relative to the directory of the file.
+func (r *Repl) ExecFile(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	module := r.evaluator.Module
+	previous := module.Path
+	module.Path = abs
+	defer func() { module.Path = previous }()
+	r.exec(string(data), module.Name())
+	return nil
+}
+
 // Exec tokenizes, parses, and, executes the specified input string.
 func (r *Repl) Exec(input string) {
+	r.exec(input, "")
+}
+
+// exec executes the input string, whose tokens are attributed to the
+// specified origin.
+func (r *Repl) exec(input string, origin string) {
 	// Lexer
-	l := lexer.New(input)
+	l := lexer.NewWithOrigin(input, origin)
 	tokens, errors := l.ScanTokens()
 	for idx, token := range tokens {
 		if r.config.tokenHeader {
@@ -148,7 +197,7 @@ func (r *Repl) Exec(input string) {
 			// 	fmt.Printf("%s\n", parserErr.Error())
 			// }			
 		}
-	} else {
+	} else if scripts, ok := r.prepare(stmts); ok {
 		for idx, stmt := range stmts {
 			// Print
 			printer := ast.Printer{}
@@ -162,8 +211,13 @@ func (r *Repl) Exec(input string) {
 			}
 
 			// Evaluate
-			i := r.evaluator
-			result, evalErr := i.Eval(stmt)
+			var result interface{}
+			var evalErr *interpreter.Error
+			if r.machine != nil {
+				result, evalErr = r.machine.Run(scripts[idx])
+			} else {
+				result, evalErr = r.evaluator.Eval(stmt)
+			}
 			if evalErr != nil {
 				if r.config.evalErrHeader {
 					header := fmt.Sprintf("Runtime Error: ")
@@ -171,6 +225,9 @@ func (r *Repl) Exec(input string) {
 				}
 				if r.config.evalErr {
 					fmt.Printf("%s", r.ansi.red(evalErr))
+					if trace := evalErr.Trace(); trace != "" {
+						fmt.Printf("%s\n", r.ansi.red(trace))
+					}
 				}
 			} else {
 				// Result
@@ -185,3 +242,57 @@ func (r *Repl) Exec(input string) {
 		}
 	}
 }
+
+// resolve resolves the variables of the statements for the evaluator. It
+// returns false if there were errors.
+func (r *Repl) resolve(stmts []ast.Stmt) bool {
+	res := resolver.New()
+	res.Resolve(stmts)
+	for idx, resolveErr := range res.Errors {
+		if r.config.resolveErrHeader {
+			header := fmt.Sprintf("Resolve Error [%d]: ", idx)
+			fmt.Printf("%s", r.ansi.brightRed(header))
+		}
+		if r.config.resolveErr {
+			fmt.Printf("%s\n", r.ansi.red(resolveErr.Error()))
+		}
+	}
+	if len(res.Errors) > 0 {
+		return false
+	}
+	r.evaluator.Resolve(res.Locals)
+	return true
+}
+
+// prepare resolves the statements and, if the Repl uses a VM, compiles each
+// of them to a script. It returns false if there were errors.
+func (r *Repl) prepare(stmts []ast.Stmt) ([]*compiler.Function, bool) {
+	if !r.resolve(stmts) {
+		return nil, false
+	}
+	if r.machine == nil {
+		return nil, true
+	}
+	c := compiler.New()
+	scripts := make([]*compiler.Function, 0, len(stmts))
+	for _, stmt := range stmts {
+		scripts = append(scripts, c.CompileStmt(stmt))
+	}
+	for idx, compileErr := range c.Errors {
+		if r.config.compileErrHeader {
+			header := fmt.Sprintf("Compile Error [%d]: ", idx)
+			fmt.Printf("%s", r.ansi.brightRed(header))
+		}
+		if r.config.compileErr {
+			fmt.Printf("%s\n", r.ansi.red(compileErr.Error()))
+		}
+	}
+	return scripts, len(c.Errors) == 0
+}
+
+// listJobs writes the background jobs of the session that are still running.
+func (r *Repl) listJobs(out io.Writer) {
+	for _, job := range r.evaluator.Jobs.Running() {
+		fmt.Fprintf(out, "[%d] %d running %s\n", job.ID, job.Pid(), job.Command)
+	}
+}
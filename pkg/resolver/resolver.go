@@ -0,0 +1,508 @@
+package resolver
+
+import (
+	"fmt"
+
+	"github.com/templecloud/glu/pkg/ast"
+	"github.com/templecloud/glu/pkg/token"
+)
+
+// Resolver ===================================================================
+//
+
+// Resolver is a Visitor that statically resolves the variables of a program
+// before it is interpreted. Each variable declared in a local scope is
+// numbered with a slot in that scope, and each reference to a local variable
+// is recorded as a Local. References that are not resolved are global.
+//
+// The functions declared in a local scope may be referred to from the bodies
+// of the functions declared before them, so local functions can be mutually
+// recursive.
+type Resolver struct {
+	Locals map[ast.Expr]Local
+	Errors []*Error
+	// scopes is the stack of enclosing local scopes, innermost last.
+	scopes []*scope
+	// inClass is true when resolving the members of a class.
+	inClass bool
+	// functions is the number of functions enclosing the node being resolved.
+	functions int
+}
+
+// New constructor.
+func New() *Resolver {
+	return &Resolver{Locals: map[ast.Expr]Local{}}
+}
+
+// Resolve the variables of the statements. Any errors are recorded in Errors.
+func (r *Resolver) Resolve(stmts []ast.Stmt) {
+	r.hoist(stmts)
+	for _, stmt := range stmts {
+		r.resolveStmt(stmt)
+	}
+}
+
+// Local ======================================================================
+//
+
+// Local is the position of a local variable relative to a reference: the
+// number of scopes between the reference and the declaration, and the slot of
+// the variable in the declaring scope.
+type Local struct {
+	Depth int
+	Slot  int
+}
+
+// scope is a local scope. A variable is declared before its initialiser is
+// resolved, and defined after, so that a variable cannot be read in its own
+// initialiser. The slots of the functions of the scope are hoisted so that
+// they are known before their declarations.
+type scope struct {
+	slots     map[string]int
+	defined   map[string]bool
+	hoisted   map[string]int
+	functions int
+}
+
+func newScope(functions int) *scope {
+	return &scope{
+		slots:     map[string]int{},
+		defined:   map[string]bool{},
+		hoisted:   map[string]int{},
+		functions: functions,
+	}
+}
+
+// Expression Functions =======================================================
+//
+
+// VisitAssignExpr resolves the node.
+func (r *Resolver) VisitAssignExpr(expr *ast.Assign) interface{} {
+	r.resolveExpr(expr.Value)
+	r.resolveLocal(expr, expr.Name)
+	return nil
+}
+
+// VisitBinaryExpr resolves the node.
+func (r *Resolver) VisitBinaryExpr(expr *ast.Binary) interface{} {
+	r.resolveExpr(expr.Left)
+	r.resolveExpr(expr.Right)
+	return nil
+}
+
+// VisitCallExpr resolves the node.
+func (r *Resolver) VisitCallExpr(expr *ast.Call) interface{} {
+	r.resolveExpr(expr.Callee)
+	r.resolveExprs(expr.Arguments)
+	return nil
+}
+
+// VisitCommandExpr resolves the node.
+func (r *Resolver) VisitCommandExpr(expr *ast.Command) interface{} {
+	return nil
+}
+
+// VisitConcatExpr resolves the node.
+func (r *Resolver) VisitConcatExpr(expr *ast.Concat) interface{} {
+	r.resolveExprs(expr.Parts)
+	return nil
+}
+
+// VisitEnvVarExpr resolves the node.
+func (r *Resolver) VisitEnvVarExpr(expr *ast.EnvVar) interface{} {
+	return nil
+}
+
+// VisitGetExpr resolves the node.
+func (r *Resolver) VisitGetExpr(expr *ast.Get) interface{} {
+	r.resolveExpr(expr.Object)
+	return nil
+}
+
+// VisitGroupingExpr resolves the node.
+func (r *Resolver) VisitGroupingExpr(expr *ast.Grouping) interface{} {
+	r.resolveExpr(expr.Expr)
+	return nil
+}
+
+// VisitIndexExpr resolves the node.
+func (r *Resolver) VisitIndexExpr(expr *ast.Index) interface{} {
+	r.resolveExpr(expr.Object)
+	r.resolveExpr(expr.Index)
+	return nil
+}
+
+// VisitLambdaExpr resolves the node.
+func (r *Resolver) VisitLambdaExpr(expr *ast.Lambda) interface{} {
+	r.resolveFunction(expr.Fn)
+	return nil
+}
+
+// VisitListExpr resolves the node.
+func (r *Resolver) VisitListExpr(expr *ast.List) interface{} {
+	r.resolveExprs(expr.Elements)
+	return nil
+}
+
+// VisitLiteralExpr resolves the node.
+func (r *Resolver) VisitLiteralExpr(expr *ast.Literal) interface{} {
+	return nil
+}
+
+// VisitLogicalExpr resolves the node.
+func (r *Resolver) VisitLogicalExpr(expr *ast.Logical) interface{} {
+	r.resolveExpr(expr.Left)
+	r.resolveExpr(expr.Right)
+	return nil
+}
+
+// VisitMapExpr resolves the node.
+func (r *Resolver) VisitMapExpr(expr *ast.Map) interface{} {
+	r.resolveExprs(expr.Keys)
+	r.resolveExprs(expr.Values)
+	return nil
+}
+
+// VisitNumeralExpr resolves the node.
+func (r *Resolver) VisitNumeralExpr(expr *ast.Numeral) interface{} {
+	return nil
+}
+
+// VisitReturnExpr resolves the node.
+func (r *Resolver) VisitReturnExpr(expr *ast.Return) interface{} {
+	if r.functions == 0 {
+		r.error(expr.Keyword, "Cannot return from top-level code.")
+	}
+	if expr.Value != nil {
+		r.resolveExpr(expr.Value)
+	}
+	return nil
+}
+
+// VisitSetExpr resolves the node.
+func (r *Resolver) VisitSetExpr(expr *ast.Set) interface{} {
+	r.resolveExpr(expr.Value)
+	r.resolveExpr(expr.Object)
+	return nil
+}
+
+// VisitSetEnvExpr resolves the node.
+func (r *Resolver) VisitSetEnvExpr(expr *ast.SetEnv) interface{} {
+	r.resolveExpr(expr.Value)
+	return nil
+}
+
+// VisitSetIndexExpr resolves the node.
+func (r *Resolver) VisitSetIndexExpr(expr *ast.SetIndex) interface{} {
+	r.resolveExpr(expr.Object)
+	r.resolveExpr(expr.Index)
+	r.resolveExpr(expr.Value)
+	return nil
+}
+
+// VisitSliceExpr resolves the node.
+func (r *Resolver) VisitSliceExpr(expr *ast.Slice) interface{} {
+	r.resolveExpr(expr.Object)
+	if expr.Start != nil {
+		r.resolveExpr(expr.Start)
+	}
+	if expr.End != nil {
+		r.resolveExpr(expr.End)
+	}
+	return nil
+}
+
+// VisitThisExpr resolves the node.
+func (r *Resolver) VisitThisExpr(expr *ast.This) interface{} {
+	if !r.inClass {
+		r.error(expr.Keyword, "Cannot use 'this' outside of a class.")
+		return nil
+	}
+	r.resolveLocal(expr, expr.Keyword)
+	return nil
+}
+
+// VisitUnaryExpr resolves the node.
+func (r *Resolver) VisitUnaryExpr(expr *ast.Unary) interface{} {
+	r.resolveExpr(expr.Right)
+	return nil
+}
+
+// VisitVarExpr resolves the node.
+func (r *Resolver) VisitVarExpr(expr *ast.VarExpr) interface{} {
+	if len(r.scopes) > 0 {
+		current := r.scopes[len(r.scopes)-1]
+		if _, declared := current.slots[expr.Name.Lexeme]; declared && !current.defined[expr.Name.Lexeme] {
+			err := fmt.Sprintf("Cannot read local variable '%s' in its own initialiser.", expr.Name.Lexeme)
+			r.error(expr.Name, err)
+		}
+	}
+	r.resolveLocal(expr, expr.Name)
+	return nil
+}
+
+// Stmt Functions =============================================================
+//
+
+// VisitBlockStmt resolves the node.
+func (r *Resolver) VisitBlockStmt(stmt *ast.BlockStmt) interface{} {
+	r.resolveBlock(stmt.Stmts)
+	return nil
+}
+
+// VisitBreakStmt resolves the node.
+func (r *Resolver) VisitBreakStmt(stmt *ast.BreakStmt) interface{} {
+	return nil
+}
+
+// VisitClassStmt resolves the node. The fields and methods are resolved in a
+// scope that declares 'this'.
+func (r *Resolver) VisitClassStmt(stmt *ast.ClassStmt) interface{} {
+	r.declare(stmt.Name)
+	r.define(stmt.Name)
+	inClass := r.inClass
+	r.inClass = true
+	r.beginScope()
+	r.declare(&token.Token{Type: token.This, Lexeme: "this"})
+	r.define(&token.Token{Type: token.This, Lexeme: "this"})
+	for _, field := range stmt.Fields {
+		if field.Initialiser != nil {
+			r.resolveExpr(field.Initialiser)
+		}
+	}
+	for _, method := range stmt.Methods {
+		r.resolveFunction(method)
+	}
+	r.endScope()
+	r.inClass = inClass
+	return nil
+}
+
+// VisitContinueStmt resolves the node.
+func (r *Resolver) VisitContinueStmt(stmt *ast.ContinueStmt) interface{} {
+	return nil
+}
+
+// VisitExecStmt resolves the node.
+func (r *Resolver) VisitExecStmt(stmt *ast.ExecStmt) interface{} {
+	r.resolveExprs(stmt.Arguments)
+	for _, redirect := range stmt.Redirects {
+		if redirect.Target != nil {
+			r.resolveExpr(redirect.Target)
+		}
+	}
+	return nil
+}
+
+// VisitExportStmt resolves the node.
+func (r *Resolver) VisitExportStmt(stmt *ast.ExportStmt) interface{} {
+	r.resolveStmt(stmt.Declaration)
+	return nil
+}
+
+// VisitExprStmt resolves the node.
+func (r *Resolver) VisitExprStmt(stmt *ast.ExprStmt) interface{} {
+	r.resolveExpr(stmt.Expr)
+	return nil
+}
+
+// VisitIfStmt resolves the node.
+func (r *Resolver) VisitIfStmt(stmt *ast.IfStmt) interface{} {
+	r.resolveExpr(stmt.Condition)
+	r.resolveStmt(stmt.ThenBranch)
+	if stmt.ElseBranch != nil {
+		r.resolveStmt(stmt.ElseBranch)
+	}
+	return nil
+}
+
+// VisitFnStmt resolves the node. The function is defined before its body is
+// resolved so that it can refer to itself recursively.
+func (r *Resolver) VisitFnStmt(stmt *ast.FnStmt) interface{} {
+	r.declare(stmt.Name)
+	r.define(stmt.Name)
+	r.resolveFunction(stmt)
+	return nil
+}
+
+// VisitImportStmt resolves the node. The functions imported from a bash
+// library are only known at runtime, so they are not resolved.
+func (r *Resolver) VisitImportStmt(stmt *ast.ImportStmt) interface{} {
+	if stmt.Alias != nil {
+		r.declare(stmt.Alias)
+		r.define(stmt.Alias)
+	}
+	return nil
+}
+
+// VisitLogStmt resolves the node.
+func (r *Resolver) VisitLogStmt(stmt *ast.LogStmt) interface{} {
+	r.resolveExpr(stmt.Expr)
+	return nil
+}
+
+// VisitThrowStmt resolves the node.
+func (r *Resolver) VisitThrowStmt(stmt *ast.ThrowStmt) interface{} {
+	r.resolveExpr(stmt.Value)
+	return nil
+}
+
+// VisitTryStmt resolves the node. The caught error is declared in the scope
+// of the catch block.
+func (r *Resolver) VisitTryStmt(stmt *ast.TryStmt) interface{} {
+	r.resolveBlock(stmt.Body)
+	if stmt.CatchName != nil {
+		r.beginScope()
+		r.declare(stmt.CatchName)
+		r.define(stmt.CatchName)
+		r.Resolve(stmt.Catch)
+		r.endScope()
+	}
+	if stmt.Finally != nil {
+		r.resolveBlock(stmt.Finally)
+	}
+	return nil
+}
+
+// VisitVariableStmt resolves the node.
+func (r *Resolver) VisitVariableStmt(stmt *ast.VariableStmt) interface{} {
+	r.declare(stmt.Name)
+	if stmt.Initialiser != nil {
+		r.resolveExpr(stmt.Initialiser)
+	}
+	r.define(stmt.Name)
+	return nil
+}
+
+// VisitWhileStmt resolves the node.
+func (r *Resolver) VisitWhileStmt(stmt *ast.WhileStmt) interface{} {
+	r.resolveExpr(stmt.Condition)
+	r.resolveStmt(stmt.Body)
+	if stmt.Increment != nil {
+		r.resolveExpr(stmt.Increment)
+	}
+	return nil
+}
+
+// VisitWithStmt resolves the node.
+func (r *Resolver) VisitWithStmt(stmt *ast.WithStmt) interface{} {
+	r.resolveExpr(stmt.Env)
+	r.resolveBlock(stmt.Body)
+	return nil
+}
+
+// Support Functions ==========================================================
+//
+
+func (r *Resolver) resolveStmt(stmt ast.Stmt) {
+	stmt.Accept(r)
+}
+
+func (r *Resolver) resolveExpr(expr ast.Expr) {
+	expr.Accept(r)
+}
+
+func (r *Resolver) resolveExprs(exprs []ast.Expr) {
+	for _, expr := range exprs {
+		r.resolveExpr(expr)
+	}
+}
+
+// resolveBlock resolves the statements in a new scope.
+func (r *Resolver) resolveBlock(stmts []ast.Stmt) {
+	r.beginScope()
+	r.Resolve(stmts)
+	r.endScope()
+}
+
+// resolveFunction resolves the parameters and body of a function in a new
+// scope. The body of a script function is not Glu, so it is not resolved.
+func (r *Resolver) resolveFunction(fn *ast.FnStmt) {
+	r.functions++
+	r.beginScope()
+	for _, param := range fn.Params {
+		r.declare(param)
+		r.define(param)
+	}
+	r.Resolve(fn.Body)
+	r.endScope()
+	r.functions--
+}
+
+// resolveLocal records the position of the variable if it is declared in an
+// enclosing local scope. A function that is not declared yet is only visible
+// from the body of a function, which cannot be called before the declaration
+// is reached.
+func (r *Resolver) resolveLocal(expr ast.Expr, name *token.Token) {
+	for idx := len(r.scopes) - 1; idx >= 0; idx-- {
+		scope := r.scopes[idx]
+		slot, ok := scope.slots[name.Lexeme]
+		if !ok && r.functions > scope.functions {
+			slot, ok = scope.hoisted[name.Lexeme]
+		}
+		if ok {
+			r.Locals[expr] = Local{Depth: len(r.scopes) - 1 - idx, Slot: slot}
+			return
+		}
+	}
+}
+
+// hoist records the slots the functions declared in the statements will have
+// in the current local scope.
+func (r *Resolver) hoist(stmts []ast.Stmt) {
+	if len(r.scopes) == 0 {
+		return
+	}
+	current := r.scopes[len(r.scopes)-1]
+	slot := len(current.slots)
+	for _, stmt := range stmts {
+		switch s := stmt.(type) {
+		case *ast.FnStmt:
+			if _, ok := current.hoisted[s.Name.Lexeme]; !ok {
+				current.hoisted[s.Name.Lexeme] = slot
+			}
+			slot++
+		case *ast.VariableStmt, *ast.ClassStmt:
+			slot++
+		case *ast.ImportStmt:
+			if s.Alias != nil {
+				slot++
+			}
+		}
+	}
+}
+
+func (r *Resolver) beginScope() {
+	r.scopes = append(r.scopes, newScope(r.functions))
+}
+
+func (r *Resolver) endScope() {
+	r.scopes = r.scopes[:len(r.scopes)-1]
+}
+
+// declare numbers the variable with the next slot of the current scope.
+// Variables in the global scope are not numbered and may be redeclared.
+func (r *Resolver) declare(name *token.Token) {
+	if len(r.scopes) == 0 {
+		return
+	}
+	current := r.scopes[len(r.scopes)-1]
+	if _, ok := current.slots[name.Lexeme]; ok {
+		err := fmt.Sprintf("Variable '%s' is already declared in this scope.", name.Lexeme)
+		r.error(name, err)
+		return
+	}
+	current.slots[name.Lexeme] = len(current.slots)
+}
+
+// define marks the variable as initialised.
+func (r *Resolver) define(name *token.Token) {
+	if len(r.scopes) == 0 {
+		return
+	}
+	r.scopes[len(r.scopes)-1].defined[name.Lexeme] = true
+}
+
+func (r *Resolver) error(token *token.Token, message string) {
+	r.Errors = append(r.Errors, NewError(token, message))
+}
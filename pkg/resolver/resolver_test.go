@@ -0,0 +1,125 @@
+package resolver
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/templecloud/glu/pkg/ast"
+	"github.com/templecloud/glu/pkg/lexer"
+	"github.com/templecloud/glu/pkg/parser"
+	"github.com/templecloud/glu/pkg/token"
+)
+
+func TestResolve_Locals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"var a = 1; log a;", ""},
+		{"{ var a = 1; var b = 2; log b; log a; }", "b@0:1 a@0:0"},
+		{"{ var a = 1; { var b = a; a = b; } }", "a@1:0 a@1:0 b@0:0"},
+		{"func f(a, b) { return b + a; }", "b@0:1 a@0:0"},
+		{"{ var n = 0; func inc() { n = n + 1; return n; } }", "n@1:0 n@1:0 n@1:0"},
+		{"var g = 1; { func f() { log g; } var g = 2; f(); }", "f@0:0"},
+		{"{ var a; func f() { return g(); } func g() { return f(); } }", "g@1:2 f@1:1"},
+		{"var g = 1; { log g; func g() {} }", ""},
+		{"class P { var x = this; m(a) { return this.x + a; } }", "this@0:0 this@1:0 a@0:0"},
+		{"try { var a = 1; } catch (e) { var a = e; } finally { var a = 2; }", "e@0:0"},
+		{"{ var f = (x) => x; import \"m.glu\" as m; log m; }", "x@0:0 m@0:1"},
+		{"{ import bash \"lib.sh\"; greet(); }", ""},
+	}
+	for idx, tt := range tests {
+		r := resolve(tt.input)
+		if len(r.Errors) > 0 {
+			t.Fatalf("test[%d] - Unexpected errors: %v", idx, r.Errors[0])
+		}
+		actual := locals(r)
+		if tt.expected != actual {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, actual)
+		}
+	}
+}
+
+func TestResolveError_Locals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"{ var a = a; }", "Cannot read local variable 'a' in its own initialiser."},
+		{"func f() { var a = 1 + a; }", "Cannot read local variable 'a' in its own initialiser."},
+		{"{ var a = 1; var a = 2; }", "Variable 'a' is already declared in this scope."},
+		{"func f(a, a) {}", "Variable 'a' is already declared in this scope."},
+		{"func f(a) { var a = 1; }", "Variable 'a' is already declared in this scope."},
+		{"{ func f() {} class f {} }", "Variable 'f' is already declared in this scope."},
+		{"try {} catch (e) { var e = 1; }", "Variable 'e' is already declared in this scope."},
+		{"log this;", "Cannot use 'this' outside of a class."},
+		{"func f() { return this; }", "Cannot use 'this' outside of a class."},
+		{"return 1;", "Cannot return from top-level code."},
+		{"{ while (true) { return; } }", "Cannot return from top-level code."},
+		{"class A { var a = () => { return 1; }; } return;", "Cannot return from top-level code."},
+	}
+	for idx, tt := range tests {
+		r := resolve(tt.input)
+		if len(r.Errors) == 0 {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%v", idx, tt.expected, nil)
+		}
+		if tt.expected != r.Errors[0].message {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, r.Errors[0].message)
+		}
+	}
+}
+
+func TestResolveError_Global(t *testing.T) {
+	r := resolve("var a = 1; var a = a;\n{ var b; var b; }")
+	expected := "Variable 'b' is already declared in this scope. At Line: 2, Column: 14, Token: {Identifier: 'b'}."
+	if len(r.Errors) != 1 {
+		t.Fatalf("Wrong number of errors. Expected=%d, Actual=%d", 1, len(r.Errors))
+	}
+	if expected != r.Errors[0].Error() {
+		t.Fatalf("Expected=%q, Actual=%q", expected, r.Errors[0].Error())
+	}
+}
+
+// Support Functions ==========================================================
+//
+
+func resolve(input string) *Resolver {
+	tokens, _ := lexer.New(input).ScanTokens()
+	stmts := parser.New(tokens).Parse()
+	r := New()
+	r.Resolve(stmts)
+	return r
+}
+
+// locals describes the resolved variables as 'name@depth:slot' in the order
+// they appear in the source.
+func locals(r *Resolver) string {
+	var names []*token.Token
+	described := map[*token.Token]string{}
+	for expr, local := range r.Locals {
+		var name *token.Token
+		switch e := expr.(type) {
+		case *ast.Assign:
+			name = e.Name
+		case *ast.This:
+			name = e.Keyword
+		case *ast.VarExpr:
+			name = e.Name
+		}
+		names = append(names, name)
+		described[name] = fmt.Sprintf("%s@%d:%d", name.Lexeme, local.Depth, local.Slot)
+	}
+	sort.Slice(names, func(a, b int) bool {
+		if names[a].Source.Line != names[b].Source.Line {
+			return names[a].Source.Line < names[b].Source.Line
+		}
+		return names[a].Source.Column < names[b].Source.Column
+	})
+	var descriptions []string
+	for _, name := range names {
+		descriptions = append(descriptions, described[name])
+	}
+	return strings.Join(descriptions, " ")
+}
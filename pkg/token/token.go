@@ -44,21 +44,38 @@ func New(
 
 // Structural tokens.
 const (
-	LeftParen  = "LeftParen"  // "("
-	RightParen = "RightParen" // ")"
-	LeftBrace  = "LeftBrace"  // "{"
-	RightBrace = "RightBrace" // "}"
-	Comma      = "Comma"      // ","
-	Dot        = "Dot"        // "."
-	Semicolon  = "Semicolon"  // ";"
+	LeftParen    = "LeftParen"    // "("
+	RightParen   = "RightParen"   // ")"
+	LeftBrace    = "LeftBrace"    // "{"
+	RightBrace   = "RightBrace"   // "}"
+	LeftBracket  = "LeftBracket"  // "["
+	RightBracket = "RightBracket" // "]"
+	Comma        = "Comma"        // ","
+	Dot          = "Dot"          // "."
+	Colon        = "Colon"        // ":"
+	Semicolon    = "Semicolon"    // ";"
+	Arrow        = "Arrow"        // "=>"
 )
 
 // Arithmetic operators.
 const (
-	Minus        = "Minus"        // "-"
-	Plus         = "Plus"         // "+"
-	ForwardSlash = "ForwardSlash" // "/"
-	Star         = "Star"         // "*"
+	Minus            = "Minus"            // "-"
+	Plus             = "Plus"             // "+"
+	ForwardSlash     = "ForwardSlash"     // "/"
+	ForwardSlashDual = "ForwardSlashDual" // "//"
+	Star             = "Star"             // "*"
+	StarDual         = "StarDual"         // "**"
+	Percent          = "Percent"          // "%"
+)
+
+// Bitwise operators.
+const (
+	Ampersand       = "Ampersand"       // "&"
+	Pipe            = "Pipe"            // "|"
+	Caret           = "Caret"           // "^"
+	Tilde           = "Tilde"           // "~"
+	LessThanDual    = "LessThanDual"    // "<<"
+	GreaterThanDual = "GreaterThanDual" // ">>"
 )
 
 // Comparison operators.
@@ -75,26 +92,43 @@ const (
 
 // Literals.
 const (
-	Identifier = "Identifier"
-	String     = "String"
-	Number     = "Number"
+	Identifier    = "Identifier"
+	String        = "String"
+	Interpolation = "Interpolation" // "...${"
+	Number        = "Number"
+	Command       = "Command"   // "`...`" or "$(...)"
+	EnvVar        = "EnvVar"    // "$NAME"
+	Attribute     = "Attribute" // "@name"
+	Script        = "Script"    // raw body of an attributed function
 )
 
 // Keywords.
 const (
-	Nil    = "nil"
-	True   = "true"
-	False  = "false"
-	And    = "and" // "&&"
-	Or     = "or"  // "||"
-	If     = "if"
-	Else   = "else"
-	While  = "while"
-	For    = "for"
-	Return = "return"
-	Var    = "var"
-	Func   = "func"
-	Log    = "log"
+	Nil      = "nil"
+	True     = "true"
+	False    = "false"
+	And      = "and" // "&&"
+	Or       = "or"  // "||"
+	If       = "if"
+	Else     = "else"
+	While    = "while"
+	For      = "for"
+	Break    = "break"
+	Continue = "continue"
+	Return   = "return"
+	Throw    = "throw"
+	Try      = "try"
+	Catch    = "catch"
+	Finally  = "finally"
+	Var      = "var"
+	Func     = "func"
+	Class    = "class"
+	This     = "this"
+	Log      = "log"
+	Exec     = "exec"
+	With     = "with"
+	Import   = "import"
+	Export   = "export"
 )
 
 // Special.
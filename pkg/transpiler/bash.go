@@ -0,0 +1,956 @@
+package transpiler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/templecloud/glu/pkg/ast"
+	"github.com/templecloud/glu/pkg/token"
+)
+
+// Bash =======================================================================
+//
+
+// Bash is a Visitor that transpiles Glu statements into an equivalent bash
+// script. It supports variables, functions, conditionals, loops, logging and
+// the shell features of Glu. Constructs with no bash equivalent are reported
+// as Errors.
+//
+// Every Glu value is represented by a bash string. Expressions are transpiled
+// to bash words; any commands needed to compute a word, e.g. function calls,
+// are emitted before the statement that uses it. Functions return their
+// result in the '__glu_ret' variable.
+//
+// Bash strings have no type, so operators whose meaning depends on the types
+// of their operands, e.g. '+' and '==', are only transpiled where the kinds of
+// the operands can be inferred.
+//
+// Bash has no block scope, and the local variables of a function are visible
+// to the functions it calls, so a declaration that bash would confuse with
+// another variable is given a unique name.
+type Bash struct {
+	Errors []*Error
+	// output
+	builder *strings.Builder
+	depth   int
+	helpers map[string]bool
+	temps   int
+	// declarations
+	functions map[string]*ast.FnStmt
+	globals   map[string]bool
+	names     map[*token.Token]string
+	kinds     map[string]kind
+	scopes    []map[string]string
+	// enclosing function and loops
+	function   *ast.FnStmt
+	increments []ast.Expr
+}
+
+// NewBash constructor.
+func NewBash() *Bash {
+	return &Bash{
+		functions: map[string]*ast.FnStmt{},
+		globals:   map[string]bool{},
+		names:     map[*token.Token]string{},
+		kinds:     map[string]kind{},
+	}
+}
+
+// Transpile returns the bash script equivalent to the statements. Any errors
+// are recorded in Errors.
+func (b *Bash) Transpile(stmts []ast.Stmt) string {
+	b.declare(stmts, false)
+	b.rename(stmts, []map[string]bool{{}}, false)
+	// The kinds of variables, parameters and function results are refined by
+	// each pass until they are stable. A kind only ever changes from pending
+	// to inferred to unknown, so the passes terminate.
+	for {
+		previous := map[string]kind{}
+		for name, k := range b.kinds {
+			previous[name] = k
+		}
+		script := b.transpile(stmts)
+		if sameKinds(previous, b.kinds) {
+			return script
+		}
+	}
+}
+
+func (b *Bash) transpile(stmts []ast.Stmt) string {
+	b.Errors = nil
+	b.helpers = map[string]bool{}
+	b.temps = 0
+	b.scopes = []map[string]string{{}}
+	body := b.capture(0, func() {
+		for _, stmt := range stmts {
+			b.statement(stmt)
+		}
+	})
+	var builder strings.Builder
+	builder.WriteString("#!/usr/bin/env bash\n")
+	builder.WriteString("# Generated by 'glu build --target bash'.\n")
+	for _, helper := range helpers {
+		if b.helpers[helper.name] {
+			builder.WriteString("\n")
+			builder.WriteString(helper.definition)
+		}
+	}
+	if body != "" {
+		builder.WriteString("\n")
+		builder.WriteString(body)
+	}
+	return builder.String()
+}
+
+// declare records the functions declared anywhere in the statements so that
+// they may be called before their declaration, and the names of the variables
+// declared outside of functions, which are global in bash.
+func (b *Bash) declare(stmts []ast.Stmt, local bool) {
+	for _, stmt := range stmts {
+		switch s := stmt.(type) {
+		case *ast.VariableStmt:
+			if !local {
+				b.globals[s.Name.Lexeme] = true
+			}
+		case *ast.FnStmt:
+			b.functions[s.Name.Lexeme] = s
+			b.declare(s.Body, true)
+		case *ast.BlockStmt:
+			b.declare(s.Stmts, local)
+		case *ast.ExportStmt:
+			b.declare([]ast.Stmt{s.Declaration}, local)
+		case *ast.IfStmt:
+			b.declare([]ast.Stmt{s.ThenBranch}, local)
+			if s.ElseBranch != nil {
+				b.declare([]ast.Stmt{s.ElseBranch}, local)
+			}
+		case *ast.WhileStmt:
+			b.declare([]ast.Stmt{s.Body}, local)
+		}
+	}
+}
+
+// rename gives a unique name to each declaration that shadows a variable of an
+// enclosing scope, and to each declaration local to a function that has the
+// name of a global variable.
+func (b *Bash) rename(stmts []ast.Stmt, scopes []map[string]bool, local bool) {
+	for _, stmt := range stmts {
+		switch s := stmt.(type) {
+		case *ast.VariableStmt:
+			b.bind(s.Name, scopes, local)
+		case *ast.FnStmt:
+			// The parameters and the body share the scope of the function.
+			scopes := enclose(scopes)
+			for _, param := range s.Params {
+				b.bind(param, scopes, true)
+			}
+			b.rename(s.Body, scopes, true)
+		case *ast.BlockStmt:
+			b.rename(s.Stmts, enclose(scopes), local)
+		case *ast.ExportStmt:
+			b.rename([]ast.Stmt{s.Declaration}, scopes, local)
+		case *ast.IfStmt:
+			b.rename([]ast.Stmt{s.ThenBranch}, scopes, local)
+			if s.ElseBranch != nil {
+				b.rename([]ast.Stmt{s.ElseBranch}, scopes, local)
+			}
+		case *ast.WhileStmt:
+			b.rename([]ast.Stmt{s.Body}, scopes, local)
+		}
+	}
+}
+
+// bind declares a name in the innermost scope, renaming it if required.
+func (b *Bash) bind(name *token.Token, scopes []map[string]bool, local bool) {
+	shadows := local && b.globals[name.Lexeme]
+	for _, scope := range scopes[:len(scopes)-1] {
+		shadows = shadows || scope[name.Lexeme]
+	}
+	if shadows {
+		b.names[name] = fmt.Sprintf("%s__%d", name.Lexeme, len(b.names)+1)
+	}
+	scopes[len(scopes)-1][name.Lexeme] = true
+}
+
+// enclose returns the scopes with a new innermost scope.
+func enclose(scopes []map[string]bool) []map[string]bool {
+	return append(scopes[:len(scopes):len(scopes)], map[string]bool{})
+}
+
+// define declares a variable in the current scope of the transpiled script
+// and returns its bash name.
+func (b *Bash) define(name *token.Token) string {
+	b.scopes[len(b.scopes)-1][name.Lexeme] = b.bashName(name)
+	return b.bashName(name)
+}
+
+// lookup returns the bash name of the variable a name refers to.
+func (b *Bash) lookup(name string) string {
+	for idx := len(b.scopes) - 1; idx >= 0; idx-- {
+		if bashName, ok := b.scopes[idx][name]; ok {
+			return bashName
+		}
+	}
+	return name
+}
+
+// bashName returns the bash name of a declaration.
+func (b *Bash) bashName(name *token.Token) string {
+	if bashName, ok := b.names[name]; ok {
+		return bashName
+	}
+	return name.Lexeme
+}
+
+// Kinds ======================================================================
+//
+
+// kind is the type of a value where it can be inferred statically.
+type kind int
+
+const (
+	unknownKind kind = iota
+	numberKind
+	stringKind
+	boolKind
+	nilKind
+	// pendingKind is the kind of a variable that has not been assigned a
+	// value by the passes so far.
+	pendingKind
+)
+
+// assign records the kind of a value assigned to a variable. A variable that
+// is assigned values of different kinds has an unknown kind.
+func (b *Bash) assign(name string, k kind) {
+	if k == pendingKind {
+		return
+	}
+	if existing, ok := b.kinds[name]; ok && existing != k {
+		k = unknownKind
+	}
+	b.kinds[name] = k
+}
+
+// kindOf returns the kind of a variable.
+func (b *Bash) kindOf(name string) kind {
+	if k, ok := b.kinds[name]; ok {
+		return k
+	}
+	return pendingKind
+}
+
+// result returns the name under which the kind of the result of a function is
+// recorded. It cannot clash with the name of a variable.
+func result(fn string) string {
+	return fn + "()"
+}
+
+// known panics if the kind of the operand of an operator is not inferred.
+func known(operator *token.Token, v value) {
+	if v.kind == unknownKind || v.kind == pendingKind {
+		msg := fmt.Sprintf("The kind of an operand of '%s' cannot be inferred by the bash target.",
+			operator.Lexeme)
+		panic(NewError(operator, msg))
+	}
+}
+
+func sameKinds(k1, k2 map[string]kind) bool {
+	if len(k1) != len(k2) {
+		return false
+	}
+	for name, k := range k1 {
+		if k2[name] != k {
+			return false
+		}
+	}
+	return true
+}
+
+// Values =====================================================================
+//
+
+// value is the bash representation of a Glu expression.
+type value struct {
+	// word is a bash word that expands to the value.
+	word string
+	// arith is an arithmetic expression for the value, if it may be numeric.
+	arith string
+	kind  kind
+}
+
+func (b *Bash) value(expr ast.Expr) value {
+	return expr.Accept(b).(value)
+}
+
+// arith returns an arithmetic expression for the operand of an arithmetic
+// operator.
+func (b *Bash) arith(operator *token.Token, v value) string {
+	known(operator, v)
+	if v.kind != numberKind {
+		panic(NewError(operator, "Operands must be numbers."))
+	}
+	if v.arith == "" {
+		v = b.temp(v.word, v.kind)
+	}
+	return v.arith
+}
+
+// arithmetic returns the value of an arithmetic expression.
+func arithmetic(expr string) value {
+	return value{word: fmt.Sprintf("$((%s))", expr), arith: fmt.Sprintf("(%s)", expr), kind: numberKind}
+}
+
+// tempName returns the name of a new temporary variable.
+func (b *Bash) tempName() string {
+	b.temps++
+	return fmt.Sprintf("__glu_t%d", b.temps)
+}
+
+// temp assigns a word to a new temporary variable and returns its value.
+func (b *Bash) temp(word string, k kind) value {
+	name := b.tempName()
+	b.emit(fmt.Sprintf("%s%s=%s", b.local(), name, word))
+	return value{word: fmt.Sprintf("\"$%s\"", name), arith: name, kind: k}
+}
+
+// boolean returns a boolean value set by a condition.
+func (b *Bash) boolean(condition string) value {
+	name := b.tempName()
+	if b.function != nil {
+		b.emit(fmt.Sprintf("local %s", name))
+	}
+	b.emit(fmt.Sprintf("if %s; then %s=true; else %s=false; fi", condition, name, name))
+	return value{word: fmt.Sprintf("\"$%s\"", name), kind: boolKind}
+}
+
+// local returns the declaration prefix of a variable in the current scope.
+func (b *Bash) local() string {
+	if b.function != nil {
+		return "local "
+	}
+	return ""
+}
+
+// quote returns a single quoted bash word for a string.
+func quote(s string) string {
+	return fmt.Sprintf("'%s'", strings.ReplaceAll(s, "'", `'\''`))
+}
+
+// Conditions =================================================================
+//
+
+// condition returns a bash command whose exit status is the truthiness of the
+// expression.
+func (b *Bash) condition(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Grouping:
+		return b.condition(e.Expr)
+	case *ast.Literal:
+		if e.Value == true {
+			return "true"
+		}
+		if e.Value == false || e.Value == nil {
+			return "false"
+		}
+	case *ast.Unary:
+		if e.Operator.Type == token.Not {
+			return negate(b.condition(e.Right))
+		}
+	case *ast.Logical:
+		return b.logicalCondition(e)
+	case *ast.Binary:
+		switch e.Operator.Type {
+		case token.LessThan, token.LessThanOrEqual, token.GreaterThan, token.GreaterThanOrEqual:
+			left, right := b.value(e.Left), b.value(e.Right)
+			return fmt.Sprintf("((%s %s %s))",
+				b.arith(e.Operator, left), e.Operator.Lexeme, b.arith(e.Operator, right))
+		case token.EqualEqual, token.NotEqual:
+			left, right := b.value(e.Left), b.value(e.Right)
+			known(e.Operator, left)
+			known(e.Operator, right)
+			if left.kind != right.kind {
+				// Values of different kinds are never equal.
+				if e.Operator.Type == token.NotEqual {
+					return "true"
+				}
+				return "false"
+			}
+			if left.kind == numberKind {
+				return fmt.Sprintf("((%s %s %s))", b.arith(e.Operator, left), e.Operator.Lexeme,
+					b.arith(e.Operator, right))
+			}
+			operator := "="
+			if e.Operator.Type == token.NotEqual {
+				operator = "!="
+			}
+			return fmt.Sprintf("[ %s %s %s ]", left.word, operator, right.word)
+		}
+	}
+	return b.truthy(b.value(expr))
+}
+
+// logicalCondition returns the condition of an 'and' or 'or' expression. The
+// right operand is only evaluated if it is needed.
+func (b *Bash) logicalCondition(expr *ast.Logical) string {
+	left := b.condition(expr.Left)
+	var right string
+	setup := b.capture(b.depth+1, func() {
+		right = b.condition(expr.Right)
+	})
+	operator := "&&"
+	if expr.Operator.Type == token.Or {
+		operator = "||"
+	}
+	if setup == "" {
+		return fmt.Sprintf("{ %s %s %s; }", left, operator, right)
+	}
+	// The right operand needs commands to evaluate, so it is evaluated in
+	// the branch of an 'if' that is only taken if it decides the outcome.
+	name, decided, undecided := b.tempName(), "false", "true"
+	if expr.Operator.Type == token.Or {
+		left, right = negate(left), negate(right)
+		decided, undecided = "true", "false"
+	}
+	b.emit(fmt.Sprintf("%s%s=%s", b.local(), name, decided))
+	b.emit(fmt.Sprintf("if %s; then", left))
+	b.builder.WriteString(setup)
+	b.depth++
+	b.emit(fmt.Sprintf("if %s; then %s=%s; fi", right, name, undecided))
+	b.depth--
+	b.emit("fi")
+	return fmt.Sprintf("[ \"$%s\" = true ]", name)
+}
+
+// truthy returns a bash command whose exit status is the truthiness of the
+// value.
+func (b *Bash) truthy(v value) string {
+	switch v.kind {
+	case boolKind:
+		return fmt.Sprintf("[ %s = true ]", v.word)
+	case numberKind, stringKind:
+		return "true"
+	case nilKind:
+		return "false"
+	}
+	b.helpers[truthyHelper] = true
+	return fmt.Sprintf("%s %s", truthyHelper, v.word)
+}
+
+func negate(condition string) string {
+	if strings.HasPrefix(condition, "! ") {
+		return strings.TrimPrefix(condition, "! ")
+	}
+	return "! " + condition
+}
+
+// Statements =================================================================
+//
+
+// statement transpiles a statement. Errors are recorded and transpilation
+// continues with the next statement.
+func (b *Bash) statement(stmt ast.Stmt) {
+	depth, function, increments, scopes := b.depth, b.function, b.increments, b.scopes
+	defer func() {
+		if r := recover(); r != nil {
+			err, ok := r.(*Error)
+			if !ok {
+				panic(r)
+			}
+			b.Errors = append(b.Errors, err)
+			b.depth, b.function, b.increments, b.scopes = depth, function, increments, scopes
+		}
+	}()
+	stmt.Accept(b)
+}
+
+// body transpiles the statements of a compound statement one level deeper.
+// An empty body is a ':' command.
+func (b *Bash) body(stmts ...ast.Stmt) {
+	text := b.capture(b.depth+1, func() {
+		for _, stmt := range stmts {
+			b.statement(stmt)
+		}
+	})
+	if text == "" {
+		text = fmt.Sprintf("%s:\n", indent(b.depth+1))
+	}
+	b.builder.WriteString(text)
+}
+
+// VisitBlockStmt transpiles the node. Bash has no block scope, so the
+// statements are transpiled in place, and the declarations that shadow a
+// variable are renamed.
+func (b *Bash) VisitBlockStmt(stmt *ast.BlockStmt) interface{} {
+	b.scopes = append(b.scopes, map[string]string{})
+	for _, s := range stmt.Stmts {
+		b.statement(s)
+	}
+	b.scopes = b.scopes[:len(b.scopes)-1]
+	return nil
+}
+
+// VisitBreakStmt transpiles the node.
+func (b *Bash) VisitBreakStmt(stmt *ast.BreakStmt) interface{} {
+	b.emit("break")
+	return nil
+}
+
+// VisitClassStmt transpiles the node.
+func (b *Bash) VisitClassStmt(stmt *ast.ClassStmt) interface{} {
+	panic(unsupported(stmt.Name, "Classes"))
+}
+
+// VisitContinueStmt transpiles the node. The increment of an enclosing 'for'
+// loop is run before continuing.
+func (b *Bash) VisitContinueStmt(stmt *ast.ContinueStmt) interface{} {
+	if increment := b.increments[len(b.increments)-1]; increment != nil {
+		b.expressionStatement(increment)
+	}
+	b.emit("continue")
+	return nil
+}
+
+// VisitExecStmt transpiles the node.
+func (b *Bash) VisitExecStmt(stmt *ast.ExecStmt) interface{} {
+	var words []string
+	for _, argument := range stmt.Arguments {
+		words = append(words, b.value(argument).word)
+	}
+	for _, redirect := range stmt.Redirects {
+		var fd string
+		if redirect.FD == 2 {
+			fd = "2"
+		}
+		switch {
+		case redirect.Target == nil:
+			words = append(words, fmt.Sprintf("%s>&%d", fd, redirect.TargetFD))
+		case redirect.Operator.Type == token.LessThan:
+			words = append(words, fmt.Sprintf("< %s", b.value(redirect.Target).word))
+		default:
+			target := b.value(redirect.Target).word
+			words = append(words, fmt.Sprintf("%s%s %s", fd, redirect.Operator.Lexeme, target))
+		}
+	}
+	b.emit(strings.Join(words, " "))
+	return nil
+}
+
+// VisitExportStmt transpiles the node. A script has no importers, so the
+// declaration is transpiled as if it were not exported.
+func (b *Bash) VisitExportStmt(stmt *ast.ExportStmt) interface{} {
+	b.statement(stmt.Declaration)
+	return nil
+}
+
+// VisitExprStmt transpiles the node.
+func (b *Bash) VisitExprStmt(stmt *ast.ExprStmt) interface{} {
+	b.expressionStatement(stmt.Expr)
+	return nil
+}
+
+// expressionStatement transpiles an expression evaluated for its effects.
+func (b *Bash) expressionStatement(expr ast.Expr) {
+	switch e := expr.(type) {
+	case *ast.Call:
+		b.call(e)
+	default:
+		v := b.value(expr)
+		if strings.Contains(v.word, "$(") && !strings.Contains(v.word, "$((") {
+			// A command substitution is run for its effects.
+			b.emit(fmt.Sprintf(": %s", v.word))
+		}
+	}
+}
+
+// VisitIfStmt transpiles the node.
+func (b *Bash) VisitIfStmt(stmt *ast.IfStmt) interface{} {
+	b.emit(fmt.Sprintf("if %s; then", b.condition(stmt.Condition)))
+	b.body(stmt.ThenBranch)
+	if stmt.ElseBranch != nil {
+		b.emit("else")
+		b.body(stmt.ElseBranch)
+	}
+	b.emit("fi")
+	return nil
+}
+
+// VisitFnStmt transpiles the node.
+func (b *Bash) VisitFnStmt(fn *ast.FnStmt) interface{} {
+	b.emit(fmt.Sprintf("%s() {", fn.Name.Lexeme))
+	b.depth++
+	if fn.Script != nil {
+		// The script body of a '@bash' function is captured as its result.
+		b.emit(fmt.Sprintf("__glu_ret=\"$(%s)\"", fn.Script.Lexeme))
+		b.assign(result(fn.Name.Lexeme), stringKind)
+	} else {
+		// The kinds of the parameters are those of the arguments of the calls.
+		function, increments := b.function, b.increments
+		b.function, b.increments = fn, nil
+		b.scopes = append(b.scopes, map[string]string{})
+		if len(fn.Params) > 0 {
+			var params []string
+			for idx, param := range fn.Params {
+				params = append(params, fmt.Sprintf("%s=\"$%d\"", b.define(param), idx+1))
+			}
+			b.emit(fmt.Sprintf("local %s", strings.Join(params, " ")))
+		}
+		for _, stmt := range fn.Body {
+			b.statement(stmt)
+		}
+		if len(fn.Body) == 0 || !isReturn(fn.Body[len(fn.Body)-1]) {
+			b.emit("__glu_ret=nil")
+			b.assign(result(fn.Name.Lexeme), nilKind)
+		}
+		b.scopes = b.scopes[:len(b.scopes)-1]
+		b.function, b.increments = function, increments
+	}
+	b.depth--
+	b.emit("}")
+	return nil
+}
+
+// VisitImportStmt transpiles the node.
+func (b *Bash) VisitImportStmt(stmt *ast.ImportStmt) interface{} {
+	panic(unsupported(stmt.Keyword, "Imports"))
+}
+
+// VisitLogStmt transpiles the node.
+func (b *Bash) VisitLogStmt(stmt *ast.LogStmt) interface{} {
+	b.emit(fmt.Sprintf("printf '%%s' %s", b.value(stmt.Expr).word))
+	return nil
+}
+
+// VisitThrowStmt transpiles the node.
+func (b *Bash) VisitThrowStmt(stmt *ast.ThrowStmt) interface{} {
+	panic(unsupported(stmt.Keyword, "Exceptions"))
+}
+
+// VisitTryStmt transpiles the node.
+func (b *Bash) VisitTryStmt(stmt *ast.TryStmt) interface{} {
+	panic(unsupported(stmt.Keyword, "Exceptions"))
+}
+
+// VisitVariableStmt transpiles the node.
+func (b *Bash) VisitVariableStmt(stmt *ast.VariableStmt) interface{} {
+	v := value{word: "nil", kind: nilKind}
+	if stmt.Initialiser != nil {
+		v = b.value(stmt.Initialiser)
+	}
+	name := b.define(stmt.Name)
+	b.emit(fmt.Sprintf("%s%s=%s", b.local(), name, v.word))
+	b.assign(name, v.kind)
+	return nil
+}
+
+// VisitWhileStmt transpiles the node. A condition that needs commands to
+// evaluate is tested at the start of the loop body.
+func (b *Bash) VisitWhileStmt(stmt *ast.WhileStmt) interface{} {
+	var condition string
+	setup := b.capture(b.depth+1, func() {
+		condition = b.condition(stmt.Condition)
+	})
+	if setup == "" {
+		b.emit(fmt.Sprintf("while %s; do", condition))
+	} else {
+		b.emit("while true; do")
+		b.builder.WriteString(setup)
+		b.depth++
+		b.emit(fmt.Sprintf("if %s; then break; fi", negate(condition)))
+		b.depth--
+	}
+	b.increments = append(b.increments, stmt.Increment)
+	if stmt.Increment != nil {
+		b.body(stmt.Body, ast.NewExprStmt(stmt.Increment))
+	} else {
+		b.body(stmt.Body)
+	}
+	b.increments = b.increments[:len(b.increments)-1]
+	b.emit("done")
+	return nil
+}
+
+// VisitWithStmt transpiles the node.
+func (b *Bash) VisitWithStmt(stmt *ast.WithStmt) interface{} {
+	panic(unsupported(stmt.Keyword, "Environment overrides"))
+}
+
+// Expressions ================================================================
+//
+
+// VisitAssignExpr transpiles the node.
+func (b *Bash) VisitAssignExpr(expr *ast.Assign) interface{} {
+	v := b.value(expr.Value)
+	name := b.lookup(expr.Name.Lexeme)
+	b.emit(fmt.Sprintf("%s=%s", name, v.word))
+	b.assign(name, v.kind)
+	return value{word: fmt.Sprintf("\"$%s\"", name), arith: name, kind: v.kind}
+}
+
+// VisitBinaryExpr transpiles the node.
+func (b *Bash) VisitBinaryExpr(expr *ast.Binary) interface{} {
+	switch expr.Operator.Type {
+	case token.LessThan, token.LessThanOrEqual, token.GreaterThan, token.GreaterThanOrEqual,
+		token.EqualEqual, token.NotEqual:
+		return b.boolean(b.condition(expr))
+	case token.ForwardSlash:
+		msg := "Division '/' is not supported by the bash target; use '//'."
+		panic(NewError(expr.Operator, msg))
+	}
+	left, right := b.value(expr.Left), b.value(expr.Right)
+	if expr.Operator.Type == token.Plus && (left.kind == stringKind || right.kind == stringKind) {
+		return value{word: left.word + right.word, kind: stringKind}
+	}
+	l, r := b.arith(expr.Operator, left), b.arith(expr.Operator, right)
+	if expr.Operator.Type == token.ForwardSlashDual {
+		// Bash division truncates; Glu integer division floors.
+		return arithmetic(fmt.Sprintf("%s / %s - (%s %% %s != 0 && (%s < 0) != (%s < 0))",
+			l, r, l, r, l, r))
+	}
+	if expr.Operator.Type == token.Percent {
+		// Bash modulo truncates; Glu modulo floors.
+		return arithmetic(fmt.Sprintf("(%s %% %s + %s) %% %s", l, r, r, r))
+	}
+	return arithmetic(fmt.Sprintf("%s %s %s", l, expr.Operator.Lexeme, r))
+}
+
+// VisitCallExpr transpiles the node.
+func (b *Bash) VisitCallExpr(expr *ast.Call) interface{} {
+	name := b.call(expr)
+	return b.temp("\"$__glu_ret\"", b.kindOf(result(name)))
+}
+
+// call emits the call of a function declared in the program and returns its
+// name. The kinds of the arguments are recorded as those of the parameters.
+func (b *Bash) call(expr *ast.Call) string {
+	callee, ok := expr.Callee.(*ast.VarExpr)
+	if !ok || b.functions[callee.Name.Lexeme] == nil {
+		msg := "Only functions declared in the program can be called by the bash target."
+		panic(NewError(expr.Paren, msg))
+	}
+	fn := b.functions[callee.Name.Lexeme]
+	if len(expr.Arguments) != len(fn.Params) {
+		msg := fmt.Sprintf("Expected %d arguments, but, got %d.", len(fn.Params), len(expr.Arguments))
+		panic(NewError(expr.Paren, msg))
+	}
+	words := []string{callee.Name.Lexeme}
+	for idx, argument := range expr.Arguments {
+		v := b.value(argument)
+		words = append(words, v.word)
+		b.assign(b.bashName(fn.Params[idx]), v.kind)
+	}
+	b.emit(strings.Join(words, " "))
+	return callee.Name.Lexeme
+}
+
+// VisitCommandExpr transpiles the node.
+func (b *Bash) VisitCommandExpr(expr *ast.Command) interface{} {
+	return value{word: fmt.Sprintf("\"$(%s)\"", expr.Token.Lexeme), kind: stringKind}
+}
+
+// VisitConcatExpr transpiles the node.
+func (b *Bash) VisitConcatExpr(expr *ast.Concat) interface{} {
+	var builder strings.Builder
+	for _, part := range expr.Parts {
+		builder.WriteString(b.value(part).word)
+	}
+	return value{word: builder.String(), kind: stringKind}
+}
+
+// VisitEnvVarExpr transpiles the node.
+func (b *Bash) VisitEnvVarExpr(expr *ast.EnvVar) interface{} {
+	return value{word: fmt.Sprintf("\"$%s\"", expr.Name.Lexeme), kind: stringKind}
+}
+
+// VisitGetExpr transpiles the node.
+func (b *Bash) VisitGetExpr(expr *ast.Get) interface{} {
+	panic(unsupported(expr.Name, "Properties"))
+}
+
+// VisitGroupingExpr transpiles the node.
+func (b *Bash) VisitGroupingExpr(expr *ast.Grouping) interface{} {
+	return b.value(expr.Expr)
+}
+
+// VisitIndexExpr transpiles the node.
+func (b *Bash) VisitIndexExpr(expr *ast.Index) interface{} {
+	panic(unsupported(expr.Bracket, "Indexes"))
+}
+
+// VisitLambdaExpr transpiles the node.
+func (b *Bash) VisitLambdaExpr(expr *ast.Lambda) interface{} {
+	panic(unsupported(expr.Fn.Name, "Lambdas"))
+}
+
+// VisitListExpr transpiles the node.
+func (b *Bash) VisitListExpr(expr *ast.List) interface{} {
+	panic(unsupported(expr.Bracket, "Lists"))
+}
+
+// VisitLiteralExpr transpiles the node.
+func (b *Bash) VisitLiteralExpr(expr *ast.Literal) interface{} {
+	switch v := expr.Value.(type) {
+	case nil:
+		return value{word: "nil", kind: nilKind}
+	case bool:
+		return value{word: fmt.Sprintf("%t", v), kind: boolKind}
+	case string:
+		return value{word: quote(v), kind: stringKind}
+	}
+	return value{word: fmt.Sprintf("%v", expr.Value)}
+}
+
+// VisitLogicalExpr transpiles the node. Like Glu, the result is the value of
+// the operand that decided the outcome.
+func (b *Bash) VisitLogicalExpr(expr *ast.Logical) interface{} {
+	left, right := b.value(expr.Left), value{}
+	result := b.temp(left.word, left.kind)
+	setup := b.capture(b.depth+1, func() {
+		right = b.value(expr.Right)
+		b.emit(fmt.Sprintf("%s=%s", result.arith, right.word))
+	})
+	test := b.truthy(result)
+	if expr.Operator.Type == token.Or {
+		test = negate(test)
+	}
+	b.emit(fmt.Sprintf("if %s; then", test))
+	b.builder.WriteString(setup)
+	b.emit("fi")
+	switch {
+	case left.kind == pendingKind || right.kind == pendingKind:
+		result.kind = pendingKind
+	case left.kind != right.kind:
+		result.kind = unknownKind
+	}
+	return result
+}
+
+// VisitMapExpr transpiles the node.
+func (b *Bash) VisitMapExpr(expr *ast.Map) interface{} {
+	panic(unsupported(expr.Brace, "Maps"))
+}
+
+// VisitNumeralExpr transpiles the node.
+func (b *Bash) VisitNumeralExpr(expr *ast.Numeral) interface{} {
+	if _, ok := expr.Value.(int64); !ok {
+		panic(unsupported(expr.Token, "Floating point numbers"))
+	}
+	return value{word: expr.Token.Lexeme, arith: expr.Token.Lexeme, kind: numberKind}
+}
+
+// VisitReturnExpr transpiles the node.
+func (b *Bash) VisitReturnExpr(expr *ast.Return) interface{} {
+	if b.function == nil {
+		panic(NewError(expr.Keyword, "Cannot return from top-level code."))
+	}
+	v := value{word: "nil", kind: nilKind}
+	if expr.Value != nil {
+		v = b.value(expr.Value)
+	}
+	b.emit(fmt.Sprintf("__glu_ret=%s", v.word))
+	b.assign(result(b.function.Name.Lexeme), v.kind)
+	b.emit("return")
+	return nil
+}
+
+// VisitSetExpr transpiles the node.
+func (b *Bash) VisitSetExpr(expr *ast.Set) interface{} {
+	panic(unsupported(expr.Name, "Properties"))
+}
+
+// VisitSetEnvExpr transpiles the node.
+func (b *Bash) VisitSetEnvExpr(expr *ast.SetEnv) interface{} {
+	v := b.value(expr.Value)
+	name := expr.Name.Lexeme
+	if v.kind == nilKind {
+		b.emit(fmt.Sprintf("unset %s", name))
+	} else {
+		b.emit(fmt.Sprintf("export %s=%s", name, v.word))
+	}
+	return value{word: fmt.Sprintf("\"$%s\"", name), kind: stringKind}
+}
+
+// VisitSetIndexExpr transpiles the node.
+func (b *Bash) VisitSetIndexExpr(expr *ast.SetIndex) interface{} {
+	panic(unsupported(expr.Bracket, "Indexes"))
+}
+
+// VisitSliceExpr transpiles the node.
+func (b *Bash) VisitSliceExpr(expr *ast.Slice) interface{} {
+	panic(unsupported(expr.Bracket, "Slices"))
+}
+
+// VisitThisExpr transpiles the node.
+func (b *Bash) VisitThisExpr(expr *ast.This) interface{} {
+	panic(unsupported(expr.Keyword, "Classes"))
+}
+
+// VisitUnaryExpr transpiles the node.
+func (b *Bash) VisitUnaryExpr(expr *ast.Unary) interface{} {
+	if expr.Operator.Type == token.Not {
+		return b.boolean(negate(b.condition(expr.Right)))
+	}
+	operand := b.arith(expr.Operator, b.value(expr.Right))
+	return arithmetic(fmt.Sprintf("%s%s", expr.Operator.Lexeme, operand))
+}
+
+// VisitVarExpr transpiles the node.
+func (b *Bash) VisitVarExpr(expr *ast.VarExpr) interface{} {
+	name := b.lookup(expr.Name.Lexeme)
+	if _, ok := b.kinds[name]; !ok && b.functions[expr.Name.Lexeme] != nil {
+		panic(unsupported(expr.Name, "Function values"))
+	}
+	return value{word: fmt.Sprintf("\"$%s\"", name), arith: name, kind: b.kindOf(name)}
+}
+
+// Support Functions ==========================================================
+//
+
+// emit writes a line of the script at the current indentation.
+func (b *Bash) emit(line string) {
+	b.builder.WriteString(indent(b.depth))
+	b.builder.WriteString(line)
+	b.builder.WriteString("\n")
+}
+
+// capture returns the lines emitted by a function at the specified
+// indentation instead of writing them to the script.
+func (b *Bash) capture(depth int, fn func()) string {
+	enclosing, enclosingDepth := b.builder, b.depth
+	captured := &strings.Builder{}
+	b.builder, b.depth = captured, depth
+	defer func() {
+		b.builder, b.depth = enclosing, enclosingDepth
+	}()
+	fn()
+	return captured.String()
+}
+
+func isReturn(stmt ast.Stmt) bool {
+	_, ok := stmt.(*ast.Return)
+	return ok
+}
+
+func indent(depth int) string {
+	return strings.Repeat("  ", depth)
+}
+
+func unsupported(t *token.Token, what string) *Error {
+	return NewError(t, fmt.Sprintf("%s are not supported by the bash target.", what))
+}
+
+// Helpers ====================================================================
+//
+
+const truthyHelper = "__glu_truthy"
+
+// helpers are the bash functions the script may depend on, in the order they
+// are written.
+var helpers = []struct {
+	name       string
+	definition string
+}{
+	{truthyHelper, `__glu_truthy() {
+  [ "$1" != false ] && [ "$1" != nil ]
+}
+`},
+}
@@ -0,0 +1,153 @@
+package transpiler
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/templecloud/glu/pkg/lexer"
+	"github.com/templecloud/glu/pkg/parser"
+)
+
+func TestBash_Transpile(t *testing.T) {
+	header := "#!/usr/bin/env bash\n# Generated by 'glu build --target bash'.\n\n"
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"var x = 1; log x + 2;", "x=1\nprintf '%s' $((x + 2))\n"},
+		{"var s = \"it's\"; log \"${s}!\";", "s='it'\\''s'\nprintf '%s' \"$s\"'!'\n"},
+		{"if (1 < 2) log 1; else { }", "if ((1 < 2)); then\n  printf '%s' 1\nelse\n  :\nfi\n"},
+		{"func f(a) { return a; } f(1);",
+			"f() {\n  local a=\"$1\"\n  __glu_ret=\"$a\"\n  return\n}\nf 1\n"},
+		{"for (var i = 0; i < 3; i = i + 1) { if (i == 1) continue; log i; }",
+			"i=0\nwhile ((i < 3)); do\n  if ((i == 1)); then\n    i=$((i + 1))\n    continue\n  fi\n" +
+				"  printf '%s' \"$i\"\n  i=$((i + 1))\ndone\n"},
+		{"$A = nil; exec \"ls\", $HOME 2> \"/dev/null\" 2>&1;",
+			"unset A\n'ls' \"$HOME\" 2> '/dev/null' 2>&1\n"},
+		{"var a = 1; { var a = 2; log a; } log a;", "a=1\na__1=2\nprintf '%s' \"$a__1\"\nprintf '%s' \"$a\"\n"},
+	}
+	for idx, tt := range tests {
+		actual, errs := transpile(tt.input)
+		if len(errs) > 0 {
+			t.Fatalf("test[%d] - Unexpected errors: %v", idx, errs)
+		}
+		if header+tt.expected != actual {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, header+tt.expected, actual)
+		}
+	}
+}
+
+func TestBash_Run(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"var a = 1; var b = \"x\"; log a; log b; log a + 1; log b + a; log nil; log true;", "1x2x1niltrue"},
+		{"log 7 // 2; log -7 // 2; log 7 % 3; log 2 ** 10; log -(1 + 2); log ~0; log 1 << 4;", "3-411024-3-116"},
+		{"log -7 % 3; log \" \"; log 7 % -3; log \" \"; log -7 // 2 * 2 + -7 % 2;", "2 -2 -7"},
+		{"log 1 < 2; log 1 == 2; log \"a\" == \"a\"; log !true; log !nil;", "truefalsetruefalsetrue"},
+		{"log nil or \"d\"; log 1 and 2; log false and 1; log nil or nil;", "d2falsenil"},
+		{"var x = 1; x = x + \"a\"; log x;", "1a"},
+		{"func f(a) { return a + 1; } log f(1); log f(2);", "23"},
+		{"func f(a, b) { return a + b; } log f(\"5\", 1);", "51"},
+		{"log 1 == \"1\"; log 1 != \"1\"; log nil == false; var s = \"1\"; log s == \"1\";", "falsetruefalsetrue"},
+		{"func f(a) { return a; } log f(1) == 1; log f(1) + 1;", "true2"},
+		{"func fib(n) { if (n < 2) return n; return fib(n - 1) + fib(n - 2); } log fib(15);", "610"},
+		{"func even(n) { if (n == 0) return true; return odd(n - 1); } " +
+			"func odd(n) { if (n == 0) return false; return even(n - 1); } log even(7);", "false"},
+		{"func f() { log 1; } log f();", "1nil"},
+		{"var i = 0; while (i < 5) { i = i + 1; if (i == 2) continue; if (i == 4) break; log i; }", "13"},
+		{"for (var i = 0; i < 5; i = i + 1) { if (i % 2 == 0) continue; log i; }", "13"},
+		{"for (var i = 0; i < 3; i = i + 1) for (var j = 0; j < 3; j = j + 1) { if (j > i) break; log j; }", "001012"},
+		{"var n = 0; func inc() { n = n + 1; return n < 3; } while (inc()) log n;", "12"},
+		{"func t() { log \"t\"; return true; } log false and t(); log true or t(); log true and t();", "falsetruettrue"},
+		{"var s = \"\"; for (var i = 0; i < 3; i = i + 1) s = s + \"${i},\"; log s;", "0,1,2,"},
+		{"func @bash up(s) { echo \"$1\" | tr a-z A-Z } log up(\"glu\");", "GLU"},
+		{"$GLU_X = 42; log `echo $GLU_X`; log $GLU_X + 1;", "42421"},
+		{"exec \"printf\", \"%s\", \"a b\";", "a b"},
+		{"{ var x = 1; { log x; } }", "1"},
+		{"var a = 1; { var a = 2; log a; { var a = 3; log a; } log a; } log a;", "2321"},
+		{"func f(a) { if (a > 0) { var a = 2; log a; } log a; } f(1);", "21"},
+		{"var x = 1; func g() { return x; } func f(x) { var y = g(); return x + y; } log f(2);", "3"},
+	}
+	for idx, tt := range tests {
+		script, errs := transpile(tt.input)
+		if len(errs) > 0 {
+			t.Fatalf("test[%d] - Unexpected errors: %v", idx, errs)
+		}
+		out, err := exec.Command("bash", "-c", script).Output()
+		if err != nil {
+			t.Fatalf("test[%d] - Script failed: %v\n%s", idx, err, script)
+		}
+		actual := string(out)
+		if tt.expected != actual {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q\n%s", idx, tt.expected, actual, script)
+		}
+	}
+}
+
+func TestBashError_Transpile(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"var l = [1];", "Lists are not supported by the bash target."},
+		{"var m = {\"a\": 1};", "Maps are not supported by the bash target."},
+		{"class A {}", "Classes are not supported by the bash target."},
+		{"var f = (a) => a;", "Lambdas are not supported by the bash target."},
+		{"try { log 1; } catch (e) { }", "Exceptions are not supported by the bash target."},
+		{"throw \"x\";", "Exceptions are not supported by the bash target."},
+		{"with env {} { }", "Environment overrides are not supported by the bash target."},
+		{"import bash \"a.sh\";", "Imports are not supported by the bash target."},
+		{"log 1.5;", "Floating point numbers are not supported by the bash target."},
+		{"log 1 / 2;", "Division '/' is not supported by the bash target; use '//'."},
+		{"log \"a\" - 1;", "Operands must be numbers."},
+		{"func f(a) { return a + 1; } f(1); f(\"a\");",
+			"The kind of an operand of '+' cannot be inferred by the bash target."},
+		{"func f(a) { return a == 1; } f(1); f(\"1\");",
+			"The kind of an operand of '==' cannot be inferred by the bash target."},
+		{"func f(a) { return a < 1; }", "The kind of an operand of '<' cannot be inferred by the bash target."},
+		{"var x = 1; x = \"a\"; log x + 1;", "The kind of an operand of '+' cannot be inferred by the bash target."},
+		{"log len(\"a\");", "Only functions declared in the program can be called by the bash target."},
+		{"func f(a) {} f();", "Expected 1 arguments, but, got 0."},
+		{"func f() {} var g = f;", "Function values are not supported by the bash target."},
+		{"return 1;", "Cannot return from top-level code."},
+	}
+	for idx, tt := range tests {
+		_, errs := transpile(tt.input)
+		if len(errs) == 0 {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%v", idx, tt.expected, nil)
+		}
+		if tt.expected != errs[0].message {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, errs[0].message)
+		}
+	}
+}
+
+func TestBashError_Position(t *testing.T) {
+	_, errs := transpile("log 1;\nvar l = [1];\nlog 2.5;\ntry {} finally {}")
+	expected := []string{
+		"Lists are not supported by the bash target. At Line: 2, Column: 9, Token: {LeftBracket: '['}.",
+		"Floating point numbers are not supported by the bash target. At Line: 3, Column: 5, Token: {Number: '2.5'}.",
+		"Exceptions are not supported by the bash target. At Line: 4, Column: 1, Token: {try: 'try'}.",
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("Wrong number of errors. Expected=%d, Actual=%d", len(expected), len(errs))
+	}
+	for idx, err := range errs {
+		if expected[idx] != err.Error() {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, expected[idx], err.Error())
+		}
+	}
+}
+
+// Support Functions ==========================================================
+//
+
+func transpile(input string) (string, []*Error) {
+	tokens, _ := lexer.New(input).ScanTokens()
+	stmts := parser.New(tokens).Parse()
+	bash := NewBash()
+	script := bash.Transpile(stmts)
+	return script, bash.Errors
+}
@@ -0,0 +1,121 @@
+package vm
+
+import (
+	"fmt"
+
+	"github.com/templecloud/glu/pkg/compiler"
+	"github.com/templecloud/glu/pkg/interpreter"
+	"github.com/templecloud/glu/pkg/token"
+)
+
+// Closure ====================================================================
+//
+
+// Closure is a compiled function with the variables it captures. Globals is
+// the environment of the module the function was declared in.
+type Closure struct {
+	Function *compiler.Function
+	Upvalues []*Upvalue
+	Globals  *interpreter.Environment
+}
+
+// NewClosure constructor.
+func NewClosure(fn *compiler.Function, globals *interpreter.Environment) *Closure {
+	return &Closure{
+		Function: fn,
+		Upvalues: make([]*Upvalue, fn.UpvalueCount),
+		Globals:  globals,
+	}
+}
+
+func (c *Closure) String() string {
+	return c.Function.String()
+}
+
+// Upvalue ====================================================================
+//
+
+// Upvalue is a captured variable. It refers to a slot of the stack while the
+// variable is in scope, and holds the value once it is closed.
+type Upvalue struct {
+	slot   int
+	open   bool
+	closed interface{}
+}
+
+// Class ======================================================================
+//
+
+// Class is a compiled class. Methods are closures, or attributed functions
+// run by the interpreter. Fields initialises the fields of a new instance.
+type Class struct {
+	Name    string
+	Fields  *Closure
+	Methods map[string]interface{}
+}
+
+// NewClass constructor.
+func NewClass(name string) *Class {
+	return &Class{Name: name, Methods: map[string]interface{}{}}
+}
+
+// arity returns the number of parameters the class initializer has.
+func (c *Class) arity() int {
+	switch init := c.Methods["init"].(type) {
+	case *Closure:
+		return init.Function.Arity
+	case interpreter.GluCallable:
+		return init.Arity()
+	}
+	return 0
+}
+
+func (c *Class) String() string {
+	return c.Name
+}
+
+// Instance ===================================================================
+//
+
+// Instance is an instance of a Class.
+type Instance struct {
+	Class  *Class
+	Fields map[string]interface{}
+}
+
+// NewInstance constructor.
+func NewInstance(class *Class) *Instance {
+	return &Instance{Class: class, Fields: map[string]interface{}{}}
+}
+
+// Get returns the named property of the instance. Fields shadow methods.
+func (i *Instance) Get(name *token.Token) interface{} {
+	if value, ok := i.Fields[name.Lexeme]; ok {
+		return value
+	}
+	if method, ok := i.Class.Methods[name.Lexeme]; ok {
+		if closure, ok := method.(*Closure); ok {
+			return &BoundMethod{Receiver: i, Method: closure}
+		}
+		return method
+	}
+	err := fmt.Sprintf("Undefined property '%s'.", name.Lexeme)
+	panic(interpreter.NewError(name, err))
+}
+
+func (i *Instance) String() string {
+	return fmt.Sprintf("%s instance", i.Class.Name)
+}
+
+// BoundMethod ================================================================
+//
+
+// BoundMethod is a method with 'this' bound to an instance.
+type BoundMethod struct {
+	Receiver *Instance
+	Method   *Closure
+}
+
+func (bm *BoundMethod) String() string {
+	return bm.Method.String()
+}
@@ -0,0 +1,721 @@
+package vm
+
+import (
+	"fmt"
+
+	"github.com/templecloud/glu/pkg/ast"
+	"github.com/templecloud/glu/pkg/compiler"
+	"github.com/templecloud/glu/pkg/interpreter"
+	"github.com/templecloud/glu/pkg/token"
+)
+
+// VM =========================================================================
+//
+
+// VM is a stack based virtual machine that executes compiled Glu functions.
+// The semantics of values, and the process environment, commands, jobs and
+// modules, are shared with the Interpreter, so that both produce the same
+// results and errors.
+type VM struct {
+	interpreter *interpreter.Interpreter
+	stack       []interface{}
+	frames      []*frame
+	// blocks is the stack of active handlers, 'with' statements and names
+	// scopes, innermost last.
+	blocks []*block
+	// names is the number of names scopes in blocks.
+	names int
+	// upvalues are the open upvalues, ordered by slot.
+	upvalues []*Upvalue
+}
+
+// New constructor.
+func New(interp *interpreter.Interpreter) *VM {
+	return &VM{interpreter: interp}
+}
+
+// frame is the activation of a closure. Slot 0 of the frame, at base, holds
+// the callee or 'this'. The name and call-site token of the callee are
+// recorded in the stack of errors that unwind through the frame; a script
+// has no call site.
+type frame struct {
+	closure *Closure
+	ip      int
+	base    int
+	name    string
+	token   *token.Token
+}
+
+// blockKind is the kind of a block.
+type blockKind int
+
+const (
+	handlerBlock blockKind = iota
+	withBlock
+	namesBlock
+)
+
+// block is an active handler, 'with' statement or names scope of a frame.
+type block struct {
+	kind  blockKind
+	frame int
+	// height is the height of the stack, and handler the offset of the code,
+	// to resume at on an error.
+	height  int
+	handler int
+	// restore restores the process environment after a 'with' statement.
+	restore func()
+	// values holds the functions imported into a names scope.
+	values map[string]interface{}
+}
+
+// Run executes a compiled script in the current module and returns the result
+// or the first runtime error it encountered.
+func (vm *VM) Run(fn *compiler.Function) (result interface{}, err *interpreter.Error) {
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(*interpreter.Error)
+			if !ok {
+				panic(r)
+			}
+			err = e
+		}
+	}()
+	closure := NewClosure(fn, vm.interpreter.Module.Globals())
+	result = vm.invoke(closure, closure, "", nil)
+	return
+}
+
+// invoke calls the closure with the value of slot 0 and runs it to
+// completion.
+func (vm *VM) invoke(closure *Closure, slot0 interface{}, name string, at *token.Token) interface{} {
+	if at != nil {
+		vm.checkDepth(at)
+	}
+	vm.stack = append(vm.stack, slot0)
+	vm.frames = append(vm.frames, &frame{
+		closure: closure,
+		base:    len(vm.stack) - 1,
+		name:    name,
+		token:   at,
+	})
+	base := len(vm.frames) - 1
+	for {
+		if result, done := vm.execute(base); done {
+			return result
+		}
+	}
+}
+
+// execute runs the frames from the base until it returns. If an error is
+// caught by a handler, done is false and execution resumes at the handler.
+func (vm *VM) execute(base int) (result interface{}, done bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			err, ok := r.(*interpreter.Error)
+			if !ok || !vm.handle(err, base) {
+				panic(r)
+			}
+		}
+	}()
+	return vm.run(base), true
+}
+
+// handle unwinds the stack to the innermost handler of the frames from the
+// base, and returns true if there is one. Frames are recorded in the stack of
+// the error as they are unwound, and 'with' statements are restored.
+func (vm *VM) handle(err *interpreter.Error, base int) bool {
+	for len(vm.blocks) > 0 {
+		b := vm.blocks[len(vm.blocks)-1]
+		if b.frame < base {
+			break
+		}
+		vm.blocks = vm.blocks[:len(vm.blocks)-1]
+		switch b.kind {
+		case withBlock:
+			b.restore()
+		case namesBlock:
+			vm.names--
+		case handlerBlock:
+			vm.unwind(err, b.frame+1)
+			vm.truncate(b.height)
+			vm.stack = append(vm.stack, err)
+			vm.frames[b.frame].ip = b.handler
+			return true
+		}
+	}
+	height := vm.frames[base].base
+	vm.unwind(err, base)
+	vm.truncate(height)
+	return false
+}
+
+// unwind discards the frames above the index, recording them in the stack of
+// the error.
+func (vm *VM) unwind(err *interpreter.Error, index int) {
+	for len(vm.frames) > index {
+		f := vm.frames[len(vm.frames)-1]
+		if f.token != nil {
+			err.Locate(f.token)
+			err.Unwind(interpreter.NewFrame(f.name, f.token))
+		}
+		vm.frames = vm.frames[:len(vm.frames)-1]
+	}
+}
+
+// truncate discards the stack above the height, closing its upvalues.
+func (vm *VM) truncate(height int) {
+	vm.closeUpvalues(height)
+	vm.stack = vm.stack[:height]
+}
+
+// run executes instructions until the frame at the base returns.
+func (vm *VM) run(base int) interface{} {
+	f := vm.frames[len(vm.frames)-1]
+	chunk := f.closure.Function.Chunk
+	code := chunk.Code
+	for {
+		op := compiler.OpCode(code[f.ip])
+		at := chunk.Tokens[f.ip]
+		f.ip++
+		switch op {
+		case compiler.OpConstant:
+			vm.push(chunk.Constants[vm.read16(f)])
+		case compiler.OpNil:
+			vm.push(nil)
+		case compiler.OpTrue:
+			vm.push(true)
+		case compiler.OpFalse:
+			vm.push(false)
+		case compiler.OpPop:
+			vm.pop()
+		case compiler.OpPopLocals:
+			count := int(vm.read8(f))
+			vm.underflow(count)
+			vm.truncate(len(vm.stack) - count)
+
+		// Variables
+		case compiler.OpGetLocal:
+			vm.push(vm.stack[f.base+int(vm.read8(f))])
+		case compiler.OpSetLocal:
+			vm.stack[f.base+int(vm.read8(f))] = vm.peek(0)
+		case compiler.OpGetUpvalue:
+			upvalue := f.closure.Upvalues[vm.read8(f)]
+			if upvalue.open {
+				vm.push(vm.stack[upvalue.slot])
+			} else {
+				vm.push(upvalue.closed)
+			}
+		case compiler.OpSetUpvalue:
+			upvalue := f.closure.Upvalues[vm.read8(f)]
+			if upvalue.open {
+				vm.stack[upvalue.slot] = vm.peek(0)
+			} else {
+				upvalue.closed = vm.peek(0)
+			}
+		case compiler.OpGetGlobal:
+			name := chunk.Constants[vm.read16(f)].(string)
+			vm.push(vm.global(f, name, at))
+		case compiler.OpSetGlobal:
+			name := chunk.Constants[vm.read16(f)].(string)
+			vm.assignGlobal(f, name, at, vm.peek(0))
+		case compiler.OpDefineGlobal:
+			name := chunk.Constants[vm.read16(f)].(string)
+			f.closure.Globals.Define(name, vm.pop())
+
+		// Properties and collections
+		case compiler.OpGetProperty:
+			vm.read16(f)
+			vm.push(interpreter.Property(at, vm.pop()))
+		case compiler.OpCheckInstance:
+			if _, ok := vm.peek(0).(*Instance); !ok {
+				panic(interpreter.NewError(at, "Only instances have fields."))
+			}
+		case compiler.OpSetProperty:
+			name := chunk.Constants[vm.read16(f)].(string)
+			value := vm.pop()
+			instance := vm.pop().(*Instance)
+			instance.Fields[name] = value
+			vm.push(value)
+		case compiler.OpGetIndex:
+			index := vm.pop()
+			object := vm.pop()
+			vm.push(interpreter.Index(at, object, index))
+		case compiler.OpSetIndex:
+			value := vm.pop()
+			index := vm.pop()
+			object := vm.pop()
+			interpreter.SetIndex(at, object, index, value)
+			vm.push(value)
+		case compiler.OpSlice:
+			flags := vm.read8(f)
+			var start, end interface{}
+			if flags&2 != 0 {
+				end = vm.pop()
+			}
+			if flags&1 != 0 {
+				start = vm.pop()
+			}
+			vm.push(interpreter.Slice(at, vm.pop(), start, end))
+
+		// Operators
+		case compiler.OpEqual:
+			right := vm.pop()
+			vm.stack[len(vm.stack)-1] = interpreter.IsEqual(vm.peek(0), right)
+		case compiler.OpNotEqual:
+			right := vm.pop()
+			vm.stack[len(vm.stack)-1] = !interpreter.IsEqual(vm.peek(0), right)
+		case compiler.OpLess, compiler.OpLessEqual, compiler.OpGreater, compiler.OpGreaterEqual:
+			right := vm.pop()
+			vm.stack[len(vm.stack)-1] = compare(op, at, vm.peek(0), right)
+		case compiler.OpAdd, compiler.OpSubtract:
+			right := vm.pop()
+			vm.stack[len(vm.stack)-1] = arithmetic(op, at, vm.peek(0), right)
+		case compiler.OpMultiply, compiler.OpDivide, compiler.OpFloorDivide,
+			compiler.OpModulo, compiler.OpPower, compiler.OpBitAnd,
+			compiler.OpBitXor, compiler.OpShiftLeft, compiler.OpShiftRight:
+			right := vm.pop()
+			vm.stack[len(vm.stack)-1] = interpreter.Binary(at, vm.peek(0), right)
+		case compiler.OpPipe:
+			count := int(vm.read8(f))
+			operators := chunk.Constants[vm.read16(f)].([]*token.Token)
+			values := vm.popN(count)
+			vm.push(interpreter.Pipe(operators, values, vm.interpreter.Process))
+		case compiler.OpNot:
+			vm.stack[len(vm.stack)-1] = !interpreter.IsTruthy(vm.peek(0))
+		case compiler.OpNegate, compiler.OpBitNot:
+			vm.stack[len(vm.stack)-1] = interpreter.Unary(at, vm.peek(0))
+
+		// Control flow
+		case compiler.OpJump:
+			offset := vm.read16(f)
+			f.ip += offset
+		case compiler.OpJumpIfFalse:
+			offset := vm.read16(f)
+			if !interpreter.IsTruthy(vm.peek(0)) {
+				f.ip += offset
+			}
+		case compiler.OpJumpIfTrue:
+			offset := vm.read16(f)
+			if interpreter.IsTruthy(vm.peek(0)) {
+				f.ip += offset
+			}
+		case compiler.OpLoop:
+			offset := vm.read16(f)
+			f.ip -= offset
+
+		// Functions
+		case compiler.OpCall:
+			count := int(vm.read8(f))
+			if vm.call(vm.peek(count), count, at) {
+				f = vm.frames[len(vm.frames)-1]
+				chunk = f.closure.Function.Chunk
+				code = chunk.Code
+			}
+		case compiler.OpClosure:
+			fn := chunk.Constants[vm.read16(f)].(*compiler.Function)
+			closure := NewClosure(fn, f.closure.Globals)
+			for idx := range closure.Upvalues {
+				isLocal := vm.read8(f) == 1
+				index := int(vm.read8(f))
+				if isLocal {
+					closure.Upvalues[idx] = vm.capture(f.base + index)
+				} else {
+					closure.Upvalues[idx] = f.closure.Upvalues[index]
+				}
+			}
+			vm.push(closure)
+		case compiler.OpScript:
+			decl := chunk.Constants[vm.read16(f)].(*ast.FnStmt)
+			vm.push(interpreter.NewGluFn(decl, nil))
+		case compiler.OpReturn:
+			result := vm.pop()
+			vm.truncate(f.base)
+			vm.frames = vm.frames[:len(vm.frames)-1]
+			if len(vm.frames) == base {
+				return result
+			}
+			vm.push(result)
+			f = vm.frames[len(vm.frames)-1]
+			chunk = f.closure.Function.Chunk
+			code = chunk.Code
+
+		// Classes
+		case compiler.OpClass:
+			name := chunk.Constants[vm.read16(f)].(string)
+			vm.push(NewClass(name))
+		case compiler.OpFields:
+			fields := vm.pop().(*Closure)
+			vm.peek(0).(*Class).Fields = fields
+		case compiler.OpSetField:
+			name := chunk.Constants[vm.read16(f)].(string)
+			vm.stack[f.base].(*Instance).Fields[name] = vm.pop()
+		case compiler.OpMethod:
+			name := chunk.Constants[vm.read16(f)].(string)
+			method := vm.pop()
+			vm.peek(0).(*Class).Methods[name] = method
+
+		// Literals and the shell
+		case compiler.OpList:
+			elements := vm.popN(vm.read16(f))
+			vm.push(interpreter.NewGluList(elements))
+		case compiler.OpMap:
+			vm.push(interpreter.NewGluMap())
+		case compiler.OpMapEntry:
+			value := vm.pop()
+			key := vm.pop()
+			vm.peek(0).(*interpreter.GluMap).Set(at, key, value)
+		case compiler.OpConcat:
+			parts := vm.popN(vm.read16(f))
+			vm.push(interpreter.Concat(parts))
+		case compiler.OpCommand:
+			command := chunk.Constants[vm.read16(f)].(*token.Token)
+			vm.push(interpreter.RunCommand(command, vm.interpreter.Process))
+		case compiler.OpGetEnv:
+			name := chunk.Constants[vm.read16(f)].(string)
+			if value, ok := vm.interpreter.Process.Get(name); ok {
+				vm.push(value)
+			} else {
+				vm.push(nil)
+			}
+		case compiler.OpSetEnv:
+			vm.read16(f)
+			vm.push(interpreter.SetEnv(at, vm.pop(), vm.interpreter.Process))
+		case compiler.OpExec:
+			count := int(vm.read8(f))
+			stmt := chunk.Constants[vm.read16(f)].(*ast.ExecStmt)
+			targets := vm.popN(redirectTargets(stmt))
+			arguments := vm.popN(count)
+			interpreter.Execute(stmt.Keyword, arguments, stmt.Redirects, targets, vm.interpreter.Process)
+		case compiler.OpLog:
+			fmt.Printf("%s", interpreter.Stringify(vm.pop()))
+
+		// Exceptions and blocks
+		case compiler.OpThrow:
+			value := vm.pop()
+			// Re-throw caught errors as they are to preserve their position
+			// and stack.
+			if err, ok := value.(*interpreter.Error); ok {
+				panic(err)
+			}
+			panic(interpreter.NewThrownError(at, value))
+		case compiler.OpRethrow:
+			panic(vm.pop().(*interpreter.Error))
+		case compiler.OpTry:
+			offset := vm.read16(f)
+			vm.blocks = append(vm.blocks, &block{
+				kind:    handlerBlock,
+				frame:   len(vm.frames) - 1,
+				height:  len(vm.stack),
+				handler: f.ip + offset,
+			})
+		case compiler.OpEndTry:
+			vm.blocks = vm.blocks[:len(vm.blocks)-1]
+		case compiler.OpWith:
+			restore := interpreter.Override(at, vm.pop(), vm.interpreter.Process)
+			vm.blocks = append(vm.blocks, &block{
+				kind:    withBlock,
+				frame:   len(vm.frames) - 1,
+				restore: restore,
+			})
+		case compiler.OpEndWith:
+			b := vm.blocks[len(vm.blocks)-1]
+			vm.blocks = vm.blocks[:len(vm.blocks)-1]
+			b.restore()
+		case compiler.OpPushNames:
+			vm.blocks = append(vm.blocks, &block{
+				kind:   namesBlock,
+				frame:  len(vm.frames) - 1,
+				values: map[string]interface{}{},
+			})
+			vm.names++
+		case compiler.OpPopNames:
+			vm.blocks = vm.blocks[:len(vm.blocks)-1]
+			vm.names--
+
+		// Modules
+		case compiler.OpImportBash:
+			local := vm.read8(f) == 1
+			path := chunk.Constants[vm.read16(f)].(*token.Token)
+			resolved := vm.interpreter.ResolveImport(path.Lexeme)
+			for _, fn := range interpreter.ImportBash(path, resolved, vm.interpreter.Process) {
+				if local {
+					vm.namesScope().values[fn.Name] = fn
+				} else {
+					f.closure.Globals.Define(fn.Name, fn)
+				}
+			}
+		case compiler.OpImportModule:
+			path := chunk.Constants[vm.read16(f)].(*token.Token)
+			vm.push(vm.importModule(path))
+		case compiler.OpExport:
+			name := chunk.Constants[vm.read16(f)].(string)
+			vm.interpreter.Module.Export(name)
+		}
+	}
+}
+
+// Call Functions =============================================================
+//
+
+// call calls the callee with the count arguments on the top of the stack. It
+// returns true if a frame was pushed for a closure, and otherwise replaces the
+// callee and arguments with the result.
+func (vm *VM) call(callee interface{}, count int, at *token.Token) bool {
+	switch fn := callee.(type) {
+	case *Closure:
+		interpreter.CheckArity(at, fn.Function.Arity, count)
+		vm.pushFrame(fn, count, fn.Function.Name, at)
+		return true
+	case *BoundMethod:
+		interpreter.CheckArity(at, fn.Method.Function.Arity, count)
+		vm.stack[len(vm.stack)-1-count] = fn.Receiver
+		vm.pushFrame(fn.Method, count, fn.Method.Function.Name, at)
+		return true
+	case *Class:
+		interpreter.CheckArity(at, fn.arity(), count)
+		instance := NewInstance(fn)
+		vm.stack[len(vm.stack)-1-count] = instance
+		if fn.Fields != nil {
+			vm.invoke(fn.Fields, instance, fn.Name, at)
+		}
+		switch init := fn.Methods["init"].(type) {
+		case *Closure:
+			vm.pushFrame(init, count, fn.Name, at)
+			return true
+		case interpreter.GluCallable:
+			vm.callNative(init, vm.popN(count), fn.Name, at)
+		}
+		return false
+	case interpreter.GluCallable:
+		interpreter.CheckArity(at, fn.Arity(), count)
+		arguments := vm.popN(count)
+		result := vm.callNative(fn, arguments, interpreter.CallableName(fn), at)
+		vm.stack[len(vm.stack)-1] = result
+		return false
+	}
+	panic(interpreter.NewError(at, "Can only call functions."))
+}
+
+// pushFrame pushes a frame for the closure whose callee and arguments are on
+// the top of the stack.
+func (vm *VM) pushFrame(closure *Closure, count int, name string, at *token.Token) {
+	vm.checkDepth(at)
+	vm.frames = append(vm.frames, &frame{
+		closure: closure,
+		base:    len(vm.stack) - 1 - count,
+		name:    name,
+		token:   at,
+	})
+}
+
+// checkDepth raises an error if a call at the token would exceed the maximum
+// depth of the call stack. The frame of the script is not a call.
+func (vm *VM) checkDepth(at *token.Token) {
+	interpreter.CheckDepth(at, len(vm.frames)-1, vm.interpreter.MaxDepth)
+}
+
+// callNative calls a function implemented by the interpreter. Errors raised
+// without a position are reported at the call.
+func (vm *VM) callNative(
+	fn interpreter.GluCallable,
+	arguments []interface{},
+	name string,
+	at *token.Token,
+) interface{} {
+	defer func() {
+		if r := recover(); r != nil {
+			if err, ok := r.(*interpreter.Error); ok {
+				err.Locate(at)
+				err.Unwind(interpreter.NewFrame(name, at))
+			}
+			panic(r)
+		}
+	}()
+	return fn.Call(vm.interpreter, arguments)
+}
+
+// importModule returns the module at the path, compiling and running it if it
+// has not been imported before.
+func (vm *VM) importModule(path *token.Token) *interpreter.GluModule {
+	return vm.interpreter.LoadModule(path, func(stmts []ast.Stmt) {
+		c := compiler.New()
+		fn := c.Compile(stmts)
+		if len(c.Errors) > 0 {
+			err := fmt.Sprintf("Cannot import '%s': %s", path.Lexeme, c.Errors[0].Error())
+			panic(interpreter.NewError(path, err))
+		}
+		closure := NewClosure(fn, vm.interpreter.Module.Globals())
+		vm.invoke(closure, closure, "", nil)
+	})
+}
+
+// Variable Functions =========================================================
+//
+
+// global returns the value of the named global. Functions imported into a
+// names scope shadow the globals.
+func (vm *VM) global(f *frame, name string, at *token.Token) interface{} {
+	if vm.names > 0 {
+		for idx := len(vm.blocks) - 1; idx >= 0; idx-- {
+			if value, ok := vm.blocks[idx].values[name]; ok {
+				return value
+			}
+		}
+	}
+	return f.closure.Globals.Get(at)
+}
+
+// assignGlobal assigns the value of the named global.
+func (vm *VM) assignGlobal(f *frame, name string, at *token.Token, value interface{}) {
+	if vm.names > 0 {
+		for idx := len(vm.blocks) - 1; idx >= 0; idx-- {
+			if _, ok := vm.blocks[idx].values[name]; ok {
+				vm.blocks[idx].values[name] = value
+				return
+			}
+		}
+	}
+	f.closure.Globals.Assign(at, value)
+}
+
+// namesScope returns the innermost names scope.
+func (vm *VM) namesScope() *block {
+	for idx := len(vm.blocks) - 1; ; idx-- {
+		if vm.blocks[idx].kind == namesBlock {
+			return vm.blocks[idx]
+		}
+	}
+}
+
+// capture returns the open upvalue for the slot, creating it if there is
+// none.
+func (vm *VM) capture(slot int) *Upvalue {
+	idx := len(vm.upvalues)
+	for idx > 0 && vm.upvalues[idx-1].slot >= slot {
+		if vm.upvalues[idx-1].slot == slot {
+			return vm.upvalues[idx-1]
+		}
+		idx--
+	}
+	upvalue := &Upvalue{slot: slot, open: true}
+	vm.upvalues = append(vm.upvalues, nil)
+	copy(vm.upvalues[idx+1:], vm.upvalues[idx:])
+	vm.upvalues[idx] = upvalue
+	return upvalue
+}
+
+// closeUpvalues closes the open upvalues of the slots from the height.
+func (vm *VM) closeUpvalues(height int) {
+	for len(vm.upvalues) > 0 {
+		upvalue := vm.upvalues[len(vm.upvalues)-1]
+		if upvalue.slot < height {
+			return
+		}
+		upvalue.closed = vm.stack[upvalue.slot]
+		upvalue.open = false
+		vm.upvalues = vm.upvalues[:len(vm.upvalues)-1]
+	}
+}
+
+// Stack Functions ============================================================
+//
+
+func (vm *VM) push(value interface{}) {
+	vm.stack = append(vm.stack, value)
+}
+
+// underflow panics if there are fewer than count values on the stack above
+// slot 0 of the current frame, which only happens if the bytecode is
+// malformed.
+func (vm *VM) underflow(count int) {
+	if len(vm.stack)-count <= vm.frames[len(vm.frames)-1].base {
+		panic(interpreter.NewError(nil, "Stack underflow."))
+	}
+}
+
+func (vm *VM) pop() interface{} {
+	vm.underflow(1)
+	value := vm.stack[len(vm.stack)-1]
+	vm.stack = vm.stack[:len(vm.stack)-1]
+	return value
+}
+
+// popN pops the count values on the top of the stack, in order.
+func (vm *VM) popN(count int) []interface{} {
+	vm.underflow(count)
+	values := make([]interface{}, count)
+	copy(values, vm.stack[len(vm.stack)-count:])
+	vm.stack = vm.stack[:len(vm.stack)-count]
+	return values
+}
+
+// peek returns the value the distance below the top of the stack.
+func (vm *VM) peek(distance int) interface{} {
+	vm.underflow(distance + 1)
+	return vm.stack[len(vm.stack)-1-distance]
+}
+
+func (vm *VM) read8(f *frame) byte {
+	b := f.closure.Function.Chunk.Code[f.ip]
+	f.ip++
+	return b
+}
+
+func (vm *VM) read16(f *frame) int {
+	code := f.closure.Function.Chunk.Code
+	f.ip += 2
+	return int(code[f.ip-2])<<8 | int(code[f.ip-1])
+}
+
+// Operator Functions =========================================================
+//
+
+// compare applies a comparison operator. Integers are compared directly, and
+// other operands by the interpreter.
+func compare(op compiler.OpCode, at *token.Token, left, right interface{}) interface{} {
+	if l, ok := left.(int64); ok {
+		if r, ok := right.(int64); ok {
+			switch op {
+			case compiler.OpLess:
+				return l < r
+			case compiler.OpLessEqual:
+				return l <= r
+			case compiler.OpGreater:
+				return l > r
+			default:
+				return l >= r
+			}
+		}
+	}
+	return interpreter.Binary(at, left, right)
+}
+
+// arithmetic applies '+' or '-'. Integers that do not overflow are computed
+// directly, and other operands by the interpreter.
+func arithmetic(op compiler.OpCode, at *token.Token, left, right interface{}) interface{} {
+	if l, ok := left.(int64); ok {
+		if r, ok := right.(int64); ok {
+			if op == compiler.OpAdd {
+				if sum := l + r; (r >= 0) == (sum >= l) {
+					return sum
+				}
+			} else if difference := l - r; (r >= 0) == (difference <= l) {
+				return difference
+			}
+		}
+	}
+	return interpreter.Binary(at, left, right)
+}
+
+// redirectTargets returns the number of redirects of the statement that have
+// a target.
+func redirectTargets(stmt *ast.ExecStmt) int {
+	count := 0
+	for _, redirect := range stmt.Redirects {
+		if redirect.Target != nil {
+			count++
+		}
+	}
+	return count
+}
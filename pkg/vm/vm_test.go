@@ -0,0 +1,132 @@
+package vm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/templecloud/glu/pkg/compiler"
+	"github.com/templecloud/glu/pkg/interpreter"
+	"github.com/templecloud/glu/pkg/lexer"
+	"github.com/templecloud/glu/pkg/parser"
+)
+
+func TestVM_Run(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"1 + 2 * 3;", int64(7)},
+		{"-(7 // 2) % 2;", int64(1)},
+		{"\"a\" + 1;", "a1"},
+		{"1 < 2 and 2 <= 2;", true},
+		{"nil or 3;", int64(3)},
+		{"!(1 == 1.0);", false},
+		{"[1, 2, 3][1:][0];", int64(2)},
+		{"var m = {\"a\": 1}; m[\"b\"] = 2; m[\"a\"] + m[\"b\"];", int64(3)},
+		// Jumps
+		{"var a; if (false) a = 1; else a = 2; a;", int64(2)},
+		{"var a = 0; while (a < 5) a = a + 1; a;", int64(5)},
+		{"var s = 0; for (var i = 0; i < 10; i = i + 1) { if (i % 2 == 0) continue; if (i > 7) break; s = s + i; } s;",
+			int64(16)},
+		// Functions and upvalues
+		{"func fib(n) { if (n < 2) return n; return fib(n - 1) + fib(n - 2); } fib(20);", int64(6765)},
+		{"func counter() { var n = 0; func inc() { n = n + 1; return n; } return inc; } " +
+			"var c = counter(); c(); c();", int64(2)},
+		{"var get; var set; { var x = 1; func g() { return x; } func s(v) { x = v; } get = g; set = s; } " +
+			"set(5); get();", int64(5)},
+		{"var r; { func f() { return g(); } func g() { return 2; } r = f(); } r;", int64(2)},
+		{"func outer() { var x = \"o\"; func middle() { func inner() { return x; } return inner; } " +
+			"return middle()(); } outer();", "o"},
+		{"var fns = []; for (var i = 0; i < 3; i = i + 1) { var j = i; push(fns, () => j); } " +
+			"fns[0]() + fns[2]();", int64(2)},
+		// Classes and exceptions
+		{"class P { var y = 2; init(x) { this.x = x; } sum() { return this.x + this.y; } } P(3).sum();", int64(5)},
+		{"var r; try { -\"a\"; } catch (e) { r = e.message; } r;", "Operand must be a number."},
+		{"var r = 0; try { try { throw 1; } finally { r = r + 1; } } catch (e) { r = r + 10; } r;", int64(11)},
+	}
+	for idx, tt := range tests {
+		actual, err := run(t, tt.input)
+		if err != nil {
+			t.Fatalf("test[%d] - Unexpected error: %v", idx, err)
+		}
+		if tt.expected != actual {
+			t.Fatalf("test[%d] - Expected=%v, Actual=%v", idx, tt.expected, actual)
+		}
+	}
+}
+
+func TestVMError_Run(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		trace    string
+	}{
+		{"-\"a\";", "Operand must be a number.", ""},
+		{"1 < \"a\";", "Operands must both be numbers or both be strings.", ""},
+		{"1 + nil;", "Operands must both be numbers.", ""},
+		{"1 // 0;", "Division by zero.", ""},
+		{"x;", "Undefined variable 'x'.", ""},
+		{"\"f\"();", "Can only call functions.", ""},
+		{"func f(a) {} f();", "Expected 1 arguments, but, got 0.", ""},
+		{"1.x = 2;", "Only instances have fields.", ""},
+		{"func f() { return 1 + nil; }\nfunc g() { f(); }\ng();", "Operands must both be numbers.",
+			"    at f (Line: 2, Column: 14)\n    at g (Line: 3, Column: 3)"},
+	}
+	for idx, tt := range tests {
+		_, err := run(t, tt.input)
+		if err == nil {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%v", idx, tt.expected, nil)
+		}
+		if !strings.Contains(err.Error(), tt.expected) {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.expected, err.Error())
+		}
+		if tt.trace != err.Trace() {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%q", idx, tt.trace, err.Trace())
+		}
+	}
+}
+
+func TestVMError_StackUnderflow(t *testing.T) {
+	tests := [][]compiler.OpCode{
+		{compiler.OpPop},
+		{compiler.OpAdd},
+		{compiler.OpNil, compiler.OpEqual},
+		{compiler.OpNegate},
+		{compiler.OpReturn},
+	}
+	for idx, ops := range tests {
+		fn := compiler.NewFunction("script", 0)
+		for _, op := range ops {
+			fn.Chunk.Write(byte(op), nil)
+		}
+		_, err := New(interpreter.New()).Run(fn)
+		expected := "Stack underflow."
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Fatalf("test[%d] - Expected=%q, Actual=%v", idx, expected, err)
+		}
+	}
+}
+
+// Support Functions ==========================================================
+//
+
+// run compiles and runs each statement of the input in turn, and returns the
+// result of the last, which is the value of an expression statement.
+func run(t *testing.T, input string) (interface{}, *interpreter.Error) {
+	tokens, _ := lexer.New(input).ScanTokens()
+	stmts := parser.New(tokens).Parse()
+	machine := New(interpreter.New())
+	c := compiler.New()
+	var result interface{}
+	for _, stmt := range stmts {
+		fn := c.CompileStmt(stmt)
+		if len(c.Errors) > 0 {
+			t.Fatalf("Unexpected compile errors: %v", c.Errors[0])
+		}
+		var err *interpreter.Error
+		if result, err = machine.Run(fn); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}